@@ -0,0 +1,76 @@
+package subcmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArgCountErr is the usage error returned when the number of arguments remaining
+// after parsing a subcommand's flags and positional parameters
+// is outside the range given by its MinArgs and MaxArgs fields
+// (see [Subcmd]).
+type ArgCountErr struct {
+	// Args are the remaining arguments.
+	Args []string
+
+	// Min and Max are the subcommand's MinArgs and MaxArgs.
+	Min, Max int
+}
+
+func (e ArgCountErr) Error() string {
+	return fmt.Sprintf("got %d trailing argument(s), want %s", len(e.Args), e.want())
+}
+
+func (e ArgCountErr) want() string {
+	switch {
+	case e.Max <= 0:
+		return fmt.Sprintf("at least %d", e.Min)
+	case e.Min == e.Max:
+		return fmt.Sprintf("exactly %d", e.Min)
+	case e.Min <= 0:
+		return fmt.Sprintf("at most %d", e.Max)
+	default:
+		return fmt.Sprintf("%d to %d", e.Min, e.Max)
+	}
+}
+
+// Detail implements Usage.
+func (e ArgCountErr) Detail() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "Wrong number of arguments: want %s, got %d", e.want(), len(e.Args))
+	if len(e.Args) > 0 {
+		fmt.Fprintln(b, ":")
+		for _, arg := range e.Args {
+			fmt.Fprintf(b, "  %s\n", arg)
+		}
+	} else {
+		fmt.Fprintln(b)
+	}
+	return b.String()
+}
+
+// argLimits are the constraints on the arguments remaining after parsing a subcommand's parameters.
+type argLimits struct {
+	strict   bool
+	min, max int
+}
+
+func (l argLimits) check(args []string, cat Catalog) error {
+	if l.strict && len(args) > 0 {
+		return ExtraArgsErr{Args: args, cat: cat}
+	}
+	if len(args) < l.min || (l.max > 0 && len(args) > l.max) {
+		return ArgCountErr{Args: args, Min: l.min, Max: l.max}
+	}
+	return nil
+}
+
+func checkArgLimits(subcmd Subcmd) error {
+	if subcmd.MinArgs < 0 || subcmd.MaxArgs < 0 {
+		return fmt.Errorf("negative MinArgs or MaxArgs")
+	}
+	if subcmd.MaxArgs > 0 && subcmd.MinArgs > subcmd.MaxArgs {
+		return fmt.Errorf("MinArgs %d exceeds MaxArgs %d", subcmd.MinArgs, subcmd.MaxArgs)
+	}
+	return nil
+}
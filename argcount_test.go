@@ -0,0 +1,59 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestArgCount(t *testing.T) {
+	cases := []struct {
+		min, max int
+		args     []string
+		wantErr  string
+	}{
+		{min: 1, args: []string{"x"}},
+		{min: 1, wantErr: "got 0 trailing argument(s), want at least 1"},
+		{max: 2, args: []string{"x", "y"}},
+		{max: 2, args: []string{"x", "y", "z"}, wantErr: "got 3 trailing argument(s), want at most 2"},
+		{min: 2, max: 2, args: []string{"x"}, wantErr: "got 1 trailing argument(s), want exactly 2"},
+		{min: 1, max: 3, args: []string{"w", "x", "y", "z"}, wantErr: "got 4 trailing argument(s), want 1 to 3"},
+		{min: 1, max: 3, args: []string{"x", "y"}},
+	}
+
+	for _, tc := range cases {
+		t.Run("", func(t *testing.T) {
+			c := testcmdfunc(func() Map {
+				return Map{
+					"a": Subcmd{
+						F:       func(context.Context, string, []string) {},
+						Params:  Params("pos", String, "", "a positional parameter"),
+						MinArgs: tc.min,
+						MaxArgs: tc.max,
+					},
+				}
+			})
+			err := Run(context.Background(), c, append([]string{"a", "pos"}, tc.args...))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var aerr ArgCountErr
+			if !errors.As(err, &aerr) {
+				t.Fatalf("got %v, want ArgCountErr", err)
+			}
+			if aerr.Error() != tc.wantErr {
+				t.Errorf(`got "%s", want "%s"`, aerr.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckArgCount(t *testing.T) {
+	err := Check(Subcmd{F: func(context.Context, []string) {}, MinArgs: 3, MaxArgs: 2})
+	if err == nil {
+		t.Error("got no error, want one")
+	}
+}
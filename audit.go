@@ -0,0 +1,122 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// WithAuditLog is an [Option] that causes [Run] to append a record to w
+// for each subcommand function it runs
+// (or tries to run, if parsing its arguments fails).
+// This is for environments that must keep track of how administrative commands are used.
+// To log to a file,
+// open it with os.O_APPEND.
+//
+// Each record is a single line of JSON with these fields:
+//
+//   - "time": the time the subcommand was dispatched, in RFC 3339 format;
+//   - "user": the name of the user running the program;
+//   - "path": the subcommand's [CommandPath];
+//   - "args": the subcommand's [Args], excluding the subcommand name,
+//     with the values of parameters whose names suggest they hold secrets replaced by "REDACTED"
+//     (see [WithLogger]);
+//   - "error": the error, if any, produced by the subcommand;
+//   - "status": the subcommand's exit status:
+//     the ExitCode of the error if it is or wraps an [ExitCoder],
+//     otherwise 1 if there was an error and 0 if not.
+//
+// Failures to write to w are reported as warnings (see [WithWarningHandler]).
+func WithAuditLog(w io.Writer) Option {
+	return func(c *config) error {
+		c.auditLog = w
+		return nil
+	}
+}
+
+type auditRecord struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Path   []string  `json:"path"`
+	Args   []string  `json:"args"`
+	Error  string    `json:"error,omitempty"`
+	Status int       `json:"status"`
+}
+
+// audit writes a record to the audit log, if there is one.
+func audit(ctx context.Context, start time.Time, params []Param, err error) {
+	w := getConfig(ctx).auditLog
+	if w == nil {
+		return
+	}
+
+	rec := auditRecord{
+		Time: start,
+		User: auditUser(),
+		Path: CommandPath(ctx),
+		Args: redactArgs(params, Args(ctx)[1:]),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		rec.Status = 1
+		var ec ExitCoder
+		if errors.As(err, &ec) {
+			rec.Status = ec.ExitCode()
+		}
+	}
+
+	j, err := json.Marshal(rec)
+	if err != nil {
+		warn(ctx, "encoding audit record: %s", err)
+		return
+	}
+	if _, err := w.Write(append(j, '\n')); err != nil {
+		warn(ctx, "writing audit record: %s", err)
+	}
+}
+
+func auditUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// redactArgs returns a copy of args
+// in which the values of any secret parameters (see isSecretName) are replaced with "REDACTED".
+func redactArgs(params []Param, args []string) []string {
+	result := append([]string(nil), args...)
+
+	flagToks, rest := scanFlagArgs(params, args)
+	for _, tok := range flagToks {
+		if !isSecretName(tok.name) {
+			continue
+		}
+		if tok.val >= 0 {
+			result[tok.val] = "REDACTED"
+		} else if idx := strings.Index(result[tok.idx], "="); idx >= 0 {
+			result[tok.idx] = result[tok.idx][:idx+1] + "REDACTED"
+		}
+	}
+
+	i := rest
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			continue
+		}
+		if i >= len(result) {
+			break
+		}
+		if isSecretName(p.Name) {
+			result[i] = "REDACTED"
+		}
+		i++
+	}
+
+	return result
+}
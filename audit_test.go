@@ -0,0 +1,96 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	c := testcmdfunc(func() Map {
+		return Commands("login", func(context.Context, string, bool, string, []string) error {
+			return errors.New("denied")
+		}, "", Params(
+			"-password", String, "", "",
+			"-v", Bool, false, "",
+			"token", String, "", "",
+		))
+	})
+
+	err := Run(context.Background(), c, []string{"login", "-v", "-password", "hunter2", "abc123", "extra"}, WithAuditLog(&buf))
+	if err == nil {
+		t.Fatal("got no error")
+	}
+
+	var rec auditRecord
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rec.Path, []string{"login"}) {
+		t.Errorf("got path %v, want [login]", rec.Path)
+	}
+	if want := []string{"-v", "-password", "REDACTED", "REDACTED", "extra"}; !reflect.DeepEqual(rec.Args, want) {
+		t.Errorf("got args %v, want %v", rec.Args, want)
+	}
+	if rec.Error != "running login: denied" {
+		t.Errorf(`got error "%s", want "running login: denied"`, rec.Error)
+	}
+	if rec.Time.IsZero() {
+		t.Error("got zero time")
+	}
+	if rec.Status != 1 {
+		t.Errorf("got status %d, want 1", rec.Status)
+	}
+}
+
+func TestAuditLogStatus(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"ok", func(context.Context, []string) error { return nil }, "", nil,
+			"coded", func(context.Context, []string) error { return fmt.Errorf("wrapped: %w", exitCodeErr(4)) }, "", nil,
+		)
+	})
+
+	cases := []struct {
+		name string
+		want int
+	}{{
+		name: "ok",
+		want: 0,
+	}, {
+		name: "coded",
+		want: 4,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Run(context.Background(), c, []string{tc.name}, WithAuditLog(&buf))
+
+			var rec map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+				t.Fatal(err)
+			}
+			if rec["status"] != float64(tc.want) {
+				t.Errorf("got status %v, want %d", rec["status"], tc.want)
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	params := Params(
+		"-key", String, "", "",
+		"-n", Int, 0, "",
+		"secret", String, "", "",
+	)
+	got := redactArgs(params, []string{"--key=k", "-n", "1", "--", "s", "x"})
+	if want := []string{"--key=REDACTED", "-n", "1", "--", "REDACTED", "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package subcmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// VarParams is like [Params],
+// but the third element of each group of four
+// is a pointer to a variable,
+// which becomes the Var of the resulting [Param].
+// The variable's current value is the parameter's default.
+//
+// This lets a [Cmd] bind its parameters directly to its own fields,
+// so that its subcommand functions need not take them as arguments:
+//
+//	func (c *command) Subcmds() subcmd.Map {
+//		return subcmd.Commands(
+//			"serve", c.serve, "run the server", subcmd.VarParams(
+//				"-addr", subcmd.String, &c.addr, "address to listen on",
+//				"-verbose", subcmd.Bool, &c.verbose, "be verbose",
+//			),
+//		)
+//	}
+//
+//	func (c *command) serve(ctx context.Context, args []string) error {
+//		// ... use c.addr and c.verbose ...
+//	}
+//
+// Parameters of type [Value] are not supported by VarParams.
+// Use a Param with a Var for those.
+//
+// This function panics if the number or types of the arguments are wrong,
+// if any variable pointer is nil,
+// or if any parameter has type Value.
+func VarParams(a ...interface{}) []Param {
+	result := Params(a...)
+	for i := range result {
+		if result[i].Type == Value {
+			panic(fmt.Sprintf("VarParams: param %s has type Value", result[i].Name))
+		}
+		ptr := reflect.ValueOf(result[i].Default)
+		if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+			panic(fmt.Sprintf("VarParams: value for %s is not a non-nil pointer", result[i].Name))
+		}
+		result[i].Var = result[i].Default
+		result[i].Default = ptr.Elem().Interface()
+	}
+	return result
+}
+
+// funcParams returns the params that are passed to a subcommand's function,
+// i.e. the ones without a Var.
+func funcParams(params []Param) []Param {
+	for _, p := range params {
+		if p.Var != nil {
+			result := make([]Param, 0, len(params))
+			for _, p := range params {
+				if p.Var == nil {
+					result = append(result, p)
+				}
+			}
+			return result
+		}
+	}
+	return params
+}
+
+// argOrder returns params in the order in which parseArgs produces their values:
+// flags first, then positional parameters.
+func argOrder(params []Param) []Param {
+	result := make([]Param, 0, len(params))
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			result = append(result, p)
+		}
+	}
+	for _, p := range params {
+		if !strings.HasPrefix(p.Name, "-") {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// checkVar checks that the Var of p, if any, is a pointer to a variable of the right type.
+func checkVar(p Param) error {
+	if p.Var == nil {
+		return nil
+	}
+	ptr := reflect.ValueOf(p.Var)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("param %s has a Var that is not a non-nil pointer", p.Name)
+	}
+	if t := ptr.Elem().Type(); t != p.reflectType() && t != p.getterType() {
+		return fmt.Errorf("param %s has a Var of type %s, want *%s", p.Name, ptr.Type(), p.reflectType())
+	}
+	return nil
+}
+
+// storeVars stores the values in argvals (produced by parseArgs for params)
+// into the Vars of the params that have them,
+// and returns argvals without those values.
+func storeVars(params []Param, argvals []reflect.Value) ([]reflect.Value, error) {
+	if len(funcParams(params)) == len(params) {
+		return argvals, nil
+	}
+	ordered := argOrder(params)
+	result := make([]reflect.Value, 0, len(argvals))
+	result = append(result, argvals[0])
+	for i, p := range ordered {
+		v := argvals[1+i]
+		if p.Var == nil {
+			result = append(result, v)
+			continue
+		}
+		elem := reflect.ValueOf(p.Var).Elem()
+		if !v.Type().AssignableTo(elem.Type()) {
+			gv, ok := fromGetter(v, elem.Type())
+			if !ok {
+				return nil, fmt.Errorf("cannot store value of type %s in Var of param %s", v.Type(), p.Name)
+			}
+			v = gv
+		}
+		elem.Set(v)
+	}
+	return append(result, argvals[1+len(ordered):]...), nil
+}
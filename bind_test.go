@@ -0,0 +1,71 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type bindtestcmd struct {
+	verbose bool
+	count   int
+	target  string
+	wait    time.Duration
+
+	gotN    int
+	gotArgs []string
+}
+
+func (c *bindtestcmd) Subcmds() Map {
+	params := VarParams(
+		"-verbose", Bool, &c.verbose, "",
+		"-count", Int, &c.count, "",
+		"target", String, &c.target, "",
+		"wait?", Duration, &c.wait, "",
+	)
+	params = append(params, Params("-n", Int, 0, "")...)
+	return Commands("run", c.run, "", params)
+}
+
+func (c *bindtestcmd) run(_ context.Context, n int, args []string) error {
+	c.gotN, c.gotArgs = n, args
+	return nil
+}
+
+func TestVarParams(t *testing.T) {
+	c := &bindtestcmd{count: 7, wait: time.Second}
+	if err := Run(context.Background(), c, []string{"run", "-verbose", "-n", "3", "there", "x"}); err == nil {
+		t.Fatal("got no error for bad duration")
+	}
+
+	if err := Run(context.Background(), c, []string{"run", "-verbose", "-n", "3", "there"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.verbose || c.count != 7 || c.target != "there" || c.wait != time.Second {
+		t.Errorf("got verbose=%v count=%d target=%s wait=%s, want true 7 there 1s", c.verbose, c.count, c.target, c.wait)
+	}
+	if c.gotN != 3 {
+		t.Errorf("got n=%d, want 3", c.gotN)
+	}
+}
+
+func TestCheckVar(t *testing.T) {
+	var (
+		n int
+		s string
+		f = func(context.Context, []string) {}
+	)
+
+	if err := Check(Subcmd{F: f, Params: []Param{{Name: "-n", Type: Int, Default: 0, Var: &n}}}); err != nil {
+		t.Error(err)
+	}
+	if err := Check(Subcmd{F: f, Params: []Param{{Name: "-n", Type: Int, Default: 0, Var: &s}}}); err == nil {
+		t.Error("got no error for Var of wrong type")
+	}
+	if err := Check(Subcmd{F: f, Params: []Param{{Name: "-n", Type: Int, Default: 0, Var: n}}}); err == nil {
+		t.Error("got no error for non-pointer Var")
+	}
+	if err := Check(Subcmd{F: f, Params: []Param{{Name: "-n", Type: Int, Default: 0, Var: &n, Nullable: true}}}); err == nil {
+		t.Error("got no error for Nullable param with Var")
+	}
+}
@@ -0,0 +1,68 @@
+package subcmd
+
+import "reflect"
+
+// Shim is a function that calls f,
+// the F field of a [Subcmd],
+// with the given args,
+// without using reflection.
+// The args are the context.Context,
+// the values of the parameters described by the Subcmd's Params,
+// and a final []string
+// (which a shim for a variadic function must expand).
+//
+// A Shim returns true if it handled the call,
+// plus the error (if any) returned by f.
+// It returns false if it does not know how to call a function of f's type.
+//
+// Shims are normally generated by the subcmd-shims command
+// (see github.com/bobg/subcmd/v2/cmd/subcmd-shims)
+// and registered with [RegisterShim] from an init function.
+// They allow programs to avoid reflect.Value.Call,
+// which some restricted runtimes (notably TinyGo) do not support.
+type Shim func(f interface{}, args []interface{}) (bool, error)
+
+var shims []Shim
+
+// RegisterShim adds s to the list of shims that [Run] tries,
+// in the order registered,
+// after its built-in handling of the most common function signatures
+// and before falling back to calling a subcommand's function via reflection.
+//
+// When the package is built with the tinygo or subcmd_noreflect build tag,
+// there is no fallback,
+// and Run returns an error if no shim handles a subcommand's function.
+func RegisterShim(s Shim) {
+	shims = append(shims, s)
+}
+
+// callF calls f with argvals,
+// first trying the built-in fast path and the registered shims,
+// and then falling back to reflectCall.
+// The argvals are as produced by parseArgs.
+func callF(f interface{}, nparams int, variadic bool, argvals []reflect.Value) error {
+	args := make([]interface{}, 0, nparams+2)
+	for _, v := range argvals[:nparams+1] {
+		args = append(args, v.Interface())
+	}
+	if variadic {
+		rest := make([]string, 0, len(argvals)-nparams-1)
+		for _, v := range argvals[nparams+1:] {
+			rest = append(rest, v.String())
+		}
+		args = append(args, rest)
+	} else {
+		args = append(args, argvals[nparams+1].Interface())
+	}
+
+	if ok, err := fastCall(f, args); ok {
+		return err
+	}
+	for _, shim := range shims {
+		if ok, err := shim(f, args); ok {
+			return err
+		}
+	}
+
+	return reflectCall(f, argvals)
+}
@@ -0,0 +1,12 @@
+//go:build tinygo || subcmd_noreflect
+
+package subcmd
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func reflectCall(f interface{}, _ []reflect.Value) error {
+	return fmt.Errorf("no shim registered for function type %T (see RegisterShim)", f)
+}
@@ -0,0 +1,14 @@
+//go:build !tinygo && !subcmd_noreflect
+
+package subcmd
+
+import "reflect"
+
+func reflectCall(f interface{}, argvals []reflect.Value) error {
+	rv := reflect.ValueOf(f).Call(argvals)
+	if len(rv) == 1 {
+		err, _ := rv[0].Interface().(error)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestShim(t *testing.T) {
+	oldShims := shims
+	defer func() { shims = oldShims }()
+
+	var (
+		shimmed bool
+		gotArgs []string
+	)
+	RegisterShim(func(f interface{}, args []interface{}) (bool, error) {
+		if f, ok := f.(func(context.Context, int, ...string) error); ok {
+			shimmed = true
+			return true, f(args[0].(context.Context), args[1].(int), args[2].([]string)...)
+		}
+		return false, nil
+	})
+
+	c := shimtestcmd(func(_ context.Context, _ int, args ...string) error {
+		gotArgs = args
+		return nil
+	})
+	if err := Run(context.Background(), c, []string{"x", "-n", "2", "a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if !shimmed {
+		t.Error("shim not used")
+	}
+	if len(gotArgs) != 2 || gotArgs[0] != "a" || gotArgs[1] != "b" {
+		t.Errorf("got args %v, want [a b]", gotArgs)
+	}
+}
+
+type shimtestcmd func(context.Context, int, ...string) error
+
+func (c shimtestcmd) Subcmds() Map {
+	return Commands("x", (func(context.Context, int, ...string) error)(c), "", Params(
+		"-n", Int, 0, "",
+	))
+}
+
+func TestFastCall(t *testing.T) {
+	ctx := context.Background()
+
+	var got int
+	ok, err := fastCall(func(_ context.Context, n int, _ []string) error {
+		got = n
+		return nil
+	}, []interface{}{ctx, 7, []string(nil)})
+	if !ok {
+		t.Fatal("fastCall did not handle func(context.Context, int, []string) error")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 7 {
+		t.Errorf("got %d, want 7", got)
+	}
+
+	ok, _ = fastCall(func(context.Context, int, int, []string) {}, []interface{}{ctx, 1, 2, []string(nil)})
+	if ok {
+		t.Error("fastCall handled func(context.Context, int, int, []string)")
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	var (
+		ctx = context.Background()
+		c   = errtestcmd{}
+	)
+	for i := 0; i < b.N; i++ {
+		if err := Run(ctx, c, []string{"bb"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package subcmd
+
+import "context"
+
+// Catalog is a message catalog for localizing the fixed text in the help and error messages
+// produced by this package.
+// It maps the English form of each message,
+// given by one of the Msg constants,
+// to its translation.
+// Translations must contain the same fmt verbs, in the same order, as the English forms.
+// Messages missing from the catalog (or mapped to "") appear in English.
+//
+// A Catalog can be installed process-wide with [WithCatalog],
+// or for a particular [Cmd] and its nested subcommands by implementing [Localizer].
+//
+// Subcommand descriptions, parameter docs, and the like
+// are supplied by the application and are not translated by the Catalog.
+// See also [WithFormatter] and [WithHelpTemplate].
+type Catalog map[string]string
+
+// The messages that may be translated in a [Catalog].
+const (
+	MsgMissingSubcmd              = "missing subcommand, want one of: %s"
+	MsgMissingSubcmdDetail        = "Missing subcommand, want one of:"
+	MsgUnknownSubcmd              = `unknown subcommand "%s", want one of: %s`
+	MsgUnknownSubcmdDetail        = `Unknown subcommand "%s", want one of:`
+	MsgUnknownSubcmdSuggest       = `unknown subcommand "%s", %s`
+	MsgUnknownSubcmdSuggestDetail = `Unknown subcommand "%s", %s`
+	MsgDidYouMean                 = "did you mean %s?"
+	MsgOr                         = " or "
+	MsgWantOneOf                  = "Want one of:"
+	MsgSubcmdsAre                 = "subcommands are: %s"
+	MsgSubcmdsAreDetail           = "Subcommands are:"
+	MsgUsage                      = "usage: %s"
+	MsgUsageDetail                = "Usage: %s"
+	MsgDeprecatedDetail           = "Deprecated: %s"
+	MsgDeprecatedTag              = "(deprecated)"
+	MsgPositionalParams           = "Positional parameters:"
+	MsgExamples                   = "Examples:"
+	MsgOptionalDefault            = "(optional, default %s)"
+	MsgTooFewArgs                 = "too few arguments"
+	MsgExtraArgs                  = "unexpected arguments: %s"
+	MsgExtraArgsDetail            = "Unexpected arguments:"
+	MsgMissingFlag                = "missing required flag %s"
+	MsgMissingFlags               = "missing required flags: %s"
+	MsgMissingFlagsDetail         = "Missing required flags:"
+	MsgFlagRequires               = "flag %s requires %s"
+	MsgFlagRequiresDetail         = "Flag %s also requires:"
+	MsgAnd                        = " and "
+	MsgUnsupported                = `subcommand "%s" is not supported`
+	MsgUnsupportedReason          = `subcommand "%s" is %s`
+	MsgRenamedWarning             = `subcommand "%s" is deprecated, use "%s" instead`
+	MsgDeprecatedWarning          = `subcommand "%s" is deprecated: %s`
+)
+
+// tr translates msg according to c.
+// A nil Catalog is valid and leaves everything in English.
+func (c Catalog) tr(msg string) string {
+	if t := c[msg]; t != "" {
+		return t
+	}
+	return msg
+}
+
+// WithCatalog is an [Option] that translates the fixed text in help and error messages
+// according to cat.
+// A [Cmd] implementing [Localizer] overrides this for itself and its nested subcommands.
+func WithCatalog(cat Catalog) Option {
+	return func(c *config) error {
+		c.catalog = cat
+		return nil
+	}
+}
+
+// Localizer is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// and Catalog returns a non-nil [Catalog],
+// that is used for the help and error messages of this Cmd and its nested subcommands
+// (unless they are Localizers too),
+// in preference to any set with [WithCatalog].
+type Localizer interface {
+	Catalog() Catalog
+}
+
+// withCatalog returns a context in which c's Catalog is in effect,
+// if c is a Localizer.
+func withCatalog(ctx context.Context, c Cmd) context.Context {
+	l, ok := cmdAs[Localizer](c)
+	if !ok {
+		return ctx
+	}
+	cat := l.Catalog()
+	if cat == nil {
+		return ctx
+	}
+	ctx, _ = withOptions(ctx, []Option{WithCatalog(cat)}) // WithCatalog never fails
+	return ctx
+}
+
+// catalog returns the Catalog in effect in ctx, if any.
+func catalog(ctx context.Context) Catalog {
+	return getConfig(ctx).catalog
+}
@@ -0,0 +1,134 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testCatalog = Catalog{
+	MsgMissingSubcmd:       "sous-commande manquante, choisir parmi : %s",
+	MsgMissingSubcmdDetail: "Sous-commande manquante, choisir parmi :",
+	MsgUsage:               "utilisation : %s",
+	MsgUsageDetail:         "Utilisation : %s",
+	MsgPositionalParams:    "Paramètres positionnels :",
+	MsgTooFewArgs:          "trop peu d'arguments",
+}
+
+type localizedcmd struct {
+	errtestcmd
+}
+
+func (localizedcmd) Catalog() Catalog { return testCatalog }
+
+func TestCatalog(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cmd  Cmd
+		opts []Option
+	}{
+		{name: "option", cmd: errtestcmd{}, opts: []Option{WithCatalog(testCatalog)}},
+		{name: "localizer", cmd: localizedcmd{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			opts := append([]Option{WithProgName("prog")}, tc.opts...)
+
+			err := Run(ctx, tc.cmd, nil, opts...)
+			var merr *MissingSubcmdErr
+			if !errors.As(err, &merr) {
+				t.Fatalf("got %v, want *MissingSubcmdErr", err)
+			}
+			if got, want := merr.Error(), "sous-commande manquante, choisir parmi : a; bb; ccc"; got != want {
+				t.Errorf(`got "%s", want "%s"`, got, want)
+			}
+			if got := merr.Detail(); !strings.HasPrefix(got, "Sous-commande manquante, choisir parmi :\n") {
+				t.Errorf(`got "%s"`, got)
+			}
+
+			err = Run(ctx, tc.cmd, []string{"help", "a"}, opts...)
+			var herr *HelpRequestedErr
+			if !errors.As(err, &herr) {
+				t.Fatalf("got %v, want *HelpRequestedErr", err)
+			}
+			if got, want := herr.Error(), "utilisation : prog a [-a1] [-a2 int] [-a3 word] a4 [a5]"; got != want {
+				t.Errorf(`got "%s", want "%s"`, got, want)
+			}
+			detail := herr.Detail()
+			if !strings.Contains(detail, "Utilisation : prog a ") || !strings.Contains(detail, "Paramètres positionnels :\n") {
+				t.Errorf(`got "%s"`, detail)
+			}
+
+			err = Run(ctx, tc.cmd, []string{"a"}, opts...)
+			if !errors.Is(err, ErrTooFewArgs) {
+				t.Fatalf("got %v, want ErrTooFewArgs", err)
+			}
+			if got, want := err.Error(), "trop peu d'arguments"; got != want {
+				t.Errorf(`got "%s", want "%s"`, got, want)
+			}
+		})
+	}
+}
+
+func TestCatalogMessages(t *testing.T) {
+	cat := Catalog{
+		MsgExtraArgs:         "arguments inattendus : %s",
+		MsgMissingFlag:       "option obligatoire manquante %s",
+		MsgFlagRequires:      "l'option %s exige %s",
+		MsgAnd:               " et ",
+		MsgUnsupported:       `sous-commande "%s" non prise en charge`,
+		MsgDeprecatedWarning: `sous-commande "%s" obsolète : %s`,
+	}
+
+	var warnings []string
+	c := testcmdfunc(func() Map {
+		return Map{
+			"strict": Subcmd{F: func(context.Context, []string) {}, Strict: true},
+			"req": Subcmd{F: func(context.Context, string, []string) {}, Params: []Param{
+				{Name: "-name", Type: String, Default: "", Required: true},
+			}},
+			"requires": Subcmd{F: func(context.Context, bool, bool, bool, []string) {}, Params: []Param{
+				{Name: "-a", Type: Bool, Default: false, Requires: []string{"b", "c"}},
+				{Name: "-b", Type: Bool, Default: false},
+				{Name: "-c", Type: Bool, Default: false},
+			}},
+			"nope": Subcmd{F: func(context.Context, []string) {}, Supported: func() (bool, string) { return false, "" }},
+			"old":  Subcmd{F: func(context.Context, []string) {}, Deprecated: "utiliser new"},
+		}
+	})
+	opts := []Option{
+		WithCatalog(cat),
+		WithWarningHandler(func(_ context.Context, msg string) { warnings = append(warnings, msg) }),
+		WithStdio(nil, io.Discard, io.Discard),
+	}
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"strict", "x"}, want: "arguments inattendus : x"},
+		{args: []string{"req"}, want: "option obligatoire manquante -name"},
+		{args: []string{"requires", "-a"}, want: "l'option -a exige -b et -c"},
+		{args: []string{"nope"}, want: `sous-commande "nope" non prise en charge`},
+	}
+	for _, tc := range cases {
+		err := Run(context.Background(), c, tc.args, opts...)
+		if err == nil {
+			t.Errorf("%v: got no error", tc.args)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf(`%v: got "%s", want it to contain "%s"`, tc.args, err, tc.want)
+		}
+	}
+
+	if err := Run(context.Background(), c, []string{"old"}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`sous-commande "old" obsolète : utiliser new`}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %v, want %v", warnings, want)
+	}
+}
@@ -1,9 +1,11 @@
 package subcmd
 
 import (
+	"fmt"
 	"reflect"
-
-	"github.com/pkg/errors"
+	"strings"
+	"sync"
+	"unicode"
 )
 
 // Check checks that the type of subcmd.F matches the expectations set by subcmd.Params:
@@ -13,56 +15,175 @@ import (
 //   - If it returns a value, that value must be of type error;
 //   - It must take an initial context.Context parameter;
 //   - It must take a final []string or ...string parameter;
-//   - The length of subcmd.Params must match the number of parameters subcmd.F takes (not counting the initial context.Context and final []string parameters);
+//   - The length of subcmd.Params must match the number of parameters subcmd.F takes (not counting the initial context.Context and final []string parameters, nor any injected parameters; see [WithProvider]);
 //   - Each parameter in subcmd.Params must match the corresponding parameter in subcmd.F.
 //
-// It also checks that the default value of each parameter in subcmd.Params matches the parameter's type.
+// Alternatively, subcmd.F may take an options struct in place of the parameters for subcmd.Params
+// (see [Subcmd]),
+// in which case each parameter must match the type of its field in the struct.
+//
+// It also checks that each parameter in subcmd.Params has a well-formed name
+// (nonempty, and for flags, free of whitespace and '='),
+// that no two parameters share a name
+// (including a positional parameter and a flag),
+// that the default value of each parameter in subcmd.Params matches the parameter's type,
+// that the flags named in each parameter's Requires field exist,
+// and that MinArgs and MaxArgs are consistent.
+//
+// As a special case, F may be nil if subcmd.Sub is not,
+// in which case Check calls [CheckMap] on the nested command's subcommands instead.
 func Check(subcmd Subcmd) error {
+	if subcmd.F == nil && subcmd.Sub != nil {
+		return CheckMap(subcmd.Sub.Subcmds())
+	}
+
 	fv := reflect.ValueOf(subcmd.F)
 	ft := fv.Type()
 
-	if err := checkFuncType(ft, subcmd.Params); err != nil {
+	if err := cachedCheckFuncType(ft, funcParams(subcmd.Params)); err != nil {
+		return err
+	}
+
+	if err := checkParamNames(subcmd.Params); err != nil {
 		return err
 	}
 
 	for i, param := range subcmd.Params {
 		if err := checkParam(param); err != nil {
-			return errors.Wrapf(err, "checking parameter %d", i+1)
+			return fmt.Errorf("checking parameter %d: %w", i+1, err)
 		}
 	}
 
-	return nil
+	if err := checkArgLimits(subcmd); err != nil {
+		return err
+	}
+
+	return checkRequiresNames(subcmd.Params)
 }
 
-func checkFuncType(ft reflect.Type, params []Param) error {
-	in := make([]reflect.Type, 0, 2+len(params))
-	in = append(in, ctxType)
-	for _, param := range params {
-		in = append(in, param.Type.reflectType())
-	}
-	in = append(in, strSliceType)
+// funcTypeCache holds the results of checkFuncType,
+// together with the other facts about a function type that invoking it requires,
+// so that repeated calls to Run with the same subcommands
+// don't have to repeat the reflection work.
+var funcTypeCache sync.Map // funcTypeKey -> funcPlan
+
+type funcTypeKey struct {
+	ft     reflect.Type
+	params string // one byte per Param, holding its Type, plus 0x80 if it's Nullable
+	names  string // the Param names, which matter for options structs
+}
 
-	out := []reflect.Type{errType}
+// funcPlan is what is known about calling a function of a given type with a given list of params.
+type funcPlan struct {
+	err       error     // the result of checkFuncType
+	nInjected int       // the result of numInjected
+	opts      *optsPlan // non-nil if the function takes an options struct
+}
 
-	if ft == reflect.FuncOf(in, nil, true) {
-		return nil
-	}
-	if ft == reflect.FuncOf(in, out, true) {
-		return nil
+func newFuncPlan(ft reflect.Type, params []Param) funcPlan {
+	if err := checkFuncType(ft, params); err != nil {
+		opts, n, optsErr := newOptsPlan(ft, params)
+		switch {
+		case optsErr != nil:
+			return funcPlan{err: optsErr}
+		case opts != nil:
+			return funcPlan{nInjected: n, opts: opts}
+		}
+		return funcPlan{err: err}
 	}
-	if ft == reflect.FuncOf(in, nil, false) {
-		return nil
+	return funcPlan{nInjected: numInjected(ft, params)}
+}
+
+// cachedCheckFuncType is checkFuncType with memoization.
+func cachedCheckFuncType(ft reflect.Type, params []Param) error {
+	return cachedFuncPlan(ft, params).err
+}
+
+// cachedFuncPlan is newFuncPlan with memoization.
+// The result depends only on ft and on the types of params,
+// so those are the cache key.
+// (Except for parameters such as JSON ones,
+// and Value ones whose defaults are flag.Getters,
+// whose Go types depend on their defaults;
+// plans involving those are not cached.)
+func cachedFuncPlan(ft reflect.Type, params []Param) funcPlan {
+	var (
+		b     = make([]byte, 0, len(params))
+		names = new(strings.Builder)
+	)
+	for _, p := range params {
+		if p.Type.dynamic() || p.getterType() != nil {
+			return newFuncPlan(ft, params)
+		}
+		t := byte(p.Type)
+		if p.Nullable {
+			t |= 0x80
+		}
+		b = append(b, t)
+		names.WriteString(p.Name)
+		names.WriteByte(0)
 	}
-	if want := reflect.FuncOf(in, out, false); ft != want {
-		return FuncTypeErr{Got: ft, Want: want}
+	key := funcTypeKey{ft: ft, params: string(b), names: names.String()}
+
+	if val, ok := funcTypeCache.Load(key); ok {
+		return val.(funcPlan)
 	}
-	return nil
+	plan := newFuncPlan(ft, params)
+	funcTypeCache.Store(key, plan)
+	return plan
 }
 
 func checkParam(param Param) error {
-	if !reflect.TypeOf(param.Default).AssignableTo(param.Type.reflectType()) {
+	if err := checkVar(param); err != nil {
+		return err
+	}
+	if param.Nullable && param.Type == Value {
+		return fmt.Errorf("param %s has type Value and cannot be Nullable", param.Name)
+	}
+	if param.Nullable && param.Var != nil {
+		return fmt.Errorf("param %s has a Var and cannot be Nullable", param.Name)
+	}
+	if param.Type == JSON {
+		if v := reflect.ValueOf(param.Default); v.Kind() != reflect.Pointer || v.IsNil() {
+			return ParamDefaultErr{Param: param}
+		}
+	} else if param.Type == Custom {
+		if param.Default == nil {
+			return ParamDefaultErr{Param: param}
+		}
+		if param.Parse == nil {
+			return fmt.Errorf("param %s has type Custom but no Parse function", param.Name)
+		}
+	} else if param.Type == Text {
+		if _, err := textParseFunc(param); err != nil {
+			return err
+		}
+	} else if param.Default == nil {
+		// A nil default means the zero value for these types.
+		switch param.Type {
+		case Strings, StringMap, Time:
+		default:
+			return ParamDefaultErr{Param: param}
+		}
+	} else if !reflect.TypeOf(param.Default).AssignableTo(param.Type.reflectType()) {
 		return ParamDefaultErr{Param: param}
 	}
+	if (param.Type == Strings || param.Type == StringMap) && !strings.HasPrefix(param.Name, "-") {
+		return fmt.Errorf("param %s has type %s but is not a flag", param.Name, param.Type)
+	}
+	if len(param.Layouts) > 0 && param.Type != Time {
+		return fmt.Errorf("param %s has Layouts but is not of type Time", param.Name)
+	}
+	if len(param.Choices) > 0 {
+		if param.Type != String {
+			return fmt.Errorf("param %s has Choices but is not of type String", param.Name)
+		}
+		if dflt, _ := param.Default.(string); dflt != "" {
+			if err := checkChoice(param.Choices, dflt); err != nil {
+				return fmt.Errorf("default value of param %s: %w", param.Name, err)
+			}
+		}
+	}
 
 	return nil
 }
@@ -71,7 +192,56 @@ func checkParam(param Param) error {
 func CheckMap(m Map) error {
 	for name, subcmd := range m {
 		if err := Check(subcmd); err != nil {
-			return errors.Wrapf(err, "checking subcommand %s", name)
+			return fmt.Errorf("checking subcommand %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkParamNames checks the syntax of the names in params,
+// and that no two of them are the same.
+// Flag names are compared without their leading dashes,
+// and positional names without their trailing "?",
+// so "-x", "--x", "x", and "x?" all collide.
+func checkParamNames(params []Param) error {
+	seen := make(map[string]string) // normalized name -> original name
+	for i, p := range params {
+		var (
+			isFlag = strings.HasPrefix(p.Name, "-")
+			name   string
+		)
+		if isFlag {
+			name = strings.TrimLeft(p.Name, "-")
+		} else {
+			name = strings.TrimSuffix(p.Name, "?")
+		}
+		if name == "" {
+			return ParamNameErr{Name: p.Name, Index: i}
+		}
+		if isFlag && strings.ContainsFunc(name, func(r rune) bool { return r == '=' || unicode.IsSpace(r) }) {
+			return ParamNameErr{Name: p.Name, Index: i}
+		}
+		if other, ok := seen[name]; ok {
+			return ParamNameErr{Name: p.Name, Index: i, Dup: other}
+		}
+		seen[name] = p.Name
+	}
+	return nil
+}
+
+// checkRequiresNames checks that the flags named in each Param.Requires are among params.
+func checkRequiresNames(params []Param) error {
+	flags := make(map[string]bool)
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			flags[strings.TrimLeft(p.Name, "-")] = true
+		}
+	}
+	for _, p := range params {
+		for _, req := range p.Requires {
+			if !flags[strings.TrimLeft(req, "-")] {
+				return fmt.Errorf("flag %s requires unknown flag %s", p.Name, req)
+			}
 		}
 	}
 	return nil
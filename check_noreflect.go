@@ -0,0 +1,41 @@
+//go:build tinygo || subcmd_noreflect
+
+package subcmd
+
+import "reflect"
+
+// This version of checkFuncType avoids reflect.FuncOf,
+// which is unavailable in some restricted runtimes.
+// On a mismatch, the Want field of the resulting FuncTypeErr is nil.
+func checkFuncType(ft reflect.Type, params []Param) error {
+	err := FuncTypeErr{Got: ft}
+
+	if ft.Kind() != reflect.Func {
+		return err
+	}
+	n := numInjected(ft, params)
+	if ft.NumIn() != n+len(params)+2 {
+		return err
+	}
+	if ft.In(0) != ctxType {
+		return err
+	}
+	for i, param := range params {
+		if t := ft.In(n + i + 1); t != param.reflectType() && t != param.getterType() {
+			return err
+		}
+	}
+	if ft.In(n+len(params)+1) != strSliceType {
+		return err
+	}
+
+	switch ft.NumOut() {
+	case 0:
+		return nil
+	case 1:
+		if ft.Out(0) == errType {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+//go:build !tinygo && !subcmd_noreflect
+
+package subcmd
+
+import "reflect"
+
+func checkFuncType(ft reflect.Type, params []Param) error {
+	n := numInjected(ft, params)
+	in := make([]reflect.Type, 0, 2+n+len(params))
+	in = append(in, ctxType)
+	for i := 1; i <= n; i++ {
+		in = append(in, ft.In(i))
+	}
+	for i, param := range params {
+		t := param.reflectType()
+		if gt := param.getterType(); gt != nil && ft.Kind() == reflect.Func && ft.NumIn() > n+i+1 && ft.In(n+i+1) == gt {
+			t = gt
+		}
+		in = append(in, t)
+	}
+	in = append(in, strSliceType)
+
+	out := []reflect.Type{errType}
+
+	if ft == reflect.FuncOf(in, nil, true) {
+		return nil
+	}
+	if ft == reflect.FuncOf(in, out, true) {
+		return nil
+	}
+	if ft == reflect.FuncOf(in, nil, false) {
+		return nil
+	}
+	if want := reflect.FuncOf(in, out, false); ft != want {
+		return FuncTypeErr{Got: ft, Want: want}
+	}
+	return nil
+}
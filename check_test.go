@@ -3,6 +3,8 @@ package subcmd
 import (
 	"context"
 	"errors"
+	"flag"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -74,13 +76,13 @@ func TestCheckOneArg(t *testing.T) {
 			}
 
 			t.Run("one", func(t *testing.T) {
-				if err := Check(Subcmd{F: fOK, Params: []Param{{Type: ptyp, Default: dflts[ptyp]}}}); err != nil {
+				if err := Check(Subcmd{F: fOK, Params: []Param{{Name: "x", Type: ptyp, Default: dflts[ptyp]}}}); err != nil {
 					t.Error(err)
 				}
 			})
 
 			t.Run("toomany", func(t *testing.T) {
-				err := Check(Subcmd{F: fTooMany, Params: []Param{{Type: ptyp, Default: dflts[ptyp]}}})
+				err := Check(Subcmd{F: fTooMany, Params: []Param{{Name: "x", Type: ptyp, Default: dflts[ptyp]}}})
 				var e FuncTypeErr
 				if !errors.As(err, &e) {
 					t.Errorf("got %v, want FuncTypeErr", err)
@@ -88,7 +90,7 @@ func TestCheckOneArg(t *testing.T) {
 			})
 
 			t.Run("toofew", func(t *testing.T) {
-				err := Check(Subcmd{F: func(context.Context, []string) {}, Params: []Param{{Type: ptyp, Default: dflts[ptyp]}}})
+				err := Check(Subcmd{F: func(context.Context, []string) {}, Params: []Param{{Name: "x", Type: ptyp, Default: dflts[ptyp]}}})
 				var e FuncTypeErr
 				if !errors.As(err, &e) {
 					t.Errorf("got %v, want FuncTypeErr", err)
@@ -102,7 +104,7 @@ func TestCheckOneArg(t *testing.T) {
 					}
 
 					t.Run(ptyp2.String(), func(t *testing.T) {
-						err := Check(Subcmd{F: fOK, Params: []Param{{Type: ptyp2, Default: dflts[ptyp2]}}})
+						err := Check(Subcmd{F: fOK, Params: []Param{{Name: "x", Type: ptyp2, Default: dflts[ptyp2]}}})
 						var e FuncTypeErr
 						if !errors.As(err, &e) {
 							t.Errorf("got %v, want FuncTypeErr", err)
@@ -181,3 +183,152 @@ var dflts = map[Type]interface{}{
 	Float64:  float64(0),
 	Duration: time.Duration(0),
 }
+
+func TestCachedCheckFuncType(t *testing.T) {
+	var (
+		f      = func(context.Context, int, []string) {}
+		ft     = reflect.TypeOf(f)
+		params = Params("-n", Int, 0, "")
+		key    = funcTypeKey{ft: ft, params: string([]byte{byte(Int)}), names: "-n\x00"}
+	)
+
+	funcTypeCache.Delete(key)
+
+	for i := 0; i < 2; i++ {
+		if err := cachedCheckFuncType(ft, params); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := funcTypeCache.Load(key); !ok {
+			t.Fatal("result not cached")
+		}
+	}
+
+	// Same function type, different param types.
+	if err := cachedCheckFuncType(ft, Params("-n", String, "", "")); err == nil {
+		t.Error("got no error for mismatched param type")
+	}
+}
+
+func TestCachedFuncPlan(t *testing.T) {
+	var (
+		f      = func(context.Context, *testing.T, int, []string) {}
+		ft     = reflect.TypeOf(f)
+		params = Params("-n", Int, 0, "")
+		key    = funcTypeKey{ft: ft, params: string([]byte{byte(Int)}), names: "-n\x00"}
+	)
+
+	funcTypeCache.Delete(key)
+
+	for i := 0; i < 2; i++ {
+		plan := cachedFuncPlan(ft, params)
+		if plan.err != nil {
+			t.Fatal(plan.err)
+		}
+		if plan.nInjected != 1 {
+			t.Errorf("got %d injected params, want 1", plan.nInjected)
+		}
+		if _, ok := funcTypeCache.Load(key); !ok {
+			t.Fatal("result not cached")
+		}
+	}
+}
+
+func TestCheckParamNames(t *testing.T) {
+	cases := []struct {
+		name    string
+		params  []Param
+		wantErr bool
+	}{{
+		name:   "ok",
+		params: []Param{{Name: "-x"}, {Name: "--y"}, {Name: "z?"}},
+	}, {
+		name:    "empty",
+		params:  []Param{{Name: ""}},
+		wantErr: true,
+	}, {
+		name:    "emptyFlag",
+		params:  []Param{{Name: "--"}},
+		wantErr: true,
+	}, {
+		name:    "emptyOptional",
+		params:  []Param{{Name: "?"}},
+		wantErr: true,
+	}, {
+		name:    "space",
+		params:  []Param{{Name: "-dry run"}},
+		wantErr: true,
+	}, {
+		name:    "equals",
+		params:  []Param{{Name: "-x=1"}},
+		wantErr: true,
+	}, {
+		name:    "dupFlag",
+		params:  []Param{{Name: "-x"}, {Name: "--x"}},
+		wantErr: true,
+	}, {
+		name:    "dupPositional",
+		params:  []Param{{Name: "x"}, {Name: "x?"}},
+		wantErr: true,
+	}, {
+		name:    "flagPositional",
+		params:  []Param{{Name: "-x"}, {Name: "x"}},
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkParamNames(tc.params)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("got err %v, wantErr is %v", err, tc.wantErr)
+			}
+			var e ParamNameErr
+			if tc.wantErr && !errors.As(err, &e) {
+				t.Errorf("got %T, want ParamNameErr", err)
+			}
+		})
+	}
+}
+
+func TestCheckNilDefault(t *testing.T) {
+	cases := []struct {
+		typ     Type
+		f       interface{}
+		wantErr bool
+	}{
+		{typ: Strings, f: func(context.Context, []string, []string) {}},
+		{typ: StringMap, f: func(context.Context, map[string]string, []string) {}},
+		{typ: Time, f: func(context.Context, time.Time, []string) {}},
+		{typ: Int, f: func(context.Context, int, []string) {}, wantErr: true},
+		{typ: Value, f: func(context.Context, flag.Value, []string) {}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.typ.String(), func(t *testing.T) {
+			subcmd := Subcmd{F: tc.f, Params: Params("-x", tc.typ, nil, "")}
+
+			err := Check(subcmd)
+			if tc.wantErr {
+				var e ParamDefaultErr
+				if !errors.As(err, &e) {
+					t.Errorf("got %v, want ParamDefaultErr", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Compile(testcmdfunc(func() Map { return Map{"x": subcmd} }))
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Compile: got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Run(context.Background(), testcmdfunc(func() Map { return Map{"x": subcmd} }), []string{"x"}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
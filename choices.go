@@ -0,0 +1,47 @@
+package subcmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// choiceValue is the [flag.Value] for a String parameter with Choices.
+type choiceValue struct {
+	ptr     *string
+	choices []string
+}
+
+func (v *choiceValue) String() string {
+	if v == nil || v.ptr == nil {
+		return ""
+	}
+	return *v.ptr
+}
+
+func (v *choiceValue) Set(s string) error {
+	if err := checkChoice(v.choices, s); err != nil {
+		return err
+	}
+	*v.ptr = s
+	return nil
+}
+
+// Get implements [flag.Getter].
+func (v *choiceValue) Get() interface{} {
+	return *v.ptr
+}
+
+// checkChoice checks that val is one of choices.
+func checkChoice(choices []string, val string) error {
+	for _, c := range choices {
+		if val == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be %s", choicesDoc(choices))
+}
+
+// choicesDoc describes choices for use in help and error messages.
+func choicesDoc(choices []string) string {
+	return "one of: " + strings.Join(choices, "|")
+}
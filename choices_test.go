@@ -0,0 +1,75 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChoices(t *testing.T) {
+	var gotColor, gotShape string
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, color, shape string, _ []string) { gotColor, gotShape = color, shape },
+				Params: []Param{
+					{Name: "-color", Type: String, Default: "red", Doc: "the color", Choices: []string{"red", "green", "blue"}},
+					{Name: "shape", Type: String, Default: "", Choices: []string{"circle", "square"}},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "square"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotColor != "red" || gotShape != "square" {
+		t.Errorf("got color=%s shape=%s, want red square", gotColor, gotShape)
+	}
+
+	if err := Run(context.Background(), c, []string{"x", "-color", "blue", "circle"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotColor != "blue" || gotShape != "circle" {
+		t.Errorf("got color=%s shape=%s, want blue circle", gotColor, gotShape)
+	}
+
+	err := Run(context.Background(), c, []string{"x", "-color", "purple", "circle"})
+	var f FlagErr
+	if !errors.As(err, &f) {
+		t.Fatalf("got %v, want FlagErr", err)
+	}
+	if f.Flag != "color" || !strings.Contains(err.Error(), "one of: red|green|blue") {
+		t.Errorf("got %v, want error about -color listing the choices", err)
+	}
+
+	err = Run(context.Background(), c, []string{"x", "triangle"})
+	var p ParseErr
+	if !errors.As(err, &p) {
+		t.Fatalf("got %v, want ParseErr", err)
+	}
+	if !strings.Contains(err.Error(), "one of: circle|square") {
+		t.Errorf("got %v, want error listing the choices", err)
+	}
+	if p.Param != "shape" || p.Type != String || p.Input != "triangle" {
+		t.Errorf("got param %s, type %s, input %s; want shape, string, triangle", p.Param, p.Type, p.Input)
+	}
+
+	err = Run(context.Background(), c, []string{"help", "x"})
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	if detail := h.Detail(); !strings.Contains(detail, "-color string  the color (one of: red|green|blue)") {
+		t.Errorf("help does not show choices:\n%s", detail)
+	}
+
+	bad := Subcmd{
+		F:      func(context.Context, string, []string) {},
+		Params: []Param{{Name: "-color", Type: String, Default: "purple", Choices: []string{"red"}}},
+	}
+	if err := Check(bad); err == nil {
+		t.Error("got no error for default not among choices")
+	}
+}
@@ -0,0 +1,395 @@
+// Command subcmd-shims generates reflection-free call shims for subcmd functions.
+//
+// It scans the Go files in a package directory for function signatures
+// suitable for the F field of a subcmd.Subcmd
+// (that is, taking an initial context.Context,
+// zero or more parameters of the types supported by subcmd.Param
+// or of other types declared in the package or imported from elsewhere
+// (such as options structs, injected dependencies, and the types produced by flag.Getter values),
+// pointers to any of those (for Nullable params),
+// and a final []string or ...string,
+// and returning nothing or an error),
+// and writes a file registering a subcmd.Shim that can call all of them
+// without using reflect.Value.Call.
+//
+// Typical usage is via a go:generate directive in the package defining the subcommands:
+//
+//	//go:generate go run github.com/bobg/subcmd/v2/cmd/subcmd-shims
+//
+// Usage:
+//
+//	subcmd-shims [-o OUTPUT] [DIR]
+//
+// DIR defaults to the current directory,
+// and OUTPUT defaults to subcmd_shims.go in DIR.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	out := flag.String("o", "", "output file (default subcmd_shims.go in DIR)")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *out == "" {
+		*out = filepath.Join(dir, "subcmd_shims.go")
+	}
+
+	src, err := generate(dir, filepath.Base(*out))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sig is a function signature suitable for a subcmd function.
+type sig struct {
+	params   []string // Go types of the parameters between the context and the final []string
+	paths    []string // import paths needed by params
+	variadic bool
+	hasErr   bool
+}
+
+func (s sig) funcType() string {
+	var (
+		b    = new(strings.Builder)
+		rest = "[]string"
+	)
+	if s.variadic {
+		rest = "...string"
+	}
+	b.WriteString("func(context.Context")
+	for _, p := range s.params {
+		b.WriteString(", " + p)
+	}
+	b.WriteString(", " + rest + ")")
+	if s.hasErr {
+		b.WriteString(" error")
+	}
+	return b.String()
+}
+
+// builtinTypes are the predeclared types that may appear in a subcmd function's parameters.
+var builtinTypes = map[string]bool{
+	"any":     true,
+	"bool":    true,
+	"error":   true,
+	"float64": true,
+	"int":     true,
+	"int64":   true,
+	"string":  true,
+	"uint":    true,
+	"uint64":  true,
+}
+
+// generate scans the Go files in dir (skipping test files and the file named skip)
+// and produces the source of a file registering shims for the subcmd function signatures found there.
+func generate(dir, skip string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != skip
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("found %d packages in %s, want 1", len(pkgs), dir)
+	}
+
+	var (
+		pkgname string
+		sigs    = make(map[string]sig)
+	)
+	for name, pkg := range pkgs {
+		pkgname = name
+		local := localTypes(pkg)
+		for _, file := range pkg.Files {
+			sc := scope{imports: fileImports(file), local: local}
+			ast.Inspect(file, func(n ast.Node) bool {
+				if ft, ok := n.(*ast.FuncType); ok {
+					if s, ok := funcSig(ft, sc); ok {
+						sigs[s.funcType()] = s
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return render(pkgname, sigs)
+}
+
+// scope is what's needed to interpret the type expressions in a file.
+type scope struct {
+	imports map[string]string // local names of the file's imports to their paths
+	local   map[string]bool   // names of the types declared at the top level of the package
+}
+
+// localTypes gives the names of the types declared at the top level of pkg.
+func localTypes(pkg *ast.Package) map[string]bool {
+	result := make(map[string]bool)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.TypeParams == nil {
+					result[ts.Name.Name] = true
+				}
+			}
+		}
+	}
+	return result
+}
+
+// fileImports maps the local names of a file's imports to their paths.
+func fileImports(file *ast.File) map[string]string {
+	result := make(map[string]string)
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := qualifier(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		result[name] = path
+	}
+	return result
+}
+
+func funcSig(ft *ast.FuncType, sc scope) (sig, bool) {
+	var types []ast.Expr
+	for _, field := range ft.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, field.Type)
+		}
+	}
+	if len(types) < 2 {
+		return sig{}, false
+	}
+
+	var s sig
+
+	if typeString(types[0], sc, nil) != "context.Context" {
+		return sig{}, false
+	}
+	switch last := types[len(types)-1].(type) {
+	case *ast.Ellipsis:
+		if typeString(last.Elt, sc, nil) != "string" {
+			return sig{}, false
+		}
+		s.variadic = true
+	default:
+		if typeString(last, sc, nil) != "[]string" {
+			return sig{}, false
+		}
+	}
+	paths := make(map[string]bool)
+	for _, t := range types[1 : len(types)-1] {
+		ts := typeString(t, sc, paths)
+		if ts == "" {
+			return sig{}, false
+		}
+		s.params = append(s.params, ts)
+	}
+	for path := range paths {
+		s.paths = append(s.paths, path)
+	}
+
+	if ft.Results != nil {
+		if len(ft.Results.List) != 1 || len(ft.Results.List[0].Names) > 1 {
+			return sig{}, false
+		}
+		if typeString(ft.Results.List[0].Type, sc, nil) != "error" {
+			return sig{}, false
+		}
+		s.hasErr = true
+	}
+
+	return s, true
+}
+
+// typeString renders a type expression,
+// normalizing package qualifiers to the names given by qualifier,
+// and adds the import paths it needs to paths (if not nil).
+// It returns "" for expressions it does not understand,
+// and for identifiers that are neither in builtinTypes nor declared in the package.
+func typeString(expr ast.Expr, sc scope, paths map[string]bool) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if builtinTypes[e.Name] || sc.local[e.Name] {
+			return e.Name
+		}
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		path, ok := sc.imports[x.Name]
+		if !ok {
+			return ""
+		}
+		if paths != nil {
+			paths[path] = true
+		}
+		return qualifier(path) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		if elt := typeString(e.X, sc, paths); elt != "" {
+			return "*" + elt
+		}
+	case *ast.ArrayType:
+		if e.Len != nil {
+			return ""
+		}
+		if elt := typeString(e.Elt, sc, paths); elt != "" {
+			return "[]" + elt
+		}
+	case *ast.MapType:
+		k, v := typeString(e.Key, sc, paths), typeString(e.Value, sc, paths)
+		if k != "" && v != "" {
+			return "map[" + k + "]" + v
+		}
+	}
+	return ""
+}
+
+// qualifier is the name by which the generated file refers to the package with the given import path:
+// the last element of the path
+// (or the one before it, if the last is a major-version suffix like v2),
+// up to any dot,
+// with characters not allowed in identifiers replaced by underscores.
+// The generated file imports the package under this name
+// when it differs from the last element of the path.
+func qualifier(path string) string {
+	elts := strings.Split(path, "/")
+	name := elts[len(elts)-1]
+	if len(elts) > 1 && isMajorVersion(name) {
+		name = elts[len(elts)-2]
+	}
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+const subcmdPath = "github.com/bobg/subcmd/v2"
+
+func render(pkgname string, sigs map[string]sig) ([]byte, error) {
+	var keys []string
+	imports := make(map[string]bool)
+	if len(sigs) > 0 {
+		imports["context"] = true
+	}
+	for k, s := range sigs {
+		keys = append(keys, k)
+		for _, path := range s.paths {
+			if path != subcmdPath {
+				imports[path] = true
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var importList []string
+	for path := range imports {
+		importList = append(importList, path)
+	}
+	sort.Strings(importList)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, "// Code generated by subcmd-shims; DO NOT EDIT.")
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "package %s\n\n", pkgname)
+	fmt.Fprintln(buf, "import (")
+	for _, path := range importList {
+		if q := qualifier(path); q != filepath.Base(path) {
+			fmt.Fprintf(buf, "\t%s %q\n", q, path)
+		} else {
+			fmt.Fprintf(buf, "\t%q\n", path)
+		}
+	}
+	if len(importList) > 0 {
+		fmt.Fprintln(buf)
+	}
+	fmt.Fprintf(buf, "\t%q\n", subcmdPath)
+	fmt.Fprintln(buf, ")")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "func init() {")
+	fmt.Fprintln(buf, "\tsubcmd.RegisterShim(subcmdShim)")
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "func subcmdShim(f interface{}, args []interface{}) (bool, error) {")
+	if len(keys) > 0 {
+		fmt.Fprintln(buf, "\tswitch f := f.(type) {")
+	}
+	for _, k := range keys {
+		s := sigs[k]
+
+		callArgs := []string{"args[0].(context.Context)"}
+		for i, p := range s.params {
+			callArgs = append(callArgs, fmt.Sprintf("args[%d].(%s)", i+1, p))
+		}
+		rest := fmt.Sprintf("args[%d].([]string)", len(s.params)+1)
+		if s.variadic {
+			rest += "..."
+		}
+		callArgs = append(callArgs, rest)
+		call := fmt.Sprintf("f(%s)", strings.Join(callArgs, ", "))
+
+		fmt.Fprintf(buf, "\tcase %s:\n", k)
+		if s.hasErr {
+			fmt.Fprintf(buf, "\t\treturn true, %s\n", call)
+		} else {
+			fmt.Fprintf(buf, "\t\t%s\n", call)
+			fmt.Fprintln(buf, "\t\treturn true, nil")
+		}
+	}
+	if len(keys) > 0 {
+		fmt.Fprintln(buf, "\t}")
+	}
+	fmt.Fprintln(buf, "\treturn false, nil")
+	fmt.Fprintln(buf, "}")
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerate(t *testing.T) {
+	dir, err := os.MkdirTemp("", "subcmd-shims")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const src = `package foo
+
+import (
+	ctxpkg "context"
+	"time"
+)
+
+func a(ctx ctxpkg.Context, verbose bool, n int, args []string) error { return nil }
+func b(_ ctxpkg.Context, d time.Duration, args ...string)            {}
+func c(ctx ctxpkg.Context, x complex128, args []string)              {}
+
+var d = func(ctxpkg.Context, []string) {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(dir, "subcmd_shims.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `// Code generated by subcmd-shims; DO NOT EDIT.
+
+package foo
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+func init() {
+	subcmd.RegisterShim(subcmdShim)
+}
+
+func subcmdShim(f interface{}, args []interface{}) (bool, error) {
+	switch f := f.(type) {
+	case func(context.Context, []string):
+		f(args[0].(context.Context), args[1].([]string))
+		return true, nil
+	case func(context.Context, bool, int, []string) error:
+		return true, f(args[0].(context.Context), args[1].(bool), args[2].(int), args[3].([]string))
+	case func(context.Context, time.Duration, ...string):
+		f(args[0].(context.Context), args[1].(time.Duration), args[2].([]string)...)
+		return true, nil
+	}
+	return false, nil
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateTypes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "subcmd-shims")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const src = `package foo
+
+import (
+	"context"
+	"io"
+	"net/netip"
+
+	"example.com/store/v3"
+	"gopkg.in/yaml.v3"
+)
+
+type serveOpts struct {
+	Port int
+	Addr netip.Addr
+}
+
+type level int
+
+func a(ctx context.Context, w io.Writer, opts serveOpts, args []string) error { return nil }
+func b(ctx context.Context, db *store.DB, n *int, args []string) error        { return nil }
+func c(ctx context.Context, lvl level, node yaml.Node, args ...string)        {}
+func d(ctx context.Context, m map[string]*level, args []string)               {}
+func e(ctx context.Context, x chan int, args []string)                        {}
+func f(ctx context.Context, x undeclared, args []string)                      {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(dir, "subcmd_shims.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `// Code generated by subcmd-shims; DO NOT EDIT.
+
+package foo
+
+import (
+	"context"
+	store "example.com/store/v3"
+	yaml "gopkg.in/yaml.v3"
+	"io"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+func init() {
+	subcmd.RegisterShim(subcmdShim)
+}
+
+func subcmdShim(f interface{}, args []interface{}) (bool, error) {
+	switch f := f.(type) {
+	case func(context.Context, *store.DB, *int, []string) error:
+		return true, f(args[0].(context.Context), args[1].(*store.DB), args[2].(*int), args[3].([]string))
+	case func(context.Context, io.Writer, serveOpts, []string) error:
+		return true, f(args[0].(context.Context), args[1].(io.Writer), args[2].(serveOpts), args[3].([]string))
+	case func(context.Context, level, yaml.Node, ...string):
+		f(args[0].(context.Context), args[1].(level), args[2].(yaml.Node), args[3].([]string)...)
+		return true, nil
+	case func(context.Context, map[string]*level, []string):
+		f(args[0].(context.Context), args[1].(map[string]*level), args[2].([]string))
+		return true, nil
+	}
+	return false, nil
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	dir, err := os.MkdirTemp("", "subcmd-shims")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const src = `package foo
+
+func a(x int) {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(dir, "subcmd_shims.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `// Code generated by subcmd-shims; DO NOT EDIT.
+
+package foo
+
+import (
+	"github.com/bobg/subcmd/v2"
+)
+
+func init() {
+	subcmd.RegisterShim(subcmdShim)
+}
+
+func subcmdShim(f interface{}, args []interface{}) (bool, error) {
+	return false, nil
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,368 @@
+package subcmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Completion produces a shell completion script for the program prog,
+// whose subcommands are given by c.
+// The shell must be "bash", "zsh", or "fish".
+//
+// The script completes subcommand names (at every level of nesting; see Subcmd.Sub)
+// and the flags of each subcommand.
+// Where a subcommand expects positional parameters,
+// the script falls back to the shell's filename completion,
+// which in zsh is labeled with the name of the expected parameter.
+//
+// The script is generated from the same [Param] definitions that [Run] uses,
+// so it can be regenerated whenever those change,
+// e.g. from a "go generate" directive
+// or at install time.
+//
+// To install the script,
+// bash users can source it from .bashrc;
+// zsh users can save it as "_prog" in a directory in $fpath;
+// and fish users can save it as "prog.fish" in ~/.config/fish/completions.
+func Completion(prog string, c Cmd, shell string) (string, error) {
+	nodes, err := completionNodes(c)
+	if err != nil {
+		return "", err
+	}
+
+	switch shell {
+	case "bash":
+		return bashCompletion(prog, nodes), nil
+	case "zsh":
+		return zshCompletion(prog, nodes), nil
+	case "fish":
+		return fishCompletion(prog, nodes), nil
+	}
+	return "", fmt.Errorf("unknown shell %s", shell)
+}
+
+// completionNode describes one level of the subcommand tree.
+type completionNode struct {
+	path       string // e.g. "/db/migrate", or "" for the top level
+	children   []completionItem
+	flags      []completionFlag
+	positional []string
+}
+
+type completionItem struct {
+	name, desc string
+}
+
+type completionFlag struct {
+	name, doc string
+	hasValue  bool
+}
+
+// completionNodes walks c to produce a list of completionNodes,
+// starting with the top level.
+func completionNodes(c Cmd) ([]*completionNode, error) {
+	var (
+		top    = &completionNode{}
+		result = []*completionNode{top}
+		byPath = map[string]*completionNode{"": top}
+	)
+
+	err := walk(c, nil, func(path []string, subcmd Subcmd) error {
+		var (
+			name       = path[len(path)-1]
+			parentPath = completionPath(path[:len(path)-1])
+			node       = &completionNode{path: completionPath(path)}
+		)
+		parent := byPath[parentPath]
+		parent.children = append(parent.children, completionItem{name: name, desc: subcmd.helpDesc(nil)})
+
+		result = append(result, node)
+		byPath[node.path] = node
+
+		if subcmd.F == nil {
+			return nil
+		}
+		for _, p := range subcmd.Params {
+			if strings.HasPrefix(p.Name, "-") {
+				node.flags = append(node.flags, completionFlag{
+					name:     strings.TrimLeft(p.Name, "-"),
+					doc:      p.Doc,
+					hasValue: p.Type != Bool,
+				})
+			} else {
+				node.positional = append(node.positional, strings.TrimSuffix(p.Name, "?"))
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func completionPath(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(path, "/")
+}
+
+// completionFunc is the name of the shell function for completing prog.
+func completionFunc(prog string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, prog)
+}
+
+// completionScan writes shell code (valid in both bash and zsh)
+// that sets cmdpath to the subcommand path in the words before the current one
+// and npos to the number of positional arguments following it.
+// The words are in words[first] through words[cur-1].
+func completionScan(b *strings.Builder, nodes []*completionNode, words, first, cur string) {
+	var (
+		paths      []string
+		valueFlags []string
+	)
+	for _, node := range nodes {
+		if node.path != "" {
+			paths = append(paths, node.path)
+		}
+		for _, f := range node.flags {
+			if f.hasValue {
+				valueFlags = append(valueFlags, fmt.Sprintf("%s:-%s", node.path, f.name), fmt.Sprintf("%s:--%s", node.path, f.name))
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "\tcmdpath=\"\"\n")
+	fmt.Fprintf(b, "\tnpos=0\n")
+	fmt.Fprintf(b, "\tfor ((i = %s; i < %s; i++)); do\n", first, cur)
+	fmt.Fprintf(b, "\t\tw=\"${%s[i]}\"\n", words)
+	fmt.Fprintf(b, "\t\tcase \"$w\" in\n")
+	fmt.Fprintf(b, "\t\t-*)\n")
+	if len(valueFlags) > 0 {
+		fmt.Fprintf(b, "\t\t\tcase \"$cmdpath:$w\" in\n")
+		fmt.Fprintf(b, "\t\t\t%s) ((i++)) ;;\n", strings.Join(valueFlags, "|"))
+		fmt.Fprintf(b, "\t\t\tesac\n")
+	}
+	fmt.Fprintf(b, "\t\t\t;;\n")
+	fmt.Fprintf(b, "\t\t*)\n")
+	if len(paths) > 0 {
+		fmt.Fprintf(b, "\t\t\tcase \"$npos:$cmdpath/$w\" in\n")
+		fmt.Fprintf(b, "\t\t\t0:%s) cmdpath=\"$cmdpath/$w\" ;;\n", strings.Join(paths, "|0:"))
+		fmt.Fprintf(b, "\t\t\t*) ((npos++)) ;;\n")
+		fmt.Fprintf(b, "\t\t\tesac\n")
+	} else {
+		fmt.Fprintf(b, "\t\t\t((npos++))\n")
+	}
+	fmt.Fprintf(b, "\t\t\t;;\n")
+	fmt.Fprintf(b, "\t\tesac\n")
+	fmt.Fprintf(b, "\tdone\n")
+}
+
+func bashCompletion(prog string, nodes []*completionNode) string {
+	var (
+		b  = new(strings.Builder)
+		fn = completionFunc(prog)
+	)
+
+	fmt.Fprintf(b, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	fmt.Fprintf(b, "\tlocal cur cmdpath npos i w opts\n")
+	fmt.Fprintf(b, "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	completionScan(b, nodes, "COMP_WORDS", "1", "COMP_CWORD")
+	fmt.Fprintf(b, "\tcase \"$cmdpath\" in\n")
+	for _, node := range nodes {
+		var words []string
+		if len(node.children) > 0 {
+			for _, child := range node.children {
+				words = append(words, child.name)
+			}
+		}
+		for _, f := range node.flags {
+			words = append(words, "-"+f.name)
+		}
+		if len(words) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\t\"%s\")\n", node.path)
+		if len(node.children) > 0 {
+			fmt.Fprintf(b, "\t\t[[ $npos -eq 0 ]] && opts=\"%s\"\n", strings.Join(words, " "))
+		} else {
+			fmt.Fprintf(b, "\t\t[[ $cur == -* ]] && opts=\"%s\"\n", strings.Join(words, " "))
+		}
+		fmt.Fprintf(b, "\t\t;;\n")
+	}
+	fmt.Fprintf(b, "\tesac\n")
+	fmt.Fprintf(b, "\tCOMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n")
+	fmt.Fprintf(b, "}\n\n")
+	fmt.Fprintf(b, "complete -o default -F %s %s\n", fn, prog)
+
+	return b.String()
+}
+
+func zshCompletion(prog string, nodes []*completionNode) string {
+	var (
+		b  = new(strings.Builder)
+		fn = completionFunc(prog)
+	)
+
+	fmt.Fprintf(b, "#compdef %s\n\n", prog)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	fmt.Fprintf(b, "\tlocal cmdpath npos i w\n")
+	fmt.Fprintf(b, "\tlocal -a items\n")
+	completionScan(b, nodes, "words", "2", "CURRENT")
+	fmt.Fprintf(b, "\tcase \"$cmdpath\" in\n")
+	for _, node := range nodes {
+		if len(node.children) == 0 && len(node.flags) == 0 && len(node.positional) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\t\"%s\")\n", node.path)
+		if len(node.flags) > 0 {
+			fmt.Fprintf(b, "\t\tif [[ $PREFIX == -* ]]; then\n")
+			fmt.Fprintf(b, "\t\t\titems=(\n")
+			for _, f := range node.flags {
+				fmt.Fprintf(b, "\t\t\t\t%s\n", zshQuote(zshItem("-"+f.name, f.doc)))
+			}
+			fmt.Fprintf(b, "\t\t\t)\n")
+			fmt.Fprintf(b, "\t\t\t_describe 'flag' items\n")
+			fmt.Fprintf(b, "\t\t\treturn\n")
+			fmt.Fprintf(b, "\t\tfi\n")
+		}
+		if len(node.children) > 0 {
+			fmt.Fprintf(b, "\t\titems=(\n")
+			for _, child := range node.children {
+				fmt.Fprintf(b, "\t\t\t%s\n", zshQuote(zshItem(child.name, child.desc)))
+			}
+			fmt.Fprintf(b, "\t\t)\n")
+			fmt.Fprintf(b, "\t\t_describe 'subcommand' items\n")
+		} else if len(node.positional) > 0 {
+			fmt.Fprintf(b, "\t\titems=(")
+			for _, name := range node.positional {
+				fmt.Fprintf(b, " %s", zshQuote(name))
+			}
+			fmt.Fprintf(b, " )\n")
+			fmt.Fprintf(b, "\t\tif ((npos < ${#items})); then\n")
+			fmt.Fprintf(b, "\t\t\t_files -X \"<${items[npos+1]}>\"\n")
+			fmt.Fprintf(b, "\t\telse\n")
+			fmt.Fprintf(b, "\t\t\t_files\n")
+			fmt.Fprintf(b, "\t\tfi\n")
+		} else {
+			fmt.Fprintf(b, "\t\t_files\n")
+		}
+		fmt.Fprintf(b, "\t\t;;\n")
+	}
+	fmt.Fprintf(b, "\t*)\n")
+	fmt.Fprintf(b, "\t\t_files\n")
+	fmt.Fprintf(b, "\t\t;;\n")
+	fmt.Fprintf(b, "\tesac\n")
+	fmt.Fprintf(b, "}\n\n")
+	fmt.Fprintf(b, "%s \"$@\"\n", fn)
+
+	return b.String()
+}
+
+// zshItem formats a name and description for _describe.
+func zshItem(name, desc string) string {
+	name = strings.ReplaceAll(name, ":", `\:`)
+	if desc == "" {
+		return name
+	}
+	return name + ":" + desc
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func fishCompletion(prog string, nodes []*completionNode) string {
+	var (
+		b  = new(strings.Builder)
+		fn = completionFunc(prog) + "_at"
+	)
+
+	fmt.Fprintf(b, "# fish completion for %s\n\n", prog)
+
+	// Fish's syntax differs enough from bash's and zsh's
+	// that it needs its own version of completionScan.
+	var (
+		paths      []string
+		valueFlags []string
+	)
+	for _, node := range nodes {
+		if node.path != "" {
+			paths = append(paths, fishQuote(node.path))
+		}
+		for _, f := range node.flags {
+			if f.hasValue {
+				valueFlags = append(valueFlags, fishQuote(fmt.Sprintf("%s:-%s", node.path, f.name)), fishQuote(fmt.Sprintf("%s:--%s", node.path, f.name)))
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "function %s\n", fn)
+	fmt.Fprintf(b, "\tset -l words (commandline -opc)\n")
+	fmt.Fprintf(b, "\tset -l cmdpath ''\n")
+	fmt.Fprintf(b, "\tset -l npos 0\n")
+	fmt.Fprintf(b, "\tset -l skip 0\n")
+	fmt.Fprintf(b, "\tfor w in $words[2..-1]\n")
+	fmt.Fprintf(b, "\t\tif test $skip -eq 1\n")
+	fmt.Fprintf(b, "\t\t\tset skip 0\n")
+	fmt.Fprintf(b, "\t\t\tcontinue\n")
+	fmt.Fprintf(b, "\t\tend\n")
+	fmt.Fprintf(b, "\t\tswitch $w\n")
+	fmt.Fprintf(b, "\t\tcase '-*'\n")
+	if len(valueFlags) > 0 {
+		fmt.Fprintf(b, "\t\t\tswitch \"$cmdpath:$w\"\n")
+		fmt.Fprintf(b, "\t\t\tcase %s\n", strings.Join(valueFlags, " "))
+		fmt.Fprintf(b, "\t\t\t\tset skip 1\n")
+		fmt.Fprintf(b, "\t\t\tend\n")
+	}
+	fmt.Fprintf(b, "\t\tcase '*'\n")
+	if len(paths) > 0 {
+		fmt.Fprintf(b, "\t\t\tif test $npos -eq 0; and contains -- \"$cmdpath/$w\" %s\n", strings.Join(paths, " "))
+		fmt.Fprintf(b, "\t\t\t\tset cmdpath \"$cmdpath/$w\"\n")
+		fmt.Fprintf(b, "\t\t\telse\n")
+		fmt.Fprintf(b, "\t\t\t\tset npos (math $npos + 1)\n")
+		fmt.Fprintf(b, "\t\t\tend\n")
+	} else {
+		fmt.Fprintf(b, "\t\t\tset npos (math $npos + 1)\n")
+	}
+	fmt.Fprintf(b, "\t\tend\n")
+	fmt.Fprintf(b, "\tend\n")
+	fmt.Fprintf(b, "\ttest \"$cmdpath\" = \"$argv[1]\"\n")
+	fmt.Fprintf(b, "end\n\n")
+
+	for _, node := range nodes {
+		cond := fishQuote(fn + " " + fishQuote(node.path))
+		if len(node.children) > 0 {
+			fmt.Fprintf(b, "complete -c %s -f -n %s\n", prog, cond)
+			for _, child := range node.children {
+				fmt.Fprintf(b, "complete -c %s -n %s -a %s", prog, cond, fishQuote(child.name))
+				if child.desc != "" {
+					fmt.Fprintf(b, " -d %s", fishQuote(child.desc))
+				}
+				fmt.Fprintln(b)
+			}
+		}
+		for _, f := range node.flags {
+			fmt.Fprintf(b, "complete -c %s -n %s -o %s", prog, cond, fishQuote(f.name))
+			if f.hasValue {
+				fmt.Fprintf(b, " -r")
+			}
+			if f.doc != "" {
+				fmt.Fprintf(b, " -d %s", fishQuote(f.doc))
+			}
+			fmt.Fprintln(b)
+		}
+	}
+
+	return b.String()
+}
+
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "'", `\'`)
+	return "'" + s + "'"
+}
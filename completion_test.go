@@ -0,0 +1,91 @@
+package subcmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			script, err := Completion("prog", nestedtestcmd{}, shell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"migrate", "status", "-n"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("script does not contain %s", want)
+				}
+			}
+		})
+	}
+
+	if _, err := Completion("prog", nestedtestcmd{}, "tcsh"); err == nil {
+		t.Error("got no error for unknown shell")
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	script, err := Completion("prog", nestedtestcmd{}, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptFile := filepath.Join(t.TempDir(), "prog.bash")
+	if err := os.WriteFile(scriptFile, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		words []string
+		want  string
+	}{{
+		words: []string{"prog", ""},
+		want:  "a bb ccc db",
+	}, {
+		words: []string{"prog", "d"},
+		want:  "db",
+	}, {
+		words: []string{"prog", "db", ""},
+		want:  "migrate status",
+	}, {
+		words: []string{"prog", "db", "migrate", "-"},
+		want:  "-n",
+	}, {
+		words: []string{"prog", "db", "migrate", "-n", "status", ""},
+		want:  "",
+	}, {
+		words: []string{"prog", "a", "-a2", "db", "-"},
+		want:  "-a1 -a2 -a3",
+	}}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.words, "_"), func(t *testing.T) {
+			var words []string
+			for _, w := range tc.words {
+				words = append(words, "'"+w+"'")
+			}
+			cmd := exec.Command(bash, "--norc", "-c", `
+source "$1"
+COMP_WORDS=(`+strings.Join(words, " ")+`)
+COMP_CWORD=$((${#COMP_WORDS[@]} - 1))
+_prog
+printf "%s\n" "${COMPREPLY[*]}"
+`, "bash", scriptFile)
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tc.want {
+				t.Errorf(`got "%s", want "%s"`, got, tc.want)
+			}
+		})
+	}
+}
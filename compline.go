@@ -0,0 +1,104 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// completionRequest reports whether the program has been invoked by bash
+// to complete a command line
+// (as a result of "complete -C prog prog"),
+// and if so returns the portion of the command line preceding the cursor.
+// Only the outermost call to [Run] responds to such requests.
+func completionRequest(ctx context.Context) (string, bool) {
+	if len(subcmdPairList(ctx)) > 0 {
+		return "", false
+	}
+	line, ok := os.LookupEnv("COMP_LINE")
+	if !ok {
+		return "", false
+	}
+	point, err := strconv.Atoi(os.Getenv("COMP_POINT"))
+	if err != nil || point < 0 {
+		return "", false
+	}
+	if point < len(line) {
+		line = line[:point]
+	}
+	return line, true
+}
+
+// completeLine writes to [Stdout] the possible completions of the last word in line,
+// one per line,
+// as expected by bash's "complete -C".
+// The first word of line is the program name and is ignored.
+func completeLine(ctx context.Context, c Cmd, cmds Map, line string) error {
+	words := strings.Fields(line)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	var cur string
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var (
+		leaf       *Subcmd
+		positional bool
+	)
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if strings.HasPrefix(w, "-") {
+			if leaf == nil {
+				continue
+			}
+			if toks, _ := scanFlagArgs(leaf.Params, words[i:]); len(toks) > 0 && toks[0].idx == 0 && toks[0].val > 0 {
+				i++
+			}
+			continue
+		}
+		if leaf != nil || positional {
+			positional = true
+			continue
+		}
+		subcmd, ok := lookupSubcmd(c, cmds, w)
+		if !ok {
+			positional = true
+			continue
+		}
+		if subcmd.Sub != nil {
+			c, cmds = subcmd.Sub, nil
+			continue
+		}
+		leaf = &subcmd
+	}
+
+	var candidates []string
+	switch {
+	case leaf != nil:
+		if !strings.HasPrefix(cur, "-") {
+			break
+		}
+		for _, p := range leaf.Params {
+			if strings.HasPrefix(p.Name, "-") {
+				candidates = append(candidates, "-"+strings.TrimLeft(p.Name, "-"))
+			}
+		}
+
+	case !positional:
+		candidates = subcmdNames(listSubcmds(c, cmds))
+	}
+
+	for _, cand := range candidates {
+		if strings.HasPrefix(cand, cur) {
+			if _, err := fmt.Fprintln(Stdout(ctx), cand); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
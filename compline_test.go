@@ -0,0 +1,57 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompleteLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want []string
+	}{{
+		line: "prog ",
+		want: []string{"a", "bb", "ccc", "db"},
+	}, {
+		line: "prog b",
+		want: []string{"bb"},
+	}, {
+		line: "prog db ",
+		want: []string{"migrate", "status"},
+	}, {
+		line: "prog db migrate -",
+		want: []string{"-n"},
+	}, {
+		line: "prog db migrate -n 3 ",
+	}, {
+		line: "prog a -a2 7 -a",
+		want: []string{"-a1", "-a2", "-a3"},
+	}, {
+		line: "prog xyz ",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			defer testSetenv("COMP_LINE", tc.line+" ignored")()
+			defer testSetenv("COMP_POINT", strconv.Itoa(len(tc.line)))()
+
+			called := false
+			c := nestedtestcmd{migrate: func(context.Context, int, string, []string) { called = true }}
+
+			buf := new(bytes.Buffer)
+			if err := Run(context.Background(), c, []string{"prog", "x", "y"}, WithStdio(nil, buf, nil)); err != nil {
+				t.Fatal(err)
+			}
+			if called {
+				t.Error("subcommand was called")
+			}
+			got := strings.Fields(buf.String())
+			if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithConfigFlag is an [Option] that makes [Run] recognize a "-config PATH" flag
+// (or "--config PATH", "-config=PATH", or "--config=PATH")
+// preceding the subcommand name at the top level,
+// as in "prog -config settings.json list".
+// It may appear among the top-level persistent flags (see [PersistentFlagger]),
+// as in "prog -verbose -config settings.json list".
+// The named file supplies values for subcommand flags.
+// Values given on the command line take precedence over values in the config file,
+// and so do values from the environment (see [WithEnvPrefix]).
+//
+// The file contains an object whose keys are subcommand names.
+// The value for each subcommand is an object
+// whose keys are the names of the subcommand's flags (without the leading "-"),
+// or, for a subcommand with nested subcommands (see Subcmd.Sub),
+// the names of those.
+// For example:
+//
+//	{
+//	  "list": {"reverse": true},
+//	  "db": {"migrate": {"n": 3, "timeout": "5s"}}
+//	}
+//
+// An array value sets a flag once for each element,
+// which is useful for flags that accumulate values.
+// A null value is ignored.
+// A key in the section for the subcommand being run
+// that is not the name of one of its flags
+// produces a warning (see [WithWarningHandler]).
+//
+// The format of the file is determined by its extension.
+// JSON (".json") is supported by default.
+// Other formats may be added with [WithConfigFormat].
+//
+// If no -config flag is given,
+// and [WithEnvPrefix] is in effect,
+// the path may instead be given in the environment variable formed from the prefix plus "_CONFIG"
+// (e.g. MYAPP_CONFIG).
+//
+// Otherwise a file named "config" plus one of the supported extensions
+// is sought in a directory named for the program (see [ProgName])
+// in the user's configuration directories.
+// These are, in order:
+// $XDG_CONFIG_HOME, if set;
+// the platform's default configuration directory (see [os.UserConfigDir]);
+// and $HOME/.config.
+// For example,
+// the program "myapp" on Linux
+// looks for ~/.config/myapp/config.json.
+// The first such file found is used.
+func WithConfigFlag() Option {
+	return func(c *config) error {
+		c.configFlag = true
+		return nil
+	}
+}
+
+// WithConfigFormat is an [Option] that adds support for config files with the given extension
+// (see [WithConfigFlag]),
+// which are decoded with unmarshal.
+// For example:
+//
+//	subcmd.WithConfigFormat(".yaml", yaml.Unmarshal)
+//	subcmd.WithConfigFormat(".toml", toml.Unmarshal)
+//
+// The unmarshal function is called with a pointer to a map[string]any.
+func WithConfigFormat(ext string, unmarshal func([]byte, interface{}) error) Option {
+	return func(c *config) error {
+		if c.configFormats == nil {
+			c.configFormats = make(map[string]func([]byte, interface{}) error)
+		}
+		c.configFormats[ext] = unmarshal
+		return nil
+	}
+}
+
+// parseConfigFlag checks for a -config flag at the start of args,
+// among the persistent flags of c (if any).
+// If there is one,
+// it loads the named file into a new context
+// and returns that and the args without the -config flag.
+func parseConfigFlag(ctx context.Context, c Cmd, args []string) (context.Context, []string, error) {
+	if !getConfig(ctx).configFlag || len(subcmdPairList(ctx)) > 0 {
+		return ctx, args, nil
+	}
+
+	params, err := persistentParams(ctx, c)
+	if err != nil {
+		return ctx, args, err
+	}
+	params = append(params, Param{Name: "-config", Type: String})
+
+	var (
+		path  string
+		found bool
+		drop  = make(map[int]bool)
+	)
+	toks, _ := scanFlagArgs(params, args)
+	for _, tok := range toks {
+		if tok.name != "config" {
+			continue
+		}
+		drop[tok.idx] = true
+		if tok.val >= 0 {
+			path = args[tok.val]
+			drop[tok.val] = true
+		} else if _, val, ok := strings.Cut(args[tok.idx], "="); ok {
+			path = val
+		} else {
+			return ctx, args, fmt.Errorf("flag needs an argument: %s", args[tok.idx])
+		}
+		found = true
+	}
+
+	if found {
+		rest := make([]string, 0, len(args)-len(drop))
+		for i, arg := range args {
+			if !drop[i] {
+				rest = append(rest, arg)
+			}
+		}
+		args = rest
+	} else {
+		path = configFileFromEnv(ctx)
+		if path == "" {
+			path = findConfigFile(ctx)
+		}
+		if path == "" {
+			return ctx, args, nil
+		}
+	}
+
+	ctx, err = loadConfigFile(ctx, path)
+	return ctx, args, err
+}
+
+// configFileFromEnv returns the config-file path named in the environment,
+// if WithEnvPrefix is in effect,
+// or else "".
+func configFileFromEnv(ctx context.Context) string {
+	prefix := getConfig(ctx).envPrefix
+	if prefix == "" {
+		return ""
+	}
+	return os.Getenv(envVarName(prefix, nil, "config"))
+}
+
+// findConfigFile looks for a config file in the default locations,
+// returning "" if there isn't one.
+func findConfigFile(ctx context.Context) string {
+	exts := []string{".json"}
+	var others []string
+	for ext := range getConfig(ctx).configFormats {
+		if ext != ".json" {
+			others = append(others, ext)
+		}
+	}
+	sort.Strings(others)
+	exts = append(exts, others...)
+
+	prog := filepath.Base(ProgName(ctx))
+	if runtime.GOOS == "windows" {
+		prog = strings.TrimSuffix(prog, ".exe")
+	}
+
+	for _, dir := range configDirs() {
+		for _, ext := range exts {
+			path := filepath.Join(dir, prog, "config"+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// configDirs returns the directories in which to look for program-specific config files.
+func configDirs() []string {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	add := func(dir string) {
+		if dir != "" && !seen[dir] {
+			result = append(result, dir)
+			seen[dir] = true
+		}
+	}
+
+	add(os.Getenv("XDG_CONFIG_HOME"))
+	if dir, err := os.UserConfigDir(); err == nil {
+		add(dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		add(filepath.Join(home, ".config"))
+	}
+
+	return result
+}
+
+// loadConfigFile reads and decodes the config file at path
+// and returns a new context containing the result.
+func loadConfigFile(ctx context.Context, path string) (context.Context, error) {
+	ext := filepath.Ext(path)
+	unmarshal := json.Unmarshal
+	if ext != ".json" {
+		var ok bool
+		if unmarshal, ok = getConfig(ctx).configFormats[ext]; !ok {
+			return ctx, fmt.Errorf("unknown config file format %s", ext)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ctx, fmt.Errorf("reading config file: %w", err)
+	}
+	var m map[string]interface{}
+	if err := unmarshal(data, &m); err != nil {
+		return ctx, fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+
+	logDebug(ctx, "loaded config file", "path", path)
+
+	return withOptions(ctx, []Option{func(c *config) error {
+		c.configData = m
+		return nil
+	}})
+}
+
+// applyConfigFile sets the flags in fs from the config file, if there is one.
+func applyConfigFile(ctx context.Context, fs *flag.FlagSet) error {
+	m := getConfig(ctx).configData
+	if m == nil {
+		return nil
+	}
+
+	path := CommandPath(ctx)
+	for _, name := range path {
+		var ok bool
+		if m, ok = configSection(m[name]); !ok {
+			return nil
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if fs.Lookup(key) == nil {
+			warn(ctx, `unknown key "%s" in config file for "%s"`, key, strings.Join(path, " "))
+			continue
+		}
+		vals, ok := m[key].([]interface{})
+		if !ok {
+			vals = []interface{}{m[key]}
+		}
+		for _, val := range vals {
+			if val == nil {
+				continue
+			}
+			if err := fs.Set(key, configString(val)); err != nil {
+				return fmt.Errorf("setting -%s from config file: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// configSection converts a decoded config-file value to a map,
+// if it is one.
+// Some decoders produce map[interface{}]interface{} rather than map[string]interface{}.
+func configSection(val interface{}) (map[string]interface{}, bool) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			result[fmt.Sprint(k)] = vv
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func configString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,171 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlag(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"db": {"migrate": {"n": 3, "bogus": true}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		gotN     int
+		warnings []string
+	)
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+	h := func(_ context.Context, msg string) { warnings = append(warnings, msg) }
+
+	if err := Run(context.Background(), c, []string{"-config", path, "db", "migrate", "dir"}, WithConfigFlag(), WithWarningHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 3 {
+		t.Errorf("got n=%d, want 3", gotN)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"bogus"`) {
+		t.Errorf("got warnings %v, want one about bogus", warnings)
+	}
+
+	if err := Run(context.Background(), c, []string{"--config=" + path, "db", "migrate", "-n", "4", "dir"}, WithConfigFlag(), WithWarningHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 4 {
+		t.Errorf("with command-line flag, got n=%d, want 4", gotN)
+	}
+
+	var u *UnknownSubcmdErr
+	if err := Run(context.Background(), c, []string{"-config", path, "db", "migrate", "dir"}); !errors.As(err, &u) {
+		t.Errorf("without WithConfigFlag, got %v, want *UnknownSubcmdErr", err)
+	}
+
+	// A custom format.
+	path = filepath.Join(dir, "config.custom")
+	if err := os.WriteFile(path, []byte(`{"db": {"migrate": {"n": 5}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(context.Background(), c, []string{"-config", path, "db", "migrate", "dir"}, WithConfigFlag()); err == nil {
+		t.Error("got no error for unknown config file format")
+	}
+	if err := Run(context.Background(), c, []string{"-config", path, "db", "migrate", "dir"}, WithConfigFlag(), WithConfigFormat(".custom", json.Unmarshal)); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 5 {
+		t.Errorf("with custom format, got n=%d, want 5", gotN)
+	}
+}
+
+func TestConfigSearch(t *testing.T) {
+	dir := t.TempDir()
+	defer testSetenv("XDG_CONFIG_HOME", dir)()
+
+	if err := os.Mkdir(filepath.Join(dir, "myapp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "myapp", "config.json"), []byte(`{"db": {"migrate": {"n": 6}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotN int
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithConfigFlag(), WithProgName("/usr/bin/myapp")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 6 {
+		t.Errorf("got n=%d, want 6", gotN)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithConfigFlag(), WithProgName("otherapp")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 1 {
+		t.Errorf("with no config file, got n=%d, want 1", gotN)
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{"db": {"migrate": {"n": 6}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotN int
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+
+	defer testSetenv("MYAPP_CONFIG", path)()
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithConfigFlag(), WithEnvPrefix("MYAPP")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 6 {
+		t.Errorf("got n=%d, want 6", gotN)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "7", "dir"}, WithConfigFlag(), WithEnvPrefix("MYAPP")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 7 {
+		t.Errorf("with command-line flag, got n=%d, want 7", gotN)
+	}
+}
+
+func TestConfigFlagPersistent(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"a": {"verbose": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{name: "after", args: []string{"-level", "2", "-config", path, "a"}},
+		{name: "before", args: []string{"-config=" + path, "-level", "2", "a"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got persistentResult
+			c := persistenttestcmd{got: &got}
+			if err := Run(context.Background(), c, tc.args, WithConfigFlag()); err != nil {
+				t.Fatal(err)
+			}
+			if want := (persistentResult{local: true, level: 2}); got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	var got persistentResult
+	if err := Run(context.Background(), persistenttestcmd{got: &got}, []string{"-level", "2", "-config"}, WithConfigFlag()); err == nil {
+		t.Error("got no error for -config without a value")
+	}
+}
+
+func TestConfigNull(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"db": {"migrate": {"n": null, "timeout": "5s"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gotN := -1
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+	if err := Run(context.Background(), c, []string{"-config", path, "db", "migrate", "dir"}, WithConfigFlag(), WithWarningHandler(func(context.Context, string) {})); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 1 {
+		t.Errorf("got n=%d, want the default 1", gotN)
+	}
+}
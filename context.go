@@ -3,6 +3,7 @@ package subcmd
 import (
 	"context"
 	"flag"
+	"strings"
 )
 
 type ctxkey int
@@ -10,6 +11,11 @@ type ctxkey int
 const (
 	fsKey ctxkey = iota + 1
 	subcmdPairListKey
+	configKey
+	argsKey
+	positionalKey
+	enrichersKey
+	persistentKey
 )
 
 func withFlagSet(ctx context.Context, fs *flag.FlagSet) context.Context {
@@ -28,22 +34,182 @@ type subcmdPair struct {
 }
 
 func subcmdPairList(ctx context.Context) []subcmdPair {
-	pairListPtr := ctx.Value(subcmdPairListKey)
-	if pairListPtr == nil {
+	pairs, _ := ctx.Value(subcmdPairListKey).([]subcmdPair)
+	return pairs
+}
+
+// addSubcmdPair returns a context whose subcmd pair list is that of ctx plus the given pair.
+// The list in ctx is not modified,
+// so a ctx can be reused for multiple calls to Run.
+func addSubcmdPair(ctx context.Context, name string, subcmd Subcmd) context.Context {
+	pairs := subcmdPairList(ctx)
+	pairs = append(pairs[:len(pairs):len(pairs)], subcmdPair{name: name, subcmd: subcmd})
+	return context.WithValue(ctx, subcmdPairListKey, pairs)
+}
+
+// Lookup returns the value of the named flag
+// in the [flag.FlagSet] used in a call to a [Subcmd] function
+// (see [FlagSet]).
+// The name may be given with or without its leading "-".
+//
+// If T is the type of the flag's value
+// (as reported by its Get method; see [flag.Getter]),
+// the result is that value.
+// If T is an interface type (such as [flag.Value]) that the flag's [flag.Value] implements,
+// the result is the flag.Value itself.
+//
+// The boolean result is false if there is no FlagSet in ctx,
+// no such flag,
+// or the flag's value cannot be converted to type T.
+func Lookup[T any](ctx context.Context, name string) (T, bool) {
+	var zero T
+
+	fs, ok := ctx.Value(fsKey).(*flag.FlagSet)
+	if !ok {
+		return zero, false
+	}
+	f := fs.Lookup(strings.TrimLeft(name, "-"))
+	if f == nil {
+		return zero, false
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		if val, ok := getter.Get().(T); ok {
+			return val, true
+		}
+	}
+	if val, ok := f.Value.(T); ok {
+		return val, true
+	}
+	return zero, false
+}
+
+// Changed tells whether the named flag
+// in the [flag.FlagSet] used in a call to a [Subcmd] function
+// (see [FlagSet])
+// was given a value,
+// as opposed to having its default.
+// The name may be given with or without its leading "-".
+//
+// A flag counts as given if it's set on the command line
+// or by some other means,
+// such as an environment variable (see [WithEnvPrefix]) or a config file (see [WithConfigFlag]).
+//
+// This lets subcommands implement "update only what the user specified" semantics.
+// See also [ChangedFlags].
+func Changed(ctx context.Context, name string) bool {
+	name = strings.TrimLeft(name, "-")
+	for _, n := range ChangedFlags(ctx) {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangedFlags returns the names
+// (without leading dashes)
+// of the flags in the [flag.FlagSet] used in a call to a [Subcmd] function
+// that were given values,
+// in lexicographical order.
+// See [Changed].
+func ChangedFlags(ctx context.Context) []string {
+	fs, ok := ctx.Value(fsKey).(*flag.FlagSet)
+	if !ok {
 		return nil
 	}
-	return *(pairListPtr.(*[]subcmdPair))
+	var result []string
+	fs.Visit(func(f *flag.Flag) {
+		result = append(result, f.Name)
+	})
+	return result
 }
 
-func addSubcmdPair(ctx context.Context, name string, subcmd Subcmd) context.Context {
-	var pairListPtr *[]subcmdPair
-	if pairListPtrVal := ctx.Value(subcmdPairListKey); pairListPtrVal == nil {
-		var pairList []subcmdPair
-		pairListPtr = &pairList
-		ctx = context.WithValue(ctx, subcmdPairListKey, pairListPtr)
-	} else {
-		pairListPtr = pairListPtrVal.(*[]subcmdPair)
-	}
-	*pairListPtr = append(*pairListPtr, subcmdPair{name: name, subcmd: subcmd})
-	return ctx
+// Positional returns the value of the named positional parameter
+// parsed for the current call to a [Subcmd] function.
+// The name may be given with or without the trailing "?" of an optional parameter.
+// This lets helpers called from a subcommand function
+// retrieve positional parameters without their being passed explicitly.
+//
+// If T is the type of the parameter's value,
+// the result is that value.
+// For a parameter of type [Value],
+// T may be flag.Value (or another interface the value implements),
+// or the type reported by its Get method (see [flag.Getter]).
+//
+// The boolean result is false if there is no such parameter
+// or its value cannot be converted to type T.
+func Positional[T any](ctx context.Context, name string) (T, bool) {
+	var zero T
+
+	posvals, ok := ctx.Value(positionalKey).(map[string]interface{})
+	if !ok {
+		return zero, false
+	}
+	val, ok := posvals[strings.TrimSuffix(name, "?")]
+	if !ok {
+		return zero, false
+	}
+	if v, ok := val.(T); ok {
+		return v, true
+	}
+	if getter, ok := val.(flag.Getter); ok {
+		if v, ok := getter.Get().(T); ok {
+			return v, true
+		}
+	}
+	return zero, false
+}
+
+// CommandPath returns the names of the subcommands dispatched so far by [Run],
+// outermost first.
+// For example,
+// in the function implementing "foo bar baz"
+// (where "baz" is a subcommand of "bar", which is a subcommand of "foo"),
+// it returns []string{"foo", "bar", "baz"}.
+// It does not include the program name.
+func CommandPath(ctx context.Context) []string {
+	pairs := subcmdPairList(ctx)
+	result := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		result = append(result, pair.name)
+	}
+	return result
+}
+
+// SubcmdPair is an element of the result of [SubcmdChain].
+type SubcmdPair struct {
+	Name   string
+	Subcmd Subcmd
+}
+
+// SubcmdChain returns the subcommands dispatched so far by [Run],
+// with their names,
+// outermost first.
+// It is like [CommandPath] but also gives access to each subcommand's description, parameters, and so on.
+//
+// The result is a copy,
+// but the Subcmd values in it share their Params slices with the original ones,
+// which must not be modified.
+func SubcmdChain(ctx context.Context) []SubcmdPair {
+	pairs := subcmdPairList(ctx)
+	result := make([]SubcmdPair, 0, len(pairs))
+	for _, pair := range pairs {
+		result = append(result, SubcmdPair{Name: pair.name, Subcmd: pair.subcmd})
+	}
+	return result
+}
+
+// Args returns the arguments that [Run] received
+// when it dispatched to the current subcommand,
+// before any flag parsing.
+// The first element is the subcommand name.
+// For example,
+// in the function implementing "foo bar -x 1 baz"
+// (where "bar" is a subcommand of "foo"),
+// it returns []string{"bar", "-x", "1", "baz"}.
+//
+// The result is a copy and may be modified freely.
+func Args(ctx context.Context) []string {
+	args, _ := ctx.Value(argsKey).([]string)
+	return append([]string(nil), args...)
 }
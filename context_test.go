@@ -2,7 +2,13 @@ package subcmd
 
 import (
 	"context"
+	"flag"
+	"net/netip"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestSubcmdPairs(t *testing.T) {
@@ -36,3 +42,258 @@ func TestSubcmdPairs(t *testing.T) {
 		}
 	}
 }
+
+func TestLookup(t *testing.T) {
+	var (
+		gotN, gotStr   bool
+		n              int
+		str            string
+		gotD, gotValue bool
+		gotMissing     bool
+		gotWrongType   bool
+	)
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(ctx context.Context, _ int, _ string, _ time.Duration, _ flag.Value, _ []string) {
+			n, gotN = Lookup[int](ctx, "n")
+			str, gotStr = Lookup[string](ctx, "-s")
+			_, gotD = Lookup[time.Duration](ctx, "d")
+			_, gotValue = Lookup[flag.Value](ctx, "v")
+			_, gotMissing = Lookup[int](ctx, "missing")
+			_, gotWrongType = Lookup[string](ctx, "n")
+		}, "", Params(
+			"-n", Int, 0, "",
+			"-s", String, "", "",
+			"-d", Duration, time.Second, "",
+			"-v", Value, &valuetestvalue{}, "",
+		))
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-n", "3", "-s", "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotN || n != 3 {
+		t.Errorf("got n=%d (%v), want 3 (true)", n, gotN)
+	}
+	if !gotStr || str != "foo" {
+		t.Errorf(`got s="%s" (%v), want "foo" (true)`, str, gotStr)
+	}
+	if !gotD {
+		t.Error("could not look up Duration flag")
+	}
+	if !gotValue {
+		t.Error("could not look up Value flag")
+	}
+	if gotMissing {
+		t.Error("found nonexistent flag")
+	}
+	if gotWrongType {
+		t.Error("found flag with wrong type")
+	}
+
+	if _, ok := Lookup[int](context.Background(), "n"); ok {
+		t.Error("found flag in context without FlagSet")
+	}
+
+	t.Run("internal types", func(t *testing.T) {
+		type jsonOpts struct{ N int }
+
+		var got []interface{}
+		c := testcmdfunc(func() Map {
+			return Map{"x": Subcmd{
+				F: func(ctx context.Context, _ []string, _ map[string]string, _ string, _ time.Time, _ *jsonOpts, _ netip.Addr, _ []string) {
+					var oks []bool
+					add := func(v interface{}, ok bool) {
+						got = append(got, v)
+						oks = append(oks, ok)
+					}
+					add(Lookup[[]string](ctx, "tag"))
+					add(Lookup[map[string]string](ctx, "label"))
+					add(Lookup[string](ctx, "color"))
+					add(Lookup[time.Time](ctx, "at"))
+					add(Lookup[*jsonOpts](ctx, "opts"))
+					add(Lookup[netip.Addr](ctx, "addr"))
+					for i, ok := range oks {
+						if !ok {
+							t.Errorf("lookup %d failed", i)
+						}
+					}
+				},
+				Params: []Param{
+					{Name: "-tag", Type: Strings},
+					{Name: "-label", Type: StringMap},
+					{Name: "-color", Type: String, Choices: []string{"red", "blue"}},
+					{Name: "-at", Type: Time, Default: time.Time{}},
+					{Name: "-opts", Type: JSON, Default: &jsonOpts{}},
+					{Name: "-addr", Type: Custom, Default: netip.Addr{}, Parse: ParseFunc(netip.ParseAddr)},
+				},
+			}}
+		})
+		args := []string{"x", "-tag", "a", "-tag", "b", "-label", "k=v", "-color", "blue", "-at", "2024-01-02T03:04:05Z", "-opts", `{"N": 7}`, "-addr", "::1"}
+		if err := Run(context.Background(), c, args); err != nil {
+			t.Fatal(err)
+		}
+		want := []interface{}{
+			[]string{"a", "b"},
+			map[string]string{"k": "v"},
+			"blue",
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			&jsonOpts{N: 7},
+			netip.MustParseAddr("::1"),
+		}
+		if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestChanged(t *testing.T) {
+	if got := ChangedFlags(context.Background()); len(got) != 0 {
+		t.Errorf("got %v, want no flags", got)
+	}
+
+	var (
+		gotN, gotX bool
+		gotFlags   []string
+	)
+	c := testcmdfunc(func() Map {
+		return Commands("run", func(ctx context.Context, _ int, _ bool, _ []string) {
+			gotN, gotX = Changed(ctx, "-n"), Changed(ctx, "x")
+			gotFlags = ChangedFlags(ctx)
+		}, "", Params(
+			"-n", Int, 1, "",
+			"-x", Bool, false, "",
+		))
+	})
+
+	// Setting a flag to its default value still counts.
+	if err := Run(context.Background(), c, []string{"run", "-n", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotN || gotX {
+		t.Errorf("got n changed %v, x changed %v; want true, false", gotN, gotX)
+	}
+	if !reflect.DeepEqual(gotFlags, []string{"n"}) {
+		t.Errorf("got %v, want [n]", gotFlags)
+	}
+}
+
+func TestCommandPath(t *testing.T) {
+	if got := CommandPath(context.Background()); len(got) != 0 {
+		t.Errorf("got %v, want empty path", got)
+	}
+
+	var got []string
+	c := nestedtestcmd{migrate: func(ctx context.Context, _ int, _ string, _ []string) {
+		got = CommandPath(ctx)
+	}}
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "db" || got[1] != "migrate" {
+		t.Errorf("got %v, want [db migrate]", got)
+	}
+}
+
+func TestCommandPathNestedRuns(t *testing.T) {
+	var got [][]string
+	inner := testcmdfunc(func() Map {
+		return Commands("leaf", func(ctx context.Context, _ []string) {
+			got = append(got, CommandPath(ctx))
+		}, "", nil)
+	})
+	outer := testcmdfunc(func() Map {
+		return Commands("repl", func(ctx context.Context, _ []string) error {
+			for i := 0; i < 3; i++ {
+				if err := Run(ctx, inner, []string{"leaf"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, "", nil)
+	})
+	if err := Run(context.Background(), outer, []string{"repl"}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"repl", "leaf"}, {"repl", "leaf"}, {"repl", "leaf"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSubcmdChain(t *testing.T) {
+	var got []SubcmdPair
+	c := nestedtestcmd{migrate: func(ctx context.Context, _ int, _ string, _ []string) {
+		got = SubcmdChain(ctx)
+	}}
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d pairs, want 2", len(got))
+	}
+	if got[0].Name != "db" || got[0].Subcmd.Desc != "database commands" {
+		t.Errorf("got %s (%s), want db (database commands)", got[0].Name, got[0].Subcmd.Desc)
+	}
+	if got[1].Name != "migrate" || len(got[1].Subcmd.Params) != 2 {
+		t.Errorf("got %s with %d params, want migrate with 2 params", got[1].Name, len(got[1].Subcmd.Params))
+	}
+}
+
+func TestArgs(t *testing.T) {
+	if got := Args(context.Background()); len(got) != 0 {
+		t.Errorf("got %v, want no args", got)
+	}
+
+	var got []string
+	c := nestedtestcmd{migrate: func(ctx context.Context, _ int, _ string, _ []string) {
+		got = Args(ctx)
+	}}
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "3", "dir"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"migrate", "-n", "3", "dir"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPositional(t *testing.T) {
+	var (
+		dir             string
+		gotDir, gotOpt  bool
+		gotMissing      bool
+		gotWrongType    bool
+		gotFlagAsPosArg bool
+	)
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(ctx context.Context, _ int, _ string, _ string, _ []string) {
+			dir, gotDir = Positional[string](ctx, "dir")
+			_, gotOpt = Positional[string](ctx, "opt?")
+			_, gotMissing = Positional[string](ctx, "missing")
+			_, gotWrongType = Positional[int](ctx, "dir")
+			_, gotFlagAsPosArg = Positional[int](ctx, "n")
+		}, "", Params(
+			"-n", Int, 0, "",
+			"dir", String, "", "",
+			"opt?", String, "", "",
+		))
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-n", "3", "here"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotDir || dir != "here" {
+		t.Errorf(`got dir="%s" (%v), want "here" (true)`, dir, gotDir)
+	}
+	if !gotOpt {
+		t.Error("could not look up optional parameter")
+	}
+	if gotMissing {
+		t.Error("found nonexistent parameter")
+	}
+	if gotWrongType {
+		t.Error("found parameter with wrong type")
+	}
+	if gotFlagAsPosArg {
+		t.Error("found flag as positional parameter")
+	}
+}
@@ -0,0 +1,113 @@
+package subcmd
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// ParseFunc adapts a function that parses a string into a T
+// for use as the Parse field of a [Param] with type [Custom].
+// For example:
+//
+//	subcmd.Param{
+//	  Name:    "-addr",
+//	  Type:    subcmd.Custom,
+//	  Default: netip.Addr{},
+//	  Parse:   subcmd.ParseFunc(netip.ParseAddr),
+//	}
+func ParseFunc[T any](f func(string) (T, error)) func(string) (interface{}, error) {
+	return func(s string) (interface{}, error) {
+		return f(s)
+	}
+}
+
+// customValue is the [flag.Value] for a parameter of type [Custom].
+type customValue struct {
+	ptr   reflect.Value // pointer to the value passed to the subcommand's function
+	parse func(string) (interface{}, error)
+}
+
+func newCustomValue(p Param) *customValue {
+	dflt := reflect.ValueOf(p.Default)
+	ptr := reflect.New(dflt.Type())
+	ptr.Elem().Set(dflt)
+	return &customValue{ptr: ptr, parse: p.Parse}
+}
+
+func (v *customValue) String() string {
+	if v == nil || !v.ptr.IsValid() {
+		return ""
+	}
+	if m, ok := v.ptr.Elem().Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v.ptr.Elem().Interface())
+}
+
+func (v *customValue) Set(s string) error {
+	val, err := parseCustom(v.parse, v.ptr.Type().Elem(), s)
+	if err != nil {
+		return err
+	}
+	v.ptr.Elem().Set(val)
+	return nil
+}
+
+// Get implements [flag.Getter].
+func (v *customValue) Get() interface{} {
+	return v.ptr.Elem().Interface()
+}
+
+// parseCustom calls parse on s
+// and checks that the result has type typ.
+func parseCustom(parse func(string) (interface{}, error), typ reflect.Type, s string) (reflect.Value, error) {
+	x, err := parse(s)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	val := reflect.ValueOf(x)
+	if !val.IsValid() || !val.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("parse function returned %T, want %s", x, typ)
+	}
+	return val, nil
+}
+
+func parseCustomPos(args *[]string, argvals *[]reflect.Value, p Param) error {
+	if p.Default == nil || p.Parse == nil {
+		return ParseErr{Err: fmt.Errorf("param %s has type Custom but no default value or Parse function", p.Name)}
+	}
+	val := reflect.ValueOf(p.Default)
+	if len(*args) > 0 {
+		var err error
+		if val, err = parseCustom(p.Parse, val.Type(), (*args)[0]); err != nil {
+			return parseErr(p, (*args)[0], err)
+		}
+		*args = (*args)[1:]
+	}
+	*argvals = append(*argvals, val)
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// textParseFunc produces a parse function for a parameter of type [Text],
+// using the UnmarshalText method of a pointer to its default value's type.
+func textParseFunc(p Param) (func(string) (interface{}, error), error) {
+	if _, ok := p.Default.(encoding.TextMarshaler); !ok {
+		return nil, fmt.Errorf("param %s has type Text but default value %v is not an encoding.TextMarshaler", p.Name, p.Default)
+	}
+	typ := reflect.TypeOf(p.Default)
+	if !reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return nil, fmt.Errorf("param %s has type Text but *%s is not an encoding.TextUnmarshaler", p.Name, typ)
+	}
+	return func(s string) (interface{}, error) {
+		ptr := reflect.New(typ)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}, nil
+}
@@ -0,0 +1,96 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestCustomParam(t *testing.T) {
+	var gotAddr, gotPeer netip.Addr
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, addr, peer netip.Addr, _ []string) { gotAddr, gotPeer = addr, peer },
+				Params: []Param{
+					{Name: "-addr", Type: Custom, Default: netip.MustParseAddr("127.0.0.1"), Parse: ParseFunc(netip.ParseAddr)},
+					{Name: "peer?", Type: Custom, Default: netip.Addr{}, Parse: ParseFunc(netip.ParseAddr)},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr.String() != "127.0.0.1" || gotPeer.IsValid() {
+		t.Errorf("got addr=%s peer=%s, want 127.0.0.1 and invalid", gotAddr, gotPeer)
+	}
+
+	if err := Run(context.Background(), c, []string{"x", "-addr", "::1", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr.String() != "::1" || gotPeer.String() != "10.0.0.1" {
+		t.Errorf("got addr=%s peer=%s, want ::1 10.0.0.1", gotAddr, gotPeer)
+	}
+
+	var f FlagErr
+	if err := Run(context.Background(), c, []string{"x", "-addr", "bogus"}); !errors.As(err, &f) {
+		t.Errorf("got %v, want FlagErr", err)
+	}
+	var p ParseErr
+	if err := Run(context.Background(), c, []string{"x", "bogus"}); !errors.As(err, &p) {
+		t.Errorf("got %v, want ParseErr", err)
+	}
+
+	wrongType := Subcmd{
+		F:      func(context.Context, netip.Addr, []string) {},
+		Params: []Param{{Name: "-addr", Type: Custom, Default: netip.Addr{}, Parse: func(string) (interface{}, error) { return 7, nil }}},
+	}
+	if err := Run(context.Background(), testcmdfunc(func() Map { return Map{"x": wrongType} }), []string{"x", "-addr", "1.2.3.4"}); err == nil {
+		t.Error("got no error for parse function returning the wrong type")
+	}
+
+	if err := Check(Subcmd{F: func(context.Context, netip.Addr, []string) {}, Params: []Param{{Name: "-addr", Type: Custom, Default: netip.Addr{}}}}); err == nil {
+		t.Error("got no error for missing Parse function")
+	}
+}
+
+func TestTextParam(t *testing.T) {
+	var gotAddr, gotPeer netip.Addr
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, addr, peer netip.Addr, _ []string) { gotAddr, gotPeer = addr, peer },
+				Params: []Param{
+					{Name: "-addr", Type: Text, Default: netip.MustParseAddr("127.0.0.1")},
+					{Name: "peer", Type: Text, Default: netip.Addr{}},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr.String() != "127.0.0.1" || gotPeer.String() != "10.0.0.1" {
+		t.Errorf("got addr=%s peer=%s, want 127.0.0.1 10.0.0.1", gotAddr, gotPeer)
+	}
+
+	if err := Run(context.Background(), c, []string{"x", "-addr", "::1", "10.0.0.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr.String() != "::1" || gotPeer.String() != "10.0.0.2" {
+		t.Errorf("got addr=%s peer=%s, want ::1 10.0.0.2", gotAddr, gotPeer)
+	}
+
+	var f FlagErr
+	if err := Run(context.Background(), c, []string{"x", "-addr", "bogus", "10.0.0.1"}); !errors.As(err, &f) {
+		t.Errorf("got %v, want FlagErr", err)
+	}
+
+	if err := Check(Subcmd{F: func(context.Context, int, []string) {}, Params: []Param{{Name: "-n", Type: Text, Default: 0}}}); err == nil {
+		t.Error("got no error for default that is not a TextMarshaler")
+	}
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Call connects to a server started with [Serve] at the given network address
+// (see [net.Dial])
+// and asks it to run the command line in args.
+// Output from the subcommand is copied to stdout and stderr,
+// either of which may be nil to discard it.
+//
+// If the subcommand fails,
+// the result is a [*RemoteErr].
+// Canceling ctx closes the connection,
+// which cancels the subcommand on the server.
+func Call(ctx context.Context, network, address string, args []string, stdout, stderr io.Writer) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return fmt.Errorf("dialing %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := json.NewEncoder(conn).Encode(Request{Args: args}); err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	dec := json.NewDecoder(conn)
+	for {
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("receiving response: %w", err)
+		}
+		if len(resp.Stdout) > 0 {
+			if _, err := stdout.Write(resp.Stdout); err != nil {
+				return fmt.Errorf("writing stdout: %w", err)
+			}
+		}
+		if len(resp.Stderr) > 0 {
+			if _, err := stderr.Write(resp.Stderr); err != nil {
+				return fmt.Errorf("writing stderr: %w", err)
+			}
+		}
+		if resp.Done {
+			if resp.Error != "" {
+				return &RemoteErr{Msg: resp.Error, Usage: resp.Detail, Status: resp.ExitCode}
+			}
+			return nil
+		}
+	}
+}
+
+// RemoteErr is the error returned by [Call] when the remote subcommand fails.
+type RemoteErr struct {
+	// Msg is the error message from the server.
+	Msg string
+
+	// Usage is the detailed usage message from the server,
+	// if the error there was a [subcmd.UsageErr].
+	Usage string
+
+	// Status is the exit status that [subcmd.Main] would have used for the error on the server
+	// (see [subcmd.ExitStatus]).
+	Status int
+}
+
+func (e *RemoteErr) Error() string {
+	return e.Msg
+}
+
+// Detail returns the detailed usage message for e, if there is one,
+// otherwise the same string as Error.
+// With this method, RemoteErr implements [subcmd.UsageErr].
+func (e *RemoteErr) Detail() string {
+	if e.Usage != "" {
+		return e.Usage
+	}
+	return e.Msg
+}
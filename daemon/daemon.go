@@ -0,0 +1,166 @@
+// Package daemon serves a subcmd.Cmd over a network connection,
+// such as a Unix-domain socket.
+//
+// A long-running server process calls [Serve] with a listener and a [subcmd.Cmd].
+// A thin client calls [Call] with a command line.
+// The server parses and runs the command line with [subcmd.Run],
+// streaming the subcommand's standard output and standard error back to the client
+// (for subcommand functions that use [subcmd.Stdout] and [subcmd.Stderr]).
+// This lets heavyweight programs pay their startup costs once,
+// and lets other programs automate the same set of commands remotely.
+//
+// The protocol is a sequence of newline-delimited JSON objects.
+// The client sends a single [Request].
+// The server responds with zero or more [Response] objects carrying output,
+// followed by a final Response with Done set.
+// The subcommand's context is canceled if the client disconnects early.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+// Request is the message a client sends to the server.
+type Request struct {
+	// Args is the command line to run,
+	// beginning with the subcommand name.
+	Args []string `json:"args"`
+}
+
+// Response is a message the server sends to the client.
+type Response struct {
+	// Stdout and Stderr carry output from the subcommand.
+	// They are []byte rather than string so that output that is not valid UTF-8 survives the trip
+	// (encoded in JSON as base64).
+	Stdout []byte `json:"stdout,omitempty"`
+	Stderr []byte `json:"stderr,omitempty"`
+
+	// Done is true in the final response.
+	Done bool `json:"done,omitempty"`
+
+	// Error is the result of the call to subcmd.Run, if it failed.
+	// It is set only in the final response.
+	Error string `json:"error,omitempty"`
+
+	// Detail is the Detail string of Error, if Error is a [subcmd.UsageErr].
+	Detail string `json:"detail,omitempty"`
+
+	// ExitCode is the exit status that [subcmd.Main] would use for the result of subcmd.Run
+	// (see [subcmd.ExitStatus]).
+	// It is set only in the final response.
+	ExitCode int `json:"exit_code,omitempty"`
+}
+
+// Serve accepts connections on ln,
+// running a command line received on each one against c,
+// until ctx is canceled or ln fails.
+// The opts are passed to each call to [subcmd.Run].
+//
+// Connections are handled concurrently,
+// so c and its subcommands must be safe for concurrent use.
+func Serve(ctx context.Context, ln net.Listener, c subcmd.Cmd, opts ...subcmd.Option) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			handle(ctx, conn, c, opts)
+		}()
+	}
+}
+
+func handle(ctx context.Context, conn net.Conn, c subcmd.Cmd, opts []subcmd.Option) {
+	var (
+		dec = json.NewDecoder(conn)
+		req Request
+	)
+	if err := dec.Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(Response{Done: true, Error: "decoding request: " + err.Error(), ExitCode: 1})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The client sends nothing after its request,
+	// so a read returning means it has gone away.
+	go func() {
+		io.Copy(io.Discard, io.MultiReader(dec.Buffered(), conn))
+		cancel()
+	}()
+
+	enc := &encoder{enc: json.NewEncoder(conn)}
+	opts = append(opts[:len(opts):len(opts)], subcmd.WithStdio(
+		strings.NewReader(""),
+		streamWriter{enc: enc, stderr: false},
+		streamWriter{enc: enc, stderr: true},
+	))
+
+	resp := Response{Done: true}
+	if err := subcmd.Run(ctx, c, req.Args, opts...); err != nil {
+		resp.Error = err.Error()
+		resp.ExitCode = subcmd.ExitStatus(err)
+		var u subcmd.UsageErr
+		if errors.As(err, &u) {
+			resp.Detail = u.Detail()
+		}
+	}
+	enc.encode(resp)
+}
+
+// Type encoder serializes writes of Responses to a connection.
+type encoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (e *encoder) encode(resp Response) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(resp)
+}
+
+// Type streamWriter is an io.Writer that sends its output to the client.
+type streamWriter struct {
+	enc    *encoder
+	stderr bool
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	var resp Response
+	if w.stderr {
+		resp.Stderr = p
+	} else {
+		resp.Stdout = p
+	}
+	if err := w.enc.encode(resp); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+func TestServe(t *testing.T) {
+	dir, err := os.MkdirTemp("", "subcmd-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sock := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- Serve(ctx, ln, testcmd{}) }()
+
+	t.Run("ok", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if err := Call(ctx, "unix", sock, []string{"greet", "-n", "2", "world"}, &stdout, &stderr); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := stdout.String(), "hello world\nhello world\n"; got != want {
+			t.Errorf(`got stdout "%s", want "%s"`, got, want)
+		}
+		if got, want := stderr.String(), "done\n"; got != want {
+			t.Errorf(`got stderr "%s", want "%s"`, got, want)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		err := Call(ctx, "unix", sock, []string{"bogus"}, nil, nil)
+		var r *RemoteErr
+		if !errors.As(err, &r) {
+			t.Fatalf("got %v, want *RemoteErr", err)
+		}
+		if !strings.HasPrefix(r.Detail(), `Unknown subcommand "bogus"`) {
+			t.Errorf("unexpected detail %s", r.Detail())
+		}
+		if r.Status != 2 {
+			t.Errorf("got status %d, want 2", r.Status)
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if err := Call(ctx, "unix", sock, []string{"binary"}, &stdout, nil); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(stdout.Bytes(), binaryOutput) {
+			t.Errorf("got stdout %x, want %x", stdout.Bytes(), binaryOutput)
+		}
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		err := Call(ctx, "unix", sock, []string{"fail"}, nil, nil)
+		var r *RemoteErr
+		if !errors.As(err, &r) {
+			t.Fatalf("got %v, want *RemoteErr", err)
+		}
+		if r.Status != 3 {
+			t.Errorf("got status %d, want 3", r.Status)
+		}
+	})
+
+	cancel()
+	if err := <-serveErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v from Serve, want context.Canceled", err)
+	}
+}
+
+type testcmd struct{}
+
+func (testcmd) Subcmds() subcmd.Map {
+	return subcmd.Commands(
+		"greet", greet, "greet someone", subcmd.Params(
+			"-n", subcmd.Int, 1, "number of greetings",
+			"name", subcmd.String, "", "whom to greet",
+		),
+		"binary", binary, "write non-UTF-8 output", nil,
+		"fail", fail, "fail with exit status 3", nil,
+	)
+}
+
+var binaryOutput = []byte{0xff, 0xfe, 0x00, 'x'}
+
+func binary(ctx context.Context, _ []string) error {
+	_, err := subcmd.Stdout(ctx).Write(binaryOutput)
+	return err
+}
+
+func fail(context.Context, []string) error {
+	return exitErr(3)
+}
+
+type exitErr int
+
+func (e exitErr) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitErr) ExitCode() int { return int(e) }
+
+func greet(ctx context.Context, n int, name string, _ []string) error {
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(subcmd.Stdout(ctx), "hello %s\n", name)
+	}
+	fmt.Fprintln(subcmd.Stderr(ctx), "done")
+	return nil
+}
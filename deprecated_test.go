@@ -0,0 +1,54 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	var called bool
+	c := testcmdfunc(func() Map {
+		return Map{
+			"old": Subcmd{
+				F:          func(context.Context, []string) { called = true },
+				Desc:       "the old way",
+				Deprecated: `use "new" instead`,
+			},
+			"new": Subcmd{
+				F:    func(context.Context, []string) {},
+				Desc: "the new way",
+			},
+		}
+	})
+
+	var stderr bytes.Buffer
+	if err := Run(context.Background(), c, []string{"old"}, WithStdio(nil, nil, &stderr), WithProgName("prog")); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("deprecated subcommand was not called")
+	}
+	if want := "prog: warning: subcommand \"old\" is deprecated: use \"new\" instead\n"; stderr.String() != want {
+		t.Errorf(`got stderr "%s", want "%s"`, stderr.String(), want)
+	}
+
+	err := Run(context.Background(), c, []string{"help"})
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	if detail := h.Detail(); !strings.Contains(detail, "the old way (deprecated)") || strings.Contains(detail, "the new way (deprecated)") {
+		t.Errorf("help output does not flag the deprecated subcommand:\n%s", detail)
+	}
+
+	err = Run(context.Background(), c, []string{"help", "old"})
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	if detail := h.Detail(); !strings.Contains(detail, `Deprecated: use "new" instead`) {
+		t.Errorf("help output does not include the deprecation message:\n%s", detail)
+	}
+}
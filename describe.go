@@ -0,0 +1,74 @@
+package subcmd
+
+import "fmt"
+
+// Description is a structured description of a [Cmd]
+// and all its subcommands,
+// produced by [Describe].
+// It can be marshaled as JSON
+// for use by external tools such as completion engines, GUI wrappers, and documentation generators.
+type Description struct {
+	// Subcmds describes the subcommands of the Cmd, in name order.
+	Subcmds []SubcmdDescription `json:"subcmds,omitempty"`
+}
+
+// SubcmdDescription describes one [Subcmd] in a [Description].
+type SubcmdDescription struct {
+	// Name is the subcommand's name.
+	Name string `json:"name"`
+
+	// Desc is the subcommand's description.
+	Desc string `json:"desc,omitempty"`
+
+	// Deprecated is the subcommand's deprecation message, if any.
+	Deprecated string `json:"deprecated,omitempty"`
+
+	// Params describes the subcommand's parameters, in order.
+	Params []HelpParam `json:"params,omitempty"`
+
+	// Examples are the subcommand's example command lines.
+	Examples []string `json:"examples,omitempty"`
+
+	// Subcmds describes the subcommand's nested subcommands (see Subcmd.Sub), in name order.
+	Subcmds []SubcmdDescription `json:"subcmds,omitempty"`
+}
+
+// Describe produces a [Description] of c:
+// its subcommands,
+// their parameters (with types, defaults, and docs),
+// and their nested subcommands at every depth.
+// It is an error if any subcommand's parameters are malformed
+// (see [ToFlagSet]).
+func Describe(c Cmd) (*Description, error) {
+	subcmds, err := describeSubcmds(c.Subcmds())
+	if err != nil {
+		return nil, err
+	}
+	return &Description{Subcmds: subcmds}, nil
+}
+
+func describeSubcmds(subcmds Map) ([]SubcmdDescription, error) {
+	var result []SubcmdDescription
+	for _, name := range subcmdNames(subcmds) {
+		subcmd := subcmds[name]
+		if _, _, _, err := ToFlagSet(subcmd.Params); err != nil {
+			return nil, fmt.Errorf("describing %s: %w", name, err)
+		}
+		d := SubcmdDescription{
+			Name:       name,
+			Desc:       subcmd.Desc,
+			Deprecated: subcmd.Deprecated,
+			Params:     helpParams(subcmd.Params),
+			Examples:   subcmd.Examples,
+		}
+		if subcmd.Sub != nil {
+			sub, err := describeSubcmds(subcmd.Sub.Subcmds())
+			if err != nil {
+				return nil, fmt.Errorf("describing %s: %w", name, err)
+			}
+			d.Subcmds = sub
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
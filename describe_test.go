@@ -0,0 +1,71 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDescribe(t *testing.T) {
+	d, err := Describe(nestedtestcmd{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range d.Subcmds {
+		names = append(names, s.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "bb", "ccc", "db"}, names); diff != "" {
+		t.Errorf("subcommand names mismatch (-want +got):\n%s", diff)
+	}
+
+	want := SubcmdDescription{
+		Name: "db",
+		Desc: "database commands",
+		Subcmds: []SubcmdDescription{{
+			Name: "migrate",
+			Desc: "run migrations",
+			Params: []HelpParam{{
+				Name:    "n",
+				Type:    "int",
+				Flag:    true,
+				Default: 1,
+				Doc:     "number of steps",
+			}, {
+				Name:    "dir",
+				Type:    "string",
+				Default: "",
+				Doc:     "migrations directory",
+			}},
+		}, {
+			Name: "status",
+			Desc: "show migration status",
+		}},
+	}
+	if diff := cmp.Diff(want, d.Subcmds[3]); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	j, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Description
+	if err := json.Unmarshal(j, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Subcmds[3].Subcmds[0].Params[1].Name != "dir" {
+		t.Errorf("JSON round trip lost parameters: %s", j)
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		c := testcmdfunc(func() Map {
+			return Commands("x", func() {}, "", Params("-v", Value, nil, ""))
+		})
+		if _, err := Describe(c); err == nil {
+			t.Error("got no error, want one for a Value param with no default")
+		}
+	})
+}
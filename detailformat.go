@@ -0,0 +1,56 @@
+package subcmd
+
+import "context"
+
+// DetailFormatter is an interface for customizing the multiline Detail output
+// of the usage errors returned by [Run]
+// for a missing or unknown subcommand, or a request for help.
+// See [WithDetailFormatter].
+type DetailFormatter interface {
+	// FormatDetail returns the Detail text to use for err,
+	// or "" to use the default.
+	// It is called with errors of type *[MissingSubcmdErr], *[UnknownSubcmdErr], and *[HelpRequestedErr]
+	// (but not for help requested with "help -json").
+	// The data describes the subcommands and parameters involved,
+	// as for a help template (see [WithHelpTemplate]),
+	// and its Text field holds the default rendering.
+	FormatDetail(err UsageErr, data HelpData) string
+}
+
+// WithDetailFormatter is an [Option] that uses f to produce the Detail output
+// for missing and unknown subcommands and for help requests.
+// This allows programs to change the layout of that output,
+// add epilogues,
+// or suppress sections,
+// working from structured data rather than parsing the built-in output.
+//
+// A [Cmd] may supply its own DetailFormatter,
+// for errors arising at its level,
+// by implementing that interface itself.
+// See also [WithFormatter] for customizing the one-line Error messages.
+func WithDetailFormatter(f DetailFormatter) Option {
+	return func(c *config) error {
+		c.detailFormatter = f
+		return nil
+	}
+}
+
+// detailFormatter returns the DetailFormatter to use for errors arising at c's level, if any.
+func detailFormatter(ctx context.Context, c Cmd) DetailFormatter {
+	if df, ok := cmdAs[DetailFormatter](c); ok {
+		return df
+	}
+	return getConfig(ctx).detailFormatter
+}
+
+// formatDetail applies df, if it is not nil, to err.
+// The default Detail text for err is data.Text.
+func formatDetail(df DetailFormatter, err UsageErr, data HelpData) string {
+	if df == nil {
+		return data.Text
+	}
+	if msg := df.FormatDetail(err, data); msg != "" {
+		return msg
+	}
+	return data.Text
+}
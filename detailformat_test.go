@@ -0,0 +1,87 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testDetailFormatter struct{}
+
+func (testDetailFormatter) FormatDetail(err UsageErr, data HelpData) string {
+	var names []string
+	for _, s := range data.Subcmds {
+		names = append(names, s.Name)
+	}
+	switch err := err.(type) {
+	case *MissingSubcmdErr:
+		return fmt.Sprintf("%s: pick one of %s\n", data.Prog, strings.Join(names, ", "))
+	case *UnknownSubcmdErr:
+		return fmt.Sprintf("%s: no %s; pick one of %s\n", data.Prog, err.Name(), strings.Join(names, ", "))
+	case *HelpRequestedErr:
+		if data.Name == "" {
+			return "" // use the default
+		}
+		return data.Text + "See the manual for more.\n"
+	}
+	return ""
+}
+
+type detailformattedcmd struct {
+	errtestcmd
+	testDetailFormatter
+}
+
+func TestDetailFormatter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cmd  Cmd
+		opts []Option
+	}{
+		{name: "option", cmd: errtestcmd{}, opts: []Option{WithDetailFormatter(testDetailFormatter{})}},
+		{name: "cmd", cmd: detailformattedcmd{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			opts := append([]Option{WithProgName("prog")}, tc.opts...)
+
+			var uerr UsageErr
+
+			err := Run(ctx, tc.cmd, nil, opts...)
+			if !errors.As(err, &uerr) {
+				t.Fatalf("got %v, want UsageErr", err)
+			}
+			if diff := cmp.Diff("prog: pick one of a, bb, ccc\n", uerr.Detail()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+
+			err = Run(ctx, tc.cmd, []string{"xyz"}, opts...)
+			if !errors.As(err, &uerr) {
+				t.Fatalf("got %v, want UsageErr", err)
+			}
+			if diff := cmp.Diff("prog: no xyz; pick one of a, bb, ccc\n", uerr.Detail()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+
+			err = Run(ctx, tc.cmd, []string{"help"}, opts...)
+			if !errors.As(err, &uerr) {
+				t.Fatalf("got %v, want UsageErr", err)
+			}
+			if got := uerr.Detail(); !strings.HasPrefix(got, "Subcommands are:\n") {
+				t.Errorf("got %q, want default help", got)
+			}
+
+			err = Run(ctx, tc.cmd, []string{"help", "bb"}, opts...)
+			if !errors.As(err, &uerr) {
+				t.Fatalf("got %v, want UsageErr", err)
+			}
+			if got := uerr.Detail(); !strings.HasPrefix(got, "bb: Do b\n") || !strings.HasSuffix(got, "See the manual for more.\n") {
+				t.Errorf("got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package subcmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Docopt produces a usage specification for c in the format described at http://docopt.org/.
+// The prog argument is the name of the program to use in usage lines.
+//
+// There is one usage line for each subcommand of c.
+// Nested subcommands (see Subcmd.Sub) are included,
+// with one usage line per leaf command.
+// Flags are written in docopt's long-option form ("--verbose", "--count=<int>")
+// unless their names are a single letter ("-v", "-n <int>").
+// Flags are described in a single "Options:" section following the usage lines;
+// when more than one subcommand has a flag with the same name,
+// only the first description is included.
+func Docopt(prog string, c Cmd) (string, error) {
+	var (
+		usage   = new(strings.Builder)
+		options = new(strings.Builder)
+		seen    = make(map[string]bool)
+		maxlen  int
+		optrows [][2]string
+	)
+
+	fmt.Fprintln(usage, "Usage:")
+
+	err := walk(c, nil, func(path []string, subcmd Subcmd) error {
+		if subcmd.F == nil && subcmd.Sub != nil {
+			return nil
+		}
+
+		fs, _, positional, err := ToFlagSet(subcmd.Params)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(usage, "  %s %s", prog, strings.Join(path, " "))
+
+		required := requiredFlags(subcmd.Params)
+		fs.VisitAll(func(f *flag.Flag) {
+			opt := docoptFlag(f)
+			if required[f.Name] {
+				fmt.Fprintf(usage, " %s", opt)
+			} else {
+				fmt.Fprintf(usage, " [%s]", opt)
+			}
+
+			if seen[f.Name] {
+				return
+			}
+			seen[f.Name] = true
+
+			_, doc := unquoteUsage(f)
+			if !docoptZero(f.DefValue) {
+				doc += fmt.Sprintf(" [default: %s]", f.DefValue)
+			}
+			if len(opt) > maxlen {
+				maxlen = len(opt)
+			}
+			optrows = append(optrows, [2]string{opt, doc})
+		})
+
+		for _, p := range positional {
+			if strings.HasSuffix(p.Name, "?") {
+				fmt.Fprintf(usage, " [<%s>]", p.Name[:len(p.Name)-1])
+			} else {
+				fmt.Fprintf(usage, " <%s>", p.Name)
+			}
+		}
+		fmt.Fprintln(usage)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(optrows) == 0 {
+		return usage.String(), nil
+	}
+
+	fmt.Fprintln(options, "\nOptions:")
+	format := fmt.Sprintf("  %%-%ds  %%s\n", maxlen)
+	for _, row := range optrows {
+		fmt.Fprintf(options, format, row[0], row[1])
+	}
+
+	return usage.String() + options.String(), nil
+}
+
+// docoptFlag renders f as a docopt option:
+// -x <arg> for a one-letter name,
+// --name=<arg> otherwise.
+func docoptFlag(f *flag.Flag) string {
+	name, _ := unquoteUsage(f)
+	if len(f.Name) == 1 {
+		if name != "" {
+			return "-" + f.Name + " <" + name + ">"
+		}
+		return "-" + f.Name
+	}
+	if name != "" {
+		return "--" + f.Name + "=<" + name + ">"
+	}
+	return "--" + f.Name
+}
+
+func docoptZero(s string) bool {
+	switch s {
+	case "", "false", "0", "0s":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocopt(t *testing.T) {
+	got, err := Docopt("prog", nestedtestcmd{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Usage:
+  prog a [--a1] [--a2=<int>] [--a3=<word>] <a4> [<a5>]
+  prog bb
+  prog ccc
+  prog db migrate [-n <int>] <dir>
+  prog db status
+
+Options:
+  --a1         the a1 flag
+  --a2=<int>   the a2 flag
+  --a3=<word>  a word flag
+  -n <int>     number of steps [default: 1]
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunNested(t *testing.T) {
+	var gotDir string
+	c := nestedtestcmd{migrate: func(_ context.Context, _ int, dir string, _ []string) { gotDir = dir }}
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "3", "here"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != "here" {
+		t.Errorf(`got dir "%s", want "here"`, gotDir)
+	}
+}
+
+// Type nestedtestcmd adds a nested "db" command to the subcommands of errtestcmd.
+type nestedtestcmd struct {
+	migrate func(context.Context, int, string, []string)
+}
+
+func (c nestedtestcmd) Subcmds() Map {
+	m := errtestcmd{}.Subcmds()
+	m["db"] = Subcmd{
+		Desc: "database commands",
+		Sub:  nesteddbcmd{migrate: c.migrate},
+	}
+	return m
+}
+
+type nesteddbcmd struct {
+	migrate func(context.Context, int, string, []string)
+}
+
+func (c nesteddbcmd) Subcmds() Map {
+	migrate := c.migrate
+	if migrate == nil {
+		migrate = func(context.Context, int, string, []string) {}
+	}
+	return Commands(
+		"migrate", migrate, "run migrations", Params(
+			"-n", Int, 1, "number of steps",
+			"dir", String, "", "migrations directory",
+		),
+		"status", errtestB, "show migration status", nil,
+	)
+}
@@ -0,0 +1,109 @@
+package subcmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WithDotenv is an [Option] that causes [Run] to load environment variables from the named files
+// (or from ".env" in the current directory if no names are given)
+// before doing anything else.
+//
+// Each file contains lines of the form KEY=VALUE,
+// optionally preceded by "export ".
+// Blank lines and lines beginning with "#" are ignored.
+// A VALUE may be enclosed in single or double quotes;
+// in a double-quoted VALUE,
+// the usual Go backslash escapes are recognized.
+//
+// Variables that are already set in the environment are not overridden.
+// When more than one file is given,
+// settings in earlier files take precedence over those in later ones.
+// A file that does not exist is silently skipped.
+func WithDotenv(paths ...string) Option {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	return func(c *config) error {
+		for _, path := range paths {
+			if err := loadDotenv(path); err != nil {
+				return fmt.Errorf("loading %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+}
+
+func loadDotenv(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	vars, err := parseDotenv(f)
+	if err != nil {
+		return err
+	}
+	for _, kv := range vars {
+		if _, ok := os.LookupEnv(kv[0]); ok {
+			continue
+		}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("setting %s: %w", kv[0], err)
+		}
+	}
+	return nil
+}
+
+// parseDotenv parses the contents of a .env file into a list of key-value pairs.
+func parseDotenv(r io.Reader) ([][2]string, error) {
+	var (
+		result [][2]string
+		sc     = bufio.NewScanner(r)
+		lineno int
+	)
+	for sc.Scan() {
+		lineno++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		idx := strings.Index(line, "=")
+		if idx < 1 {
+			return nil, fmt.Errorf("line %d: missing =", lineno)
+		}
+		key := strings.TrimSpace(line[:idx])
+		val := strings.TrimSpace(line[idx+1:])
+
+		switch {
+		case len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"':
+			unquoted, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineno, err)
+			}
+			val = unquoted
+
+		case len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'':
+			val = val[1 : len(val)-1]
+
+		default:
+			// Strip a trailing comment from an unquoted value.
+			if idx := strings.Index(val, " #"); idx >= 0 {
+				val = strings.TrimSpace(val[:idx])
+			}
+		}
+
+		result = append(result, [2]string{key, val})
+	}
+	return result, sc.Err()
+}
@@ -0,0 +1,75 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDotenv(t *testing.T) {
+	const input = `
+# A comment.
+FOO=bar
+export BAZ = "a\tb"
+QUUX='single # quoted'
+XYZZY=plugh # trailing comment
+`
+	got, err := parseDotenv(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{
+		{"FOO", "bar"},
+		{"BAZ", "a\tb"},
+		{"QUUX", "single # quoted"},
+		{"XYZZY", "plugh"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := parseDotenv(strings.NewReader("NOEQUALS\n")); err == nil {
+		t.Error("got no error for malformed line")
+	}
+}
+
+func TestWithDotenv(t *testing.T) {
+	dir, err := os.MkdirTemp("", "subcmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("SUBCMD_TEST_A=from-file\nSUBCMD_TEST_B=from-file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreA := testSetenv("SUBCMD_TEST_A", "from-env")
+	defer restoreA()
+	defer os.Unsetenv("SUBCMD_TEST_B")
+
+	var gotA, gotB string
+	c := dotenvtestcmd(func(context.Context, []string) {
+		gotA, gotB = os.Getenv("SUBCMD_TEST_A"), os.Getenv("SUBCMD_TEST_B")
+	})
+	if err := Run(context.Background(), c, []string{"x"}, WithDotenv(path, filepath.Join(dir, "nonexistent"))); err != nil {
+		t.Fatal(err)
+	}
+	if gotA != "from-env" {
+		t.Errorf(`got SUBCMD_TEST_A="%s", want "from-env"`, gotA)
+	}
+	if gotB != "from-file" {
+		t.Errorf(`got SUBCMD_TEST_B="%s", want "from-file"`, gotB)
+	}
+}
+
+type dotenvtestcmd func(context.Context, []string)
+
+func (c dotenvtestcmd) Subcmds() Map {
+	return Commands("x", (func(context.Context, []string))(c), "", nil)
+}
@@ -0,0 +1,55 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Enricher is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// [Run] calls Enrich to derive the context for each subcommand function
+// before calling it,
+// e.g. to add a request ID, tenant information, or a logger.
+//
+// This applies at every level of nesting:
+// the Enrich methods of all the Cmds on the way to a subcommand
+// (see Subcmd.Sub)
+// are called in turn,
+// outermost first,
+// each receiving the context produced by the previous one.
+// The context passed to Enrich already includes the [CommandPath],
+// so Enrich can tailor its result to the subcommand being run.
+//
+// If Enrich returns an error,
+// Run returns that error without calling the subcommand function.
+type Enricher interface {
+	Enrich(context.Context) (context.Context, error)
+}
+
+func enrichers(ctx context.Context) []Enricher {
+	e, _ := ctx.Value(enrichersKey).([]Enricher)
+	return e
+}
+
+// addEnricher adds c to the Enrichers in ctx,
+// if it is one.
+func addEnricher(ctx context.Context, c Cmd) context.Context {
+	e, ok := cmdAs[Enricher](c)
+	if !ok {
+		return ctx
+	}
+	list := enrichers(ctx)
+	list = append(list[:len(list):len(list)], e)
+	return context.WithValue(ctx, enrichersKey, list)
+}
+
+// enrich applies the Enrichers in ctx to it.
+func enrich(ctx context.Context) (context.Context, error) {
+	for _, e := range enrichers(ctx) {
+		var err error
+		if ctx, err = e.Enrich(ctx); err != nil {
+			return nil, fmt.Errorf("enriching context: %w", err)
+		}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,60 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type enrichtestkey struct{}
+
+func TestEnricher(t *testing.T) {
+	var got string
+	c := enrichtestcmd{
+		tag: "outer",
+		sub: enrichtestcmd{
+			tag: "inner",
+			f: func(ctx context.Context, _ []string) {
+				got, _ = ctx.Value(enrichtestkey{}).(string)
+			},
+		},
+	}
+
+	if err := Run(context.Background(), c, []string{"sub", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "outer:sub/x,inner:sub/x"; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+
+	c.err = errors.New("boom")
+	if err := Run(context.Background(), c, []string{"sub", "x"}); !errors.Is(err, c.err) {
+		t.Errorf("got %v, want %v", err, c.err)
+	}
+}
+
+type enrichtestcmd struct {
+	tag string
+	err error
+	sub Cmd
+	f   func(context.Context, []string)
+}
+
+func (c enrichtestcmd) Subcmds() Map {
+	if c.sub != nil {
+		return Map{"sub": Subcmd{Sub: c.sub}}
+	}
+	return Commands("x", c.f, "", nil)
+}
+
+func (c enrichtestcmd) Enrich(ctx context.Context) (context.Context, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	val := c.tag + ":" + strings.Join(CommandPath(ctx), "/")
+	if prev, ok := ctx.Value(enrichtestkey{}).(string); ok {
+		val = prev + "," + val
+	}
+	return context.WithValue(ctx, enrichtestkey{}, val), nil
+}
@@ -0,0 +1,147 @@
+package subcmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvEncoder is an optional additional interface that a [Prefixer] can implement
+// to choose how it is encoded in the environment variable passed to plugin subprocesses
+// (see [EnvVar]).
+// EnvEncoding should return one of the following:
+//
+//   - "json" (the default): the Cmd is JSON-marshaled;
+//   - "base64": the Cmd is JSON-marshaled and then base64-encoded, with the prefix "base64:";
+//   - "gob": the Cmd is encoded with [encoding/gob] and then base64-encoded, with the prefix "gob:".
+//
+// The gob encoding is useful for Cmd types that cannot be represented cleanly in JSON.
+// [ParseEnv] recognizes all three encodings.
+//
+// Encodings other than "json" require version 2 of the plugin protocol
+// (see [ProtocolVersion]),
+// so plugins built with older versions of this package fail with a [ProtocolErr]
+// instead of misreading them.
+type EnvEncoder interface {
+	EnvEncoding() string
+}
+
+// EnvMarshaler is an optional additional interface that a [Prefixer] can implement
+// to control what is passed to plugin subprocesses in the environment variable [EnvVar].
+// If it does,
+// MarshalEnv is used in place of [json.Marshal] to encode the Cmd.
+// This lets a Cmd omit fields that plugins should not see, such as credentials,
+// or that cannot be marshaled, such as database handles.
+// The result must be JSON that [ParseEnv] can decode.
+//
+// MarshalEnv is also used with the "base64" encoding (see [EnvEncoder]),
+// but not with the "gob" encoding.
+type EnvMarshaler interface {
+	MarshalEnv() ([]byte, error)
+}
+
+// EnvErr is the error returned by [ParseEnv] and [ParseEnvStrict]
+// when the plugin environment variable (see [EnvVar]) is present but cannot be decoded.
+type EnvErr struct {
+	// Var is the name of the environment variable.
+	Var string
+
+	// Err is the underlying decoding error.
+	Err error
+}
+
+func (e EnvErr) Error() string {
+	return fmt.Sprintf("parsing %s: %s", e.Var, e.Err)
+}
+
+// Unwrap unwraps the nested error in e.
+func (e EnvErr) Unwrap() error {
+	return e.Err
+}
+
+// ParseEnvStrict is like [ParseEnv]
+// but fails if the JSON-encoded value contains fields that the value pointed to by ptr does not have.
+// This catches version skew between a parent and its plugins.
+// It has no such effect on the gob encoding (see [EnvEncoder]),
+// which always ignores unknown fields.
+func ParseEnvStrict(ptr interface{}) error {
+	return parseEnv(ptr, true)
+}
+
+func parseEnv(ptr interface{}, strict bool) error {
+	name := cmdEnvVar(ptr)
+	val := os.Getenv(name)
+	if val == "" {
+		return nil
+	}
+	if err := checkProtocol(); err != nil {
+		return err
+	}
+	if err := decodeEnv(val, ptr, strict); err != nil {
+		return EnvErr{Var: name, Err: err}
+	}
+	return nil
+}
+
+// encodeEnv encodes c for passing to a plugin subprocess.
+// It also returns the plugin protocol version that the encoding requires.
+func encodeEnv(c interface{}) (string, int, error) {
+	encoding := "json"
+	if e, ok := cmdAs[EnvEncoder](c); ok {
+		encoding = e.EnvEncoding()
+	}
+
+	switch encoding {
+	case "", "json":
+		j, err := marshalEnvJSON(c)
+		return string(j), 1, err
+
+	case "base64":
+		j, err := marshalEnvJSON(c)
+		return "base64:" + base64.StdEncoding.EncodeToString(j), 2, err
+
+	case "gob":
+		buf := new(bytes.Buffer)
+		err := gob.NewEncoder(buf).Encode(unwrapCmd(c)) // gob can't see through Memoize as JSON can
+		return "gob:" + base64.StdEncoding.EncodeToString(buf.Bytes()), 2, err
+
+	default:
+		return "", 0, fmt.Errorf("unknown encoding %s", encoding)
+	}
+}
+
+func marshalEnvJSON(c interface{}) ([]byte, error) {
+	if m, ok := cmdAs[EnvMarshaler](c); ok {
+		return m.MarshalEnv()
+	}
+	return json.Marshal(c)
+}
+
+// decodeEnv decodes val, produced by encodeEnv, into ptr.
+func decodeEnv(val string, ptr interface{}, strict bool) error {
+	if rest, ok := strings.CutPrefix(val, "gob:"); ok {
+		data, err := base64.StdEncoding.DecodeString(rest)
+		if err != nil {
+			return err
+		}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(ptr)
+	}
+
+	data := []byte(val)
+	if rest, ok := strings.CutPrefix(val, "base64:"); ok {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(rest); err != nil {
+			return err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(ptr)
+}
@@ -0,0 +1,128 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEnvEncoding(t *testing.T) {
+	for _, encoding := range []string{"json", "base64", "gob"} {
+		for _, memoize := range []bool{false, true} {
+			name := encoding
+			if memoize {
+				name += "_memoized"
+			}
+			t.Run(name, func(t *testing.T) {
+				var c Cmd = testEnvEncodingCmd{Data: "xyz", encoding: encoding}
+				if memoize {
+					c = Memoize(c)
+				}
+				val, version, err := encodeEnv(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantVersion := 2
+				if encoding == "json" {
+					wantVersion = 1
+				}
+				if version != wantVersion {
+					t.Errorf("got protocol version %d, want %d", version, wantVersion)
+				}
+
+				defer testSetenv(EnvVar, val)()
+				defer testSetenv(ProtocolEnvVar, "2")()
+
+				var got testEnvEncodingCmd
+				if err := ParseEnv(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Data != "xyz" {
+					t.Errorf(`got data "%s", want "xyz"`, got.Data)
+				}
+			})
+		}
+	}
+}
+
+func TestParseEnvErr(t *testing.T) {
+	cases := []struct {
+		name, val string
+		strict    bool
+	}{{
+		name: "json",
+		val:  `{"data":`,
+	}, {
+		name: "base64",
+		val:  "base64:!!!",
+	}, {
+		name: "gob",
+		val:  "gob:AAAA",
+	}, {
+		name:   "strict",
+		val:    `{"data": "xyz", "extra": 1}`,
+		strict: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer testSetenv(EnvVar, tc.val)()
+
+			var (
+				got testPrefixMainCmd
+				err error
+			)
+			if tc.strict {
+				err = ParseEnvStrict(&got)
+			} else {
+				err = ParseEnv(&got)
+			}
+			var e EnvErr
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v, want EnvErr", err)
+			}
+			if e.Var != EnvVar {
+				t.Errorf("got var %s, want %s", e.Var, EnvVar)
+			}
+		})
+	}
+
+	// Without strictness, unknown fields are ignored.
+	defer testSetenv(EnvVar, `{"data": "xyz", "extra": 1}`)()
+	var got testPrefixMainCmd
+	if err := ParseEnv(&got); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testEnvEncodingCmd struct {
+	Data     string `json:"data"`
+	encoding string
+}
+
+func (testEnvEncodingCmd) Subcmds() Map          { return nil }
+func (testEnvEncodingCmd) Prefix() string        { return "foo-" }
+func (c testEnvEncodingCmd) EnvEncoding() string { return c.encoding }
+
+func TestEnvMarshaler(t *testing.T) {
+	c := testEnvMarshalerCmd{Data: "xyz", Secret: "hunter2"}
+	val, _, err := encodeEnv(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != `{"data":"xyz"}` {
+		t.Errorf("got %s, want only the data field", val)
+	}
+}
+
+type testEnvMarshalerCmd struct {
+	Data   string `json:"data"`
+	Secret string `json:"secret"`
+}
+
+func (testEnvMarshalerCmd) Subcmds() Map   { return nil }
+func (testEnvMarshalerCmd) Prefix() string { return "foo-" }
+
+func (c testEnvMarshalerCmd) MarshalEnv() ([]byte, error) {
+	return json.Marshal(testPrefixMainCmd{Data: c.Data})
+}
@@ -0,0 +1,27 @@
+package subcmd
+
+// EnvVarNamer is an optional additional interface that a [Prefixer] can implement.
+// If it does,
+// EnvVarName is the name of the environment variable
+// used to pass the JSON-marshaled Cmd to plugin subprocesses,
+// instead of [EnvVar].
+// This lets several subcmd-based tools in one process tree
+// keep their plugin state separate.
+//
+// [ParseEnv] and [ParsePluginEnv] read the same variable
+// when the value they decode into implements EnvVarNamer,
+// as it does when a plugin decodes into the same type as its parent.
+type EnvVarNamer interface {
+	EnvVarName() string
+}
+
+// cmdEnvVar returns the name of the environment variable
+// for passing x (a Cmd, or a pointer to one) to a plugin subprocess.
+func cmdEnvVar(x interface{}) string {
+	if n, ok := cmdAs[EnvVarNamer](x); ok {
+		if name := n.EnvVarName(); name != "" {
+			return name
+		}
+	}
+	return EnvVar
+}
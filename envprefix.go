@@ -0,0 +1,83 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WithEnvPrefix is an [Option] that lets every flag of every subcommand
+// take its default value from an environment variable.
+// The variable's name is prefix,
+// the [CommandPath] of the subcommand,
+// and the flag name,
+// joined with underscores,
+// upper-cased,
+// and with any hyphens changed to underscores.
+// For example,
+// with WithEnvPrefix("MYAPP"),
+// the -reverse flag of "myapp list" may be set with MYAPP_LIST_REVERSE,
+// and the -dry-run flag of "myapp db migrate" may be set with MYAPP_DB_MIGRATE_DRY_RUN.
+//
+// Flags given on the command line override the values in the environment.
+// See also Param.Env.
+func WithEnvPrefix(prefix string) Option {
+	return func(c *config) error {
+		c.envPrefix = prefix
+		return nil
+	}
+}
+
+// applyEnv sets the flags in fs from the environment,
+// using the variables named in Param.Env
+// and, if WithEnvPrefix is in effect,
+// the variables it implies.
+func applyEnv(ctx context.Context, fs *flag.FlagSet, params []Param) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		name, val, ok := lookupFlagEnv(ctx, params, f.Name)
+		if !ok {
+			return
+		}
+		if e := fs.Set(f.Name, val); e != nil {
+			err = fmt.Errorf("setting -%s from $%s: %w", f.Name, name, e)
+		}
+	})
+	return err
+}
+
+// lookupFlagEnv looks up the environment variable for the flag named flagName.
+// If the flag's Param has an Env field,
+// and that variable is set,
+// it takes precedence over the one implied by WithEnvPrefix.
+// The result is the name of the variable that was found and its value.
+func lookupFlagEnv(ctx context.Context, params []Param, flagName string) (string, string, bool) {
+	for _, p := range params {
+		if p.Env == "" || !strings.HasPrefix(p.Name, "-") || strings.TrimLeft(p.Name, "-") != flagName {
+			continue
+		}
+		if val, ok := os.LookupEnv(p.Env); ok {
+			return p.Env, val, true
+		}
+	}
+
+	prefix := getConfig(ctx).envPrefix
+	if prefix == "" {
+		return "", "", false
+	}
+	name := envVarName(prefix, CommandPath(ctx), flagName)
+	val, ok := os.LookupEnv(name)
+	return name, val, ok
+}
+
+func envVarName(prefix string, path []string, flagName string) string {
+	parts := append([]string{prefix}, path...)
+	parts = append(parts, flagName)
+	name := strings.ToUpper(strings.Join(parts, "_"))
+	return strings.ReplaceAll(name, "-", "_")
+}
@@ -0,0 +1,83 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	var gotN int
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+
+	defer testSetenv("MYAPP_DB_MIGRATE_N", "5")()
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 1 {
+		t.Errorf("without prefix, got n=%d, want 1", gotN)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithEnvPrefix("MYAPP")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 5 {
+		t.Errorf("with prefix, got n=%d, want 5", gotN)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "7", "dir"}, WithEnvPrefix("MYAPP")); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 7 {
+		t.Errorf("with command-line flag, got n=%d, want 7", gotN)
+	}
+
+	defer testSetenv("MYAPP_DB_MIGRATE_N", "x")()
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithEnvPrefix("MYAPP")); err == nil {
+		t.Error("got no error for bad environment value")
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if got := envVarName("MYAPP", []string{"db", "migrate"}, "dry-run"); got != "MYAPP_DB_MIGRATE_DRY_RUN" {
+		t.Errorf(`got "%s", want "MYAPP_DB_MIGRATE_DRY_RUN"`, got)
+	}
+}
+
+func TestParamEnv(t *testing.T) {
+	var gotAddr string
+	c := testcmdfunc(func() Map {
+		return Map{
+			"serve": Subcmd{
+				F: func(_ context.Context, addr string, _ []string) { gotAddr = addr },
+				Params: []Param{
+					{Name: "-addr", Type: String, Default: ":80", Env: "SERVE_ADDR"},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != ":80" {
+		t.Errorf(`without env, got addr "%s", want ":80"`, gotAddr)
+	}
+
+	defer testSetenv("SERVE_ADDR", ":8080")()
+	defer testSetenv("MYAPP_SERVE_ADDR", ":9090")()
+
+	if err := Run(context.Background(), c, []string{"serve"}, WithEnvPrefix("MYAPP")); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != ":8080" {
+		t.Errorf(`with env, got addr "%s", want ":8080"`, gotAddr)
+	}
+
+	if err := Run(context.Background(), c, []string{"serve", "-addr", ":1"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != ":1" {
+		t.Errorf(`with flag, got addr "%s", want ":1"`, gotAddr)
+	}
+}
@@ -3,8 +3,10 @@ package subcmd
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -129,6 +131,9 @@ Usage: %s a [-a1] [-a2 int] [-a3 word] a4 [a5]
 -a1       the a1 flag
 -a2 int   the a2 flag
 -a3 word  a word flag
+Positional parameters:
+a4 duration  positional duration
+a5 bool      optional positional bool (optional, default false)
 `, os.Args[0])
 
 			if diff := cmp.Diff(want, got); diff != "" {
@@ -138,6 +143,90 @@ Usage: %s a [-a1] [-a2 int] [-a3 word] a4 [a5]
 	})
 }
 
+func TestHelpFlag(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = nestedtestcmd{migrate: func(context.Context, int, string, []string) {}}
+	)
+
+	cases := []struct {
+		args, helpArgs []string
+	}{{
+		args:     []string{"db", "migrate", "-h"},
+		helpArgs: []string{"db", "help", "migrate"},
+	}, {
+		args:     []string{"db", "migrate", "-n", "3", "--help"},
+		helpArgs: []string{"db", "help", "migrate"},
+	}, {
+		args:     []string{"db", "-help"},
+		helpArgs: []string{"db", "help"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			stderr := new(strings.Builder)
+			err := Run(ctx, c, tc.args, WithStdio(nil, nil, stderr))
+
+			var herr *HelpRequestedErr
+			if !errors.As(err, &herr) {
+				t.Fatalf("got %v, want *HelpRequestedErr", err)
+			}
+			if !errors.Is(err, flag.ErrHelp) {
+				t.Error("error does not wrap flag.ErrHelp")
+			}
+			if stderr.Len() > 0 {
+				t.Errorf("unexpected output on stderr: %q", stderr.String())
+			}
+
+			var want *HelpRequestedErr
+			if !errors.As(Run(ctx, c, tc.helpArgs), &want) {
+				t.Fatal("help subcommand did not produce a HelpRequestedErr")
+			}
+			if errors.Is(want, flag.ErrHelp) {
+				t.Error("help subcommand error unexpectedly wraps flag.ErrHelp")
+			}
+			if diff := cmp.Diff(want.Detail(), herr.Detail()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeepHelp(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = nestedtestcmd{}
+	)
+
+	err := Run(ctx, c, []string{"help", "db", "migrate"}, WithProgName("prog"))
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if got, want := herr.Error(), "usage: prog db migrate [-n int] dir"; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+
+	var want *HelpRequestedErr
+	if !errors.As(Run(ctx, c, []string{"db", "help", "migrate"}, WithProgName("prog")), &want) {
+		t.Fatal("nested help did not produce a HelpRequestedErr")
+	}
+	if diff := cmp.Diff(want.Detail(), herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		err := Run(ctx, c, []string{"help", "db", "frobnicate"}, WithProgName("prog"))
+		var herr *HelpRequestedErr
+		if !errors.As(err, &herr) {
+			t.Fatalf("got %v, want *HelpRequestedErr", err)
+		}
+		if got := herr.Detail(); !strings.Contains(got, "migrate") || !strings.Contains(got, "frobnicate") {
+			t.Errorf("got %q, want it to mention frobnicate and migrate", got)
+		}
+	})
+}
+
 func TestTooFewArgs(t *testing.T) {
 	err := Run(context.Background(), errtestcmd{}, []string{"a"})
 	if !errors.Is(err, ErrTooFewArgs) {
@@ -149,7 +238,45 @@ func TestParseErr(t *testing.T) {
 	err := Run(context.Background(), errtestcmd{}, []string{"a", "x"})
 	var perr ParseErr
 	if !errors.As(err, &perr) {
-		t.Errorf("got %v, want *ParseErr", err)
+		t.Fatalf("got %v, want *ParseErr", err)
+	}
+	if perr.Param != "a4" || perr.Type != Duration || perr.Input != "x" {
+		t.Errorf("got param %s, type %s, input %s; want a4, time.Duration, x", perr.Param, perr.Type, perr.Input)
+	}
+	if got, want := perr.Error(), `argument 'a4': cannot parse "x" as time.Duration: time: invalid duration "x"`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	err = Run(context.Background(), errtestcmd{}, []string{"a", "1s", "x7"})
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *ParseErr", err)
+	}
+	if got, want := perr.Error(), `argument 'a5': cannot parse "x7" as bool`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRunWrapsFuncErr(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"plain", func(context.Context, []string) error { return sentinel }, "", nil,
+			"typed", func(context.Context, []string) error { return fmt.Errorf("context: %w", ParseErr{Err: sentinel}) }, "", nil,
+		)
+	})
+
+	err := Run(context.Background(), c, []string{"plain"})
+	if !errors.Is(err, sentinel) {
+		t.Errorf("got %v, want an error wrapping %v", err, sentinel)
+	}
+
+	err = Run(context.Background(), c, []string{"typed"})
+	var perr ParseErr
+	if !errors.As(err, &perr) {
+		t.Errorf("got %v, want an error wrapping ParseErr", err)
+	}
+	if !errors.Is(err, sentinel) {
+		t.Errorf("got %v, want an error wrapping %v", err, sentinel)
 	}
 }
 
@@ -0,0 +1,217 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// This file gives the error types produced by [Run] JSON encodings,
+// so that tools driving a program built with this package
+// (such as editor plugins or web frontends)
+// can consume its errors programmatically.
+// Each encoding is an object with a "kind" field identifying the type of error,
+// a "message" field holding the result of its Error method,
+// and other fields specific to the type.
+
+// MarshalError produces a JSON encoding of err,
+// which is typically an error returned by [Run].
+// If err is or wraps an error that implements [json.Marshaler]
+// (such as the usage and parsing errors of this package),
+// the result is that error's encoding.
+// If it wraps [ErrTooFewArgs],
+// the result is an object whose "kind" field is "too_few_args".
+// Otherwise it is an object of the form {"kind": "error", "message": MESSAGE}.
+func MarshalError(err error) ([]byte, error) {
+	var m json.Marshaler
+	if errors.As(err, &m) {
+		return m.MarshalJSON()
+	}
+	kind := "error"
+	if errors.Is(err, ErrTooFewArgs) {
+		kind = "too_few_args"
+	}
+	return json.Marshal(struct {
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}{
+		Kind:    kind,
+		Message: err.Error(),
+	})
+}
+
+func pairNames(pairs []subcmdPair) []string {
+	result := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		result = append(result, pair.name)
+	}
+	return result
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "missing_subcmd".
+func (e *MissingSubcmdErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Path    []string `json:"path"`
+		Names   []string `json:"names"`
+	}{
+		Kind:    "missing_subcmd",
+		Message: e.Error(),
+		Path:    pairNames(e.pairs),
+		Names:   e.Names(),
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "unknown_subcmd".
+func (e *UnknownSubcmdErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind        string   `json:"kind"`
+		Message     string   `json:"message"`
+		Path        []string `json:"path"`
+		Name        string   `json:"name"`
+		Names       []string `json:"names"`
+		Suggestions []string `json:"suggestions,omitempty"`
+	}{
+		Kind:        "unknown_subcmd",
+		Message:     e.Error(),
+		Path:        pairNames(e.pairs),
+		Name:        e.name,
+		Names:       e.Names(),
+		Suggestions: e.Suggestions(),
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "parse".
+func (e ParseErr) MarshalJSON() ([]byte, error) {
+	var typ string
+	if e.Type != 0 {
+		typ = e.Type.String()
+	}
+	return json.Marshal(struct {
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+		Param   string `json:"param,omitempty"`
+		Type    string `json:"type,omitempty"`
+		Input   string `json:"input,omitempty"`
+		Err     string `json:"error"`
+	}{
+		Kind:    "parse",
+		Message: e.Error(),
+		Param:   e.Param,
+		Type:    typ,
+		Input:   e.Input,
+		Err:     errString(e.Err),
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "flag".
+func (e FlagErr) MarshalJSON() ([]byte, error) {
+	var typ string
+	if e.Type != 0 {
+		typ = e.Type.String()
+	}
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Path    []string `json:"path"`
+		Flag    string   `json:"flag,omitempty"`
+		Type    string   `json:"type,omitempty"`
+		Err     string   `json:"error"`
+	}{
+		Kind:    "flag",
+		Message: e.Error(),
+		Path:    e.Path,
+		Flag:    e.Flag,
+		Type:    typ,
+		Err:     errString(e.Err),
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "extra_args".
+func (e ExtraArgsErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Args    []string `json:"args"`
+	}{
+		Kind:    "extra_args",
+		Message: e.Error(),
+		Args:    e.Args,
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "arg_count".
+func (e ArgCountErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Args    []string `json:"args"`
+		Min     int      `json:"min,omitempty"`
+		Max     int      `json:"max,omitempty"`
+	}{
+		Kind:    "arg_count",
+		Message: e.Error(),
+		Args:    e.Args,
+		Min:     e.Min,
+		Max:     e.Max,
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "missing_flag".
+func (e MissingFlagErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Flags   []string `json:"flags"`
+	}{
+		Kind:    "missing_flag",
+		Message: e.Error(),
+		Flags:   e.Flags,
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "flag_requires".
+func (e FlagRequiresErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string   `json:"kind"`
+		Message string   `json:"message"`
+		Flag    string   `json:"flag"`
+		Missing []string `json:"missing"`
+	}{
+		Kind:    "flag_requires",
+		Message: e.Error(),
+		Flag:    e.Flag,
+		Missing: e.Missing,
+	})
+}
+
+// MarshalJSON implements [json.Marshaler].
+// The "kind" field is "unsupported".
+func (e *UnsupportedErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+		Name    string `json:"name"`
+		Reason  string `json:"reason,omitempty"`
+	}{
+		Kind:    "unsupported",
+		Message: e.Error(),
+		Name:    e.Name,
+		Reason:  e.Reason,
+	})
+}
@@ -0,0 +1,83 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshalError(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want map[string]interface{}
+	}{{
+		name: "missing",
+		want: map[string]interface{}{
+			"kind":    "missing_subcmd",
+			"message": "missing subcommand, want one of: a; bb; ccc",
+			"path":    []interface{}{},
+			"names":   []interface{}{"a", "bb", "ccc"},
+		},
+	}, {
+		name: "unknown",
+		args: []string{"cc"},
+		want: map[string]interface{}{
+			"kind":        "unknown_subcmd",
+			"message":     `unknown subcommand "cc", did you mean "ccc"?`,
+			"path":        []interface{}{},
+			"name":        "cc",
+			"names":       []interface{}{"a", "bb", "ccc"},
+			"suggestions": []interface{}{"ccc"},
+		},
+	}, {
+		name: "flag",
+		args: []string{"a", "-a2", "x", "1s"},
+		want: map[string]interface{}{
+			"kind":    "flag",
+			"message": `parsing flags for a: invalid value "x" for flag -a2: parse error`,
+			"path":    []interface{}{"a"},
+			"flag":    "a2",
+			"type":    "int",
+			"error":   `invalid value "x" for flag -a2: parse error`,
+		},
+	}, {
+		name: "too_few",
+		args: []string{"a"},
+		want: map[string]interface{}{
+			"kind":    "too_few_args",
+			"message": "marshaling args: too few arguments",
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Run(context.Background(), errtestcmd{}, tc.args)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			j, err := MarshalError(err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(j, &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	j, err := MarshalError(errors.New("boom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(j), `{"kind":"error","message":"boom"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
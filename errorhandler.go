@@ -0,0 +1,22 @@
+package subcmd
+
+import "context"
+
+// ErrorHandler is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// [Run] passes any error it would otherwise return to HandleError,
+// and returns the result instead.
+// This includes usage errors such as [HelpRequestedErr] and [UnknownSubcmdErr],
+// errors from parsing arguments,
+// and errors returned by subcommand functions.
+// It allows an application to translate errors in one place,
+// e.g. adding hints for the user,
+// or turning domain-specific errors into usage errors.
+//
+// The context passed to HandleError includes the [CommandPath],
+// if the error happened after a subcommand was found.
+// If nested Cmds (see Subcmd.Sub) are also ErrorHandlers,
+// the innermost one handles an error first.
+type ErrorHandler interface {
+	HandleError(context.Context, error) error
+}
@@ -0,0 +1,51 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	var gotPath []string
+	c := errhandlertestcmd{path: &gotPath}
+
+	err := Run(context.Background(), c, []string{"x"})
+	if err == nil || err.Error() != "x failed (try again later)" {
+		t.Errorf(`got %v, want "x failed (try again later)"`, err)
+	}
+	if !reflect.DeepEqual(gotPath, []string{"x"}) {
+		t.Errorf("got path %v, want [x]", gotPath)
+	}
+
+	var u *UnknownSubcmdErr
+	if err := Run(context.Background(), c, []string{"y"}); !errors.As(err, &u) {
+		t.Errorf("got %v, want *UnknownSubcmdErr", err)
+	}
+
+	if err := Run(context.Background(), c, []string{"ok"}); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+type errhandlertestcmd struct {
+	path *[]string
+}
+
+func (errhandlertestcmd) Subcmds() Map {
+	return Commands(
+		"x", func(context.Context, []string) error { return errors.New("x failed") }, "", nil,
+		"ok", func(context.Context, []string) {}, "", nil,
+	)
+}
+
+func (c errhandlertestcmd) HandleError(ctx context.Context, err error) error {
+	*c.path = CommandPath(ctx)
+	var u *UnknownSubcmdErr
+	if errors.As(err, &u) {
+		return err
+	}
+	return fmt.Errorf("%w (try again later)", errors.Unwrap(err))
+}
@@ -1,24 +1,63 @@
 package subcmd
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
-	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"text/template"
 )
 
 // ErrTooFewArgs is the error when not enough arguments are supplied for required positional parameters.
 var ErrTooFewArgs = errors.New("too few arguments")
 
-// ParseErr is the type of error returned when parsing a positional parameter according to its type fails.
+// ParseErr is the type of error returned when parsing a positional parameter according to its type fails
+// (or when a path-valued flag does not name an existing file or directory).
 type ParseErr struct {
 	Err error
+
+	// Param is the name of the parameter whose value could not be parsed.
+	// It is empty when the error is not about a specific input string
+	// (for example, when the parameter itself is misconfigured).
+	Param string
+
+	// Type is the declared type of the parameter.
+	Type Type
+
+	// Input is the string that could not be parsed.
+	Input string
 }
 
 func (e ParseErr) Error() string {
-	return "parse error: " + e.Err.Error()
+	if e.Param == "" {
+		return "parse error: " + e.Err.Error()
+	}
+
+	kind := "argument"
+	if strings.HasPrefix(e.Param, "-") {
+		kind = "flag"
+	}
+	msg := fmt.Sprintf(`%s '%s': cannot parse "%s" as %s`, kind, e.Param, e.Input, e.Type)
+
+	// The message above says all there is to say about a syntax error from strconv.
+	// For other errors, add the reason.
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(e.Err, &numErr) && numErr.Err == strconv.ErrSyntax:
+		return msg
+	case errors.As(e.Err, &numErr):
+		return msg + ": " + numErr.Err.Error()
+	default:
+		return msg + ": " + e.Err.Error()
+	}
+}
+
+// parseErr produces a [ParseErr] for a failure to parse input as the value of p.
+func parseErr(p Param, input string, err error) ParseErr {
+	return ParseErr{Err: err, Param: strings.TrimSuffix(p.Name, "?"), Type: p.Type, Input: input}
 }
 
 // Unwrap unwraps the nested error in e.
@@ -26,6 +65,82 @@ func (e ParseErr) Unwrap() error {
 	return e.Err
 }
 
+// ExtraArgsErr is the usage error returned when arguments remain after parsing a strict subcommand's parameters.
+// See Subcmd.Strict and [WithStrictArgs].
+type ExtraArgsErr struct {
+	// Args are the unconsumed arguments.
+	Args []string
+
+	cat Catalog
+}
+
+func (e ExtraArgsErr) Error() string {
+	return fmt.Sprintf(e.cat.tr(MsgExtraArgs), strings.Join(e.Args, " "))
+}
+
+// Detail implements Usage.
+func (e ExtraArgsErr) Detail() string {
+	b := new(strings.Builder)
+	fmt.Fprintln(b, e.cat.tr(MsgExtraArgsDetail))
+	for _, arg := range e.Args {
+		fmt.Fprintf(b, "  %s\n", arg)
+	}
+	return b.String()
+}
+
+// MissingFlagErr is the usage error returned when required flags are not supplied.
+// See Param.Required.
+type MissingFlagErr struct {
+	// Flags are the names of the missing flags, each with a leading "-".
+	Flags []string
+
+	cat Catalog
+}
+
+func (e MissingFlagErr) Error() string {
+	if len(e.Flags) == 1 {
+		return fmt.Sprintf(e.cat.tr(MsgMissingFlag), e.Flags[0])
+	}
+	return fmt.Sprintf(e.cat.tr(MsgMissingFlags), strings.Join(e.Flags, " "))
+}
+
+// Detail implements Usage.
+func (e MissingFlagErr) Detail() string {
+	b := new(strings.Builder)
+	fmt.Fprintln(b, e.cat.tr(MsgMissingFlagsDetail))
+	for _, f := range e.Flags {
+		fmt.Fprintf(b, "  %s\n", f)
+	}
+	return b.String()
+}
+
+// FlagRequiresErr is the usage error returned when a flag is supplied without the other flags it requires.
+// See Param.Requires.
+type FlagRequiresErr struct {
+	// Flag is the name of the flag that was supplied, with a leading "-".
+	Flag string
+
+	// Missing are the names of the flags it requires that were not supplied,
+	// each with a leading "-".
+	Missing []string
+
+	cat Catalog
+}
+
+func (e FlagRequiresErr) Error() string {
+	return fmt.Sprintf(e.cat.tr(MsgFlagRequires), e.Flag, strings.Join(e.Missing, e.cat.tr(MsgAnd)))
+}
+
+// Detail implements Usage.
+func (e FlagRequiresErr) Detail() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, e.cat.tr(MsgFlagRequiresDetail)+"\n", e.Flag)
+	for _, f := range e.Missing {
+		fmt.Fprintf(b, "  %s\n", f)
+	}
+	return b.String()
+}
+
 // UsageErr is the type of errors that give usage information.
 // Such errors have the usual Error() method producing a one-line string,
 // but also a Detail() method producing a multiline string with more detail.
@@ -36,77 +151,173 @@ type UsageErr interface {
 
 // MissingSubcmdErr is a usage error returned when [Run] is called with an empty args list.
 type MissingSubcmdErr struct {
-	pairs []subcmdPair
-	cmd   Cmd
+	pairs   []subcmdPair
+	cmd     Cmd
+	subcmds Map
+	prog    string
+	cat     Catalog
+	df      DetailFormatter
 }
 
 func (e *MissingSubcmdErr) Error() string {
-	return fmt.Sprintf("missing subcommand, want one of: %s", strings.Join(subcmdNames(e.cmd), "; "))
+	return fmt.Sprintf(e.cat.tr(MsgMissingSubcmd), strings.Join(subcmdNames(listSubcmds(e.cmd, e.subcmds)), "; "))
+}
+
+// Names returns the names of the available subcommands.
+func (e *MissingSubcmdErr) Names() []string {
+	return subcmdNames(listSubcmds(e.cmd, e.subcmds))
 }
 
 // Detail implements Usage.
 func (e *MissingSubcmdErr) Detail() string {
-	return missingUnknownSubcmd("Missing subcommand, want one of:", e.cmd)
+	subcmds := listSubcmds(e.cmd, e.subcmds)
+	text := missingUnknownSubcmd(e.cat.tr(MsgMissingSubcmdDetail), subcmds, e.cat)
+	if e.df == nil {
+		return text
+	}
+	return formatDetail(e.df, e, newHelpData(HelpInfo{Subcmds: subcmdsInfo(subcmds, false)}, e.prog, e.pairs, text))
 }
 
-// HelpRequestedErr is a usage error returned when the "help" pseudo-subcommand-name is used.
+// HelpRequestedErr is a usage error returned when the "help" pseudo-subcommand-name is used,
+// or when a -h or -help flag follows a subcommand name.
 type HelpRequestedErr struct {
-	pairs []subcmdPair
-	cmd   Cmd
-	name  string
+	pairs   []subcmdPair
+	cmd     Cmd
+	subcmds Map
+	name    string
+	prog    string
+	json    bool
+	all     bool
+	tmpl    *template.Template
+	cat     Catalog
+	df      DetailFormatter
+	err     error
+}
+
+// newHelpRequestedErr produces a HelpRequestedErr for the subcommands of c,
+// which has the given path.
+func newHelpRequestedErr(ctx context.Context, pairs []subcmdPair, c Cmd, subcmds Map) *HelpRequestedErr {
+	return &HelpRequestedErr{
+		pairs:   pairs,
+		cmd:     c,
+		subcmds: subcmds,
+		prog:    ProgName(ctx),
+		tmpl:    helpTemplate(ctx, c),
+		cat:     catalog(ctx),
+		df:      detailFormatter(ctx, c),
+	}
+}
+
+// isHelpFlag tells whether arg is a flag requesting help.
+func isHelpFlag(arg string) bool {
+	switch arg {
+	case "-h", "--h", "-help", "--help":
+		return true
+	}
+	return false
+}
+
+// Unwrap returns [flag.ErrHelp] if help was requested with a -h or -help flag
+// (as in "prog foo -h"),
+// and nil if it was requested with the "help" pseudo-subcommand
+// (as in "prog help foo").
+func (e *HelpRequestedErr) Unwrap() error {
+	return e.err
 }
 
 func (e *HelpRequestedErr) Error() string {
 	if e.name != "" {
 		// foo bar help baz
-		subcmds := e.cmd.Subcmds()
-		subcmd, ok := subcmds[e.name]
-		if !ok {
-			return fmt.Sprintf(`unknown subcommand "%s", want one of: %s`, e.name, strings.Join(subcmdNames(e.cmd), "; "))
-		}
-
-		fs, _, positional, err := ToFlagSet(subcmd.Params)
+		line, err := e.usageLine()
 		if err != nil {
-			return fmt.Sprintf("error constructing usage string: %s", err.Error())
+			return err.Error()
 		}
+		return fmt.Sprintf(e.cat.tr(MsgUsage), line)
+	}
 
-		b := new(strings.Builder)
-		fmt.Fprintf(b, "usage: %s", os.Args[0])
-		for _, pair := range e.pairs {
-			fmt.Fprint(b, " ", pair.name)
-		}
-		fmt.Fprintf(b, " %s", e.name)
+	// foo bar help
+	return fmt.Sprintf(e.cat.tr(MsgSubcmdsAre), strings.Join(subcmdNames(listSubcmds(e.cmd, e.subcmds)), "; "))
+}
 
-		fs.VisitAll(func(f *flag.Flag) {
-			if name, _ := flag.UnquoteUsage(f); name == "" {
-				fmt.Fprintf(b, " [-%s]", f.Name)
-			} else {
-				fmt.Fprintf(b, " [-%s %s]", f.Name, name)
-			}
-		})
-		for _, p := range positional {
-			name := p.Name
-			if strings.HasSuffix(name, "?") {
-				fmt.Fprintf(b, " [%s]", name[:len(name)-1])
-			} else {
-				fmt.Fprint(b, " ", name)
-			}
+// usageLine produces the one-line usage summary for the subcommand named in e,
+// starting with the program name.
+func (e *HelpRequestedErr) usageLine() (string, error) {
+	subcmd, ok := lookupSubcmd(e.cmd, e.subcmds, e.name)
+	if !ok {
+		return "", e.unknown()
+	}
+
+	fs, _, positional, err := ToFlagSet(subcmd.Params)
+	if err != nil {
+		return "", fmt.Errorf("error constructing usage string: %w", err)
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprint(b, e.prog)
+	for _, pair := range e.pairs {
+		fmt.Fprint(b, " ", pair.name)
+	}
+	fmt.Fprintf(b, " %s", e.name)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if name, _ := unquoteUsage(f); name == "" {
+			fmt.Fprintf(b, " [-%s]", f.Name)
+		} else {
+			fmt.Fprintf(b, " [-%s %s]", f.Name, name)
+		}
+	})
+	for _, p := range positional {
+		name := p.Name
+		if strings.HasSuffix(name, "?") {
+			fmt.Fprintf(b, " [%s]", name[:len(name)-1])
+		} else {
+			fmt.Fprint(b, " ", name)
 		}
-		return b.String()
 	}
+	return b.String(), nil
+}
 
-	// foo bar help
-	return fmt.Sprintf("subcommands are: %s", strings.Join(subcmdNames(e.cmd), "; "))
+// unknown is the error for help requested for an unknown subcommand.
+func (e *HelpRequestedErr) unknown() *UnknownSubcmdErr {
+	return &UnknownSubcmdErr{pairs: e.pairs, cmd: e.cmd, subcmds: e.subcmds, name: e.name, prog: e.prog, cat: e.cat, df: e.df}
 }
 
 // Detail implements Usage.
+// If the help pseudo-subcommand was given the -all flag,
+// the result includes the entire hierarchy of nested subcommands (see Subcmd.Sub),
+// indented as in the output of [Tree].
+// If it was given the -json flag,
+// the result is JSON (see [HelpRequestedErr.MarshalJSON]).
+// Otherwise, if a help template is in effect (see [WithHelpTemplate]),
+// the result is produced by executing it.
+// Either of those may be replaced by a [DetailFormatter].
 func (e *HelpRequestedErr) Detail() string {
+	if e.json {
+		return e.detailJSON()
+	}
+	var text string
+	if e.tmpl != nil {
+		text = e.detailTemplate()
+	} else {
+		text = e.detailText()
+	}
+	if e.df == nil {
+		return text
+	}
+	info, err := e.info()
+	if err != nil {
+		return text
+	}
+	return formatDetail(e.df, e, newHelpData(info, e.prog, e.pairs, text))
+}
+
+// detailText is the default result of Detail.
+func (e *HelpRequestedErr) detailText() string {
 	if e.name != "" {
 		// foo bar help baz
-		subcmds := e.cmd.Subcmds()
-		subcmd, ok := subcmds[e.name]
+		subcmd, ok := lookupSubcmd(e.cmd, e.subcmds, e.name)
 		if !ok {
-			return fmt.Sprintf(`unknown subcommand "%s", want one of: %s`, e.name, strings.Join(subcmdNames(e.cmd), "; "))
+			return e.unknown().Error()
 		}
 
 		fs, _, positional, err := ToFlagSet(subcmd.Params)
@@ -119,23 +330,38 @@ func (e *HelpRequestedErr) Detail() string {
 		if subcmd.Desc != "" {
 			fmt.Fprintf(b, "%s: %s\n", e.name, subcmd.Desc)
 		}
+		if subcmd.Deprecated != "" {
+			fmt.Fprintf(b, e.cat.tr(MsgDeprecatedDetail)+"\n", subcmd.Deprecated)
+		}
 
-		fmt.Fprintf(b, "Usage: %s", os.Args[0])
+		usage := new(strings.Builder)
+		fmt.Fprint(usage, e.prog)
 		for _, pair := range e.pairs {
-			fmt.Fprint(b, " ", pair.name)
+			fmt.Fprint(usage, " ", pair.name)
 		}
-		fmt.Fprintf(b, " %s", e.name)
+		fmt.Fprintf(usage, " %s", e.name)
 
-		var maxlen int
+		var (
+			maxlen   int
+			required = requiredFlags(subcmd.Params)
+		)
 		fs.VisitAll(func(f *flag.Flag) {
-			var l int
-			if name, _ := flag.UnquoteUsage(f); name == "" {
-				fmt.Fprintf(b, " [-%s]", f.Name)
+			var (
+				l         int
+				flagUsage string
+			)
+			if name, _ := unquoteUsage(f); name == "" {
+				flagUsage = "-" + f.Name
 				l = len(f.Name)
 			} else {
-				fmt.Fprintf(b, " [-%s %s]", f.Name, name)
+				flagUsage = "-" + f.Name + " " + name
 				l = 1 + len(f.Name) + len(name)
 			}
+			if required[f.Name] {
+				fmt.Fprintf(usage, " %s", flagUsage)
+			} else {
+				fmt.Fprintf(usage, " [%s]", flagUsage)
+			}
 			if l > maxlen {
 				maxlen = l
 			}
@@ -143,31 +369,72 @@ func (e *HelpRequestedErr) Detail() string {
 		for _, p := range positional {
 			name := p.Name
 			if strings.HasSuffix(name, "?") {
-				fmt.Fprintf(b, " [%s]", name[:len(name)-1])
+				fmt.Fprintf(usage, " [%s]", name[:len(name)-1])
 			} else {
-				fmt.Fprint(b, " ", name)
+				fmt.Fprint(usage, " ", name)
 			}
 		}
-		fmt.Fprintln(b)
+		fmt.Fprintf(b, e.cat.tr(MsgUsageDetail)+"\n", usage)
 
 		format := fmt.Sprintf("-%%-%d.%ds  %%s\n", maxlen, maxlen)
 
+		flagParams := make(map[string]Param)
+		for _, p := range subcmd.Params {
+			if strings.HasPrefix(p.Name, "-") {
+				flagParams[strings.TrimLeft(p.Name, "-")] = p
+			}
+		}
+
 		fs.VisitAll(func(f *flag.Flag) {
-			if name, u := flag.UnquoteUsage(f); name == "" {
+			name, u := unquoteUsage(f)
+			p := flagParams[f.Name]
+			if len(p.Choices) > 0 {
+				u += fmt.Sprintf(" (%s)", choicesDoc(p.Choices))
+			}
+			if p.Env != "" {
+				u += fmt.Sprintf(" [$%s]", p.Env)
+			}
+			if name == "" {
 				fmt.Fprintf(b, format, f.Name, u)
 			} else {
 				fmt.Fprintf(b, format, f.Name+" "+name, u)
 			}
 		})
 
+		if len(positional) > 0 {
+			fmt.Fprintln(b, e.cat.tr(MsgPositionalParams))
+			writePositionalDocs(b, positional, e.cat)
+		}
+
+		if len(subcmd.Examples) > 0 {
+			fmt.Fprintln(b, e.cat.tr(MsgExamples))
+			for _, ex := range subcmd.Examples {
+				fmt.Fprintf(b, "  %s\n", ex)
+			}
+		}
+
+		if e.all && subcmd.Sub != nil {
+			fmt.Fprintln(b, e.cat.tr(MsgSubcmdsAreDetail))
+			if err := tree(b, subcmd.Sub, e.cat); err != nil {
+				return fmt.Sprintf("error constructing subcommand tree: %s", err.Error())
+			}
+		}
+
 		return b.String()
 	}
 
 	// foo bar help
 	b := new(strings.Builder)
-	fmt.Fprintln(b, "Subcommands are:")
-	cmdnames := subcmdNames(e.cmd)
-	subcmds := e.cmd.Subcmds()
+	fmt.Fprintln(b, e.cat.tr(MsgSubcmdsAreDetail))
+	if e.all {
+		// foo bar help -all
+		if err := tree(b, e.cmd, e.cat); err != nil {
+			return fmt.Sprintf("error constructing subcommand tree: %s", err.Error())
+		}
+		return b.String()
+	}
+	subcmds := listSubcmds(e.cmd, e.subcmds)
+	cmdnames := subcmdNames(subcmds)
 	var maxlen int
 	for _, name := range cmdnames {
 		if len(name) > maxlen {
@@ -176,33 +443,125 @@ func (e *HelpRequestedErr) Detail() string {
 	}
 	format := fmt.Sprintf("%%-%d.%ds  %%s\n", maxlen, maxlen)
 	for _, name := range cmdnames {
-		fmt.Fprintf(b, format, name, subcmds[name].Desc)
+		fmt.Fprintf(b, format, name, subcmds[name].helpDesc(e.cat))
 	}
 
 	return b.String()
 }
 
+// writePositionalDocs writes a line to b for each of the given positional parameters,
+// showing its name, type, and doc string,
+// and whether it is optional and its default value if so.
+func writePositionalDocs(b *strings.Builder, positional []Param, cat Catalog) {
+	type row struct {
+		usage, doc string
+	}
+
+	var (
+		rows   []row
+		maxlen int
+	)
+	for _, p := range positional {
+		var (
+			name     = strings.TrimSuffix(p.Name, "?")
+			optional = strings.HasSuffix(p.Name, "?")
+		)
+
+		// Use a single-flag FlagSet to get the type name, the unquoted doc string, and the formatted default value,
+		// the same way they are produced for flags.
+		fs, _, _, err := ToFlagSet([]Param{{Name: "-" + name, Type: p.Type, Default: p.Default, Doc: p.Doc, Choices: p.Choices}})
+		if err != nil {
+			rows = append(rows, row{usage: name, doc: fmt.Sprintf("error: %s", err)})
+			continue
+		}
+		f := fs.Lookup(name)
+		typ, doc := unquoteUsage(f)
+		if typ == "" {
+			typ = "bool"
+		}
+		if len(p.Choices) > 0 {
+			doc += fmt.Sprintf(" (%s)", choicesDoc(p.Choices))
+		}
+		if optional {
+			doc += " " + fmt.Sprintf(cat.tr(MsgOptionalDefault), f.DefValue)
+		}
+
+		usage := name + " " + typ
+		if len(usage) > maxlen {
+			maxlen = len(usage)
+		}
+		rows = append(rows, row{usage: usage, doc: doc})
+	}
+
+	format := fmt.Sprintf("%%-%d.%ds  %%s\n", maxlen, maxlen)
+	for _, r := range rows {
+		fmt.Fprintf(b, format, r.usage, r.doc)
+	}
+}
+
 // UnknownSubcmdErr is a usage error returned when an unknown subcommand name is passed to [Run] as args[0].
 type UnknownSubcmdErr struct {
-	pairs []subcmdPair
-	cmd   Cmd
-	name  string
+	pairs   []subcmdPair
+	cmd     Cmd
+	subcmds Map
+	name    string
+	prog    string
+	cat     Catalog
+	df      DetailFormatter
 }
 
+// Error implements error.
+// If there are any plausible misspellings of the unknown name among the available subcommands
+// (see [UnknownSubcmdErr.Suggestions]),
+// the message suggests them.
+// Otherwise it lists all the available subcommands.
 func (e *UnknownSubcmdErr) Error() string {
-	return fmt.Sprintf(`unknown subcommand "%s", want one of: %s`, e.name, strings.Join(subcmdNames(e.cmd), "; "))
+	if q := didYouMean(e.Suggestions(), e.cat); q != "" {
+		return fmt.Sprintf(e.cat.tr(MsgUnknownSubcmdSuggest), e.name, q)
+	}
+	return fmt.Sprintf(e.cat.tr(MsgUnknownSubcmd), e.name, strings.Join(subcmdNames(listSubcmds(e.cmd, e.subcmds)), "; "))
+}
+
+// Name returns the unknown subcommand name.
+func (e *UnknownSubcmdErr) Name() string {
+	return e.name
+}
+
+// Names returns the names of the available subcommands.
+func (e *UnknownSubcmdErr) Names() []string {
+	return subcmdNames(listSubcmds(e.cmd, e.subcmds))
+}
+
+// Suggestions returns the names of the available subcommands
+// that are close enough to the unknown name
+// (by edit distance)
+// to be plausible misspellings of it,
+// closest first.
+func (e *UnknownSubcmdErr) Suggestions() []string {
+	return suggestions(e.name, subcmdNames(listSubcmds(e.cmd, e.subcmds)))
 }
 
 // Detail implements Usage.
 func (e *UnknownSubcmdErr) Detail() string {
-	return missingUnknownSubcmd(fmt.Sprintf(`Unknown subcommand "%s", want one of:`, e.name), e.cmd)
+	var (
+		subcmds = listSubcmds(e.cmd, e.subcmds)
+		text    string
+	)
+	if q := didYouMean(e.Suggestions(), e.cat); q != "" {
+		text = missingUnknownSubcmd(fmt.Sprintf(e.cat.tr(MsgUnknownSubcmdSuggestDetail), e.name, q)+"\n"+e.cat.tr(MsgWantOneOf), subcmds, e.cat)
+	} else {
+		text = missingUnknownSubcmd(fmt.Sprintf(e.cat.tr(MsgUnknownSubcmdDetail), e.name), subcmds, e.cat)
+	}
+	if e.df == nil {
+		return text
+	}
+	return formatDetail(e.df, e, newHelpData(HelpInfo{Subcmds: subcmdsInfo(subcmds, false)}, e.prog, e.pairs, text))
 }
 
-func missingUnknownSubcmd(line1 string, cmd Cmd) string {
+func missingUnknownSubcmd(line1 string, subcmds Map, cat Catalog) string {
 	b := new(strings.Builder)
 	fmt.Fprintln(b, line1)
-	cmdnames := subcmdNames(cmd)
-	subcmds := cmd.Subcmds()
+	cmdnames := subcmdNames(subcmds)
 	var maxlen int
 	for _, name := range cmdnames {
 		if len(name) > maxlen {
@@ -211,11 +570,30 @@ func missingUnknownSubcmd(line1 string, cmd Cmd) string {
 	}
 	format := fmt.Sprintf("%%-%d.%ds  %%s\n", maxlen, maxlen)
 	for _, name := range cmdnames {
-		fmt.Fprintf(b, format, name, subcmds[name].Desc)
+		fmt.Fprintf(b, format, name, subcmds[name].helpDesc(cat))
 	}
 	return b.String()
 }
 
+// UnsupportedErr is the error returned when [Run] is asked to run a subcommand
+// that is not supported in the current environment (see Subcmd.Supported).
+type UnsupportedErr struct {
+	// Name is the name of the subcommand.
+	Name string
+
+	// Reason is the explanation given by the subcommand's Supported function.
+	Reason string
+
+	cat Catalog
+}
+
+func (e *UnsupportedErr) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf(e.cat.tr(MsgUnsupported), e.Name)
+	}
+	return fmt.Sprintf(e.cat.tr(MsgUnsupportedReason), e.Name, e.Reason)
+}
+
 // FuncTypeErr means a [Subcmd]'s F field has a type that does not match the function signature implied by its Params field.
 type FuncTypeErr struct {
 	// Got is the type of the F field.
@@ -242,3 +620,28 @@ type ParamDefaultErr struct {
 func (e ParamDefaultErr) Error() string {
 	return fmt.Sprintf("default value %v is not of type %v", e.Param.Default, e.Param.Type)
 }
+
+// ParamNameErr is the error when a [Param] has a name that is empty,
+// that is a flag name containing whitespace or "=",
+// or that is the same as the name of an earlier Param.
+type ParamNameErr struct {
+	// Name is the Param's name.
+	Name string
+
+	// Index is the Param's position in its list, starting at 0.
+	Index int
+
+	// Dup, if not empty, is the name of the earlier Param with the same name.
+	Dup string
+}
+
+func (e ParamNameErr) Error() string {
+	switch {
+	case e.Dup != "":
+		return fmt.Sprintf("param %s has the same name as param %s", e.Name, e.Dup)
+	case strings.Trim(e.Name, "-?") == "":
+		return fmt.Sprintf("param %d has an empty name", e.Index+1)
+	default:
+		return fmt.Sprintf("flag name %q contains whitespace or '='", e.Name)
+	}
+}
@@ -0,0 +1,53 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExamples(t *testing.T) {
+	examples := []string{
+		"prog list",
+		"prog list -reverse  # newest first",
+	}
+	c := testcmdfunc(func() Map {
+		return Map{
+			"list": Subcmd{
+				F:        func(context.Context, bool, []string) {},
+				Desc:     "list things",
+				Params:   Params("-reverse", Bool, false, "reverse order"),
+				Examples: examples,
+			},
+		}
+	})
+
+	err := Run(context.Background(), c, []string{"help", "list"}, WithProgName("prog"))
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	want := `Examples:
+  prog list
+  prog list -reverse  # newest first
+`
+	if detail := h.Detail(); !strings.HasSuffix(detail, want) {
+		t.Errorf("got:\n%s\nwant suffix:\n%s", detail, want)
+	}
+
+	err = Run(context.Background(), c, []string{"help", "-json", "list"}, WithProgName("prog"))
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	var info HelpInfo
+	if err := json.Unmarshal([]byte(h.Detail()), &info); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(examples, info.Examples); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
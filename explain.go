@@ -0,0 +1,130 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WithExplain is an [Option] that causes [Run] to write to w
+// a description of exactly how the command line was interpreted
+// before calling the selected subcommand's function:
+// the command-line arguments (if any) that supplied each flag and positional parameter,
+// or else where its value came from instead
+// (its default, the parent process [see ParsePluginEnv], a persistent flag [see PersistentFlagger], a config file [see WithConfigFlag], or an environment variable [see WithEnvPrefix]);
+// and what remained in the trailing args.
+// If w is nil, the description goes to [Stderr].
+//
+// The values of parameters whose names suggest they hold secrets are shown as "REDACTED"
+// (see [WithLogger]).
+//
+// This is useful in debugging surprising argument-parsing behavior.
+func WithExplain(w io.Writer) Option {
+	return func(c *config) error {
+		c.explain = true
+		c.explainTo = w
+		return nil
+	}
+}
+
+// explanation records where the flag values in a FlagSet came from.
+// A nil *explanation is valid and does nothing.
+type explanation struct {
+	ctx     context.Context
+	w       io.Writer
+	fs      *flag.FlagSet
+	sources map[string]string // flag name -> source of its value
+}
+
+func newExplanation(ctx context.Context, fs *flag.FlagSet) *explanation {
+	conf := getConfig(ctx)
+	if !conf.explain {
+		return nil
+	}
+	w := conf.explainTo
+	if w == nil {
+		w = Stderr(ctx)
+	}
+	return &explanation{
+		ctx:     ctx,
+		w:       w,
+		fs:      fs,
+		sources: make(map[string]string),
+	}
+}
+
+// note attributes any flags that have been set since the last call to note to the source computed by f.
+func (x *explanation) note(f func(name string) string) {
+	if x == nil {
+		return
+	}
+	x.fs.Visit(func(fl *flag.Flag) {
+		if _, ok := x.sources[fl.Name]; !ok {
+			x.sources[fl.Name] = f(fl.Name)
+		}
+	})
+}
+
+// print writes the explanation.
+// The args are the arguments before parsing,
+// rest is what remains after parsing flags and positional parameters,
+// and posvals are the values of the positional parameters.
+func (x *explanation) print(params []Param, args, rest []string, posvals []reflect.Value) {
+	if x == nil {
+		return
+	}
+
+	var (
+		flagToks, first = scanFlagArgs(params, args)
+		flagArgs        = make(map[string][]string)
+	)
+	for _, tok := range flagToks {
+		flagArgs[tok.name] = append(flagArgs[tok.name], args[tok.idx])
+		if tok.val >= 0 {
+			flagArgs[tok.name] = append(flagArgs[tok.name], args[tok.val])
+		}
+	}
+
+	fmt.Fprintf(x.w, "%s %s:\n", ProgName(x.ctx), strings.Join(CommandPath(x.ctx), " "))
+
+	var (
+		consumed = len(args) - first - len(rest)
+		pos      int
+	)
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			name := strings.TrimLeft(p.Name, "-")
+			val := x.fs.Lookup(name).Value.String()
+			var source string
+			if fa, ok := flagArgs[name]; ok {
+				source = "from " + strings.Join(redactArgs(params, fa), " ")
+			} else if s, ok := x.sources[name]; ok {
+				source = "from " + s
+			} else {
+				source = "default"
+			}
+			fmt.Fprintf(x.w, "  -%s = %s (%s)\n", name, explainValue(p.Name, val), source)
+			continue
+		}
+
+		val := fmt.Sprint(posvals[pos].Interface())
+		if pos < consumed {
+			fmt.Fprintf(x.w, "  %s = %s (from argument %d)\n", p.Name, explainValue(p.Name, val), first+pos+1)
+		} else {
+			fmt.Fprintf(x.w, "  %s = %s (default)\n", p.Name, explainValue(p.Name, val))
+		}
+		pos++
+	}
+
+	fmt.Fprintf(x.w, "  trailing args: %q\n", rest)
+}
+
+func explainValue(name, val string) string {
+	if isSecretName(name) {
+		return "REDACTED"
+	}
+	return fmt.Sprintf("%q", val)
+}
@@ -0,0 +1,56 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(context.Context, int, string, string, string, string, []string) {}, "", Params(
+			"-n", Int, 1, "",
+			"-s", String, "", "",
+			"-password", String, "", "",
+			"dir", String, "", "",
+			"opt?", String, "dflt", "",
+		))
+	})
+
+	defer testSetenv("MYAPP_X_S", "env")()
+
+	buf := new(bytes.Buffer)
+	err := Run(context.Background(), c, []string{"x", "-password=hunter2", "here", "a", "b"}, WithExplain(buf), WithEnvPrefix("MYAPP"), WithProgName("prog"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `prog x:
+  -n = "1" (default)
+  -s = "env" (from $MYAPP_X_S)
+  -password = REDACTED (from -password=REDACTED)
+  dir = "here" (from argument 2)
+  opt? = "a" (from argument 3)
+  trailing args: ["b"]
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err = Run(context.Background(), c, []string{"x", "-n", "3", "--", "here"}, WithExplain(buf), WithProgName("prog")); err != nil {
+		t.Fatal(err)
+	}
+
+	const want2 = `prog x:
+  -n = "3" (from -n 3)
+  -s = "" (default)
+  -password = REDACTED (default)
+  dir = "here" (from argument 4)
+  opt? = "dflt" (default)
+  trailing args: []
+`
+	if got := buf.String(); got != want2 {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want2)
+	}
+}
@@ -0,0 +1,54 @@
+package subcmd
+
+import (
+	"context"
+	"time"
+)
+
+// fastCall is a built-in [Shim] for the most common subcommand function signatures:
+// those taking no parameters besides the context and the remaining args,
+// and those taking a single parameter of one of the non-Value types.
+// Calling these directly is cheaper than reflect.Value.Call,
+// and produces more readable stack traces when the function panics.
+func fastCall(f interface{}, args []interface{}) (bool, error) {
+	switch f := f.(type) {
+	case func(context.Context, []string) error:
+		return true, f(args[0].(context.Context), args[1].([]string))
+	case func(context.Context, []string):
+		f(args[0].(context.Context), args[1].([]string))
+		return true, nil
+	case func(context.Context, ...string) error:
+		return true, f(args[0].(context.Context), args[1].([]string)...)
+	case func(context.Context, ...string):
+		f(args[0].(context.Context), args[1].([]string)...)
+		return true, nil
+
+	case func(context.Context, bool, []string) error:
+		return true, f(args[0].(context.Context), args[1].(bool), args[2].([]string))
+	case func(context.Context, bool, []string):
+		f(args[0].(context.Context), args[1].(bool), args[2].([]string))
+		return true, nil
+	case func(context.Context, int, []string) error:
+		return true, f(args[0].(context.Context), args[1].(int), args[2].([]string))
+	case func(context.Context, int, []string):
+		f(args[0].(context.Context), args[1].(int), args[2].([]string))
+		return true, nil
+	case func(context.Context, int64, []string) error:
+		return true, f(args[0].(context.Context), args[1].(int64), args[2].([]string))
+	case func(context.Context, uint, []string) error:
+		return true, f(args[0].(context.Context), args[1].(uint), args[2].([]string))
+	case func(context.Context, uint64, []string) error:
+		return true, f(args[0].(context.Context), args[1].(uint64), args[2].([]string))
+	case func(context.Context, string, []string) error:
+		return true, f(args[0].(context.Context), args[1].(string), args[2].([]string))
+	case func(context.Context, string, []string):
+		f(args[0].(context.Context), args[1].(string), args[2].([]string))
+		return true, nil
+	case func(context.Context, float64, []string) error:
+		return true, f(args[0].(context.Context), args[1].(float64), args[2].([]string))
+	case func(context.Context, time.Duration, []string) error:
+		return true, f(args[0].(context.Context), args[1].(time.Duration), args[2].([]string))
+	}
+
+	return false, nil
+}
@@ -0,0 +1,80 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// FlagErr is the error returned when parsing a subcommand's flags fails.
+type FlagErr struct {
+	// Path is the [CommandPath] of the subcommand.
+	Path []string
+
+	// Flag is the name of the offending flag, without its leading "-".
+	// If the problem is malformed flag syntax,
+	// this is the offending argument instead.
+	Flag string
+
+	// Type is the type of the flag,
+	// or zero if there is no such flag.
+	Type Type
+
+	// Err is the error produced by the [flag] package.
+	Err error
+}
+
+func (e FlagErr) Error() string {
+	if len(e.Path) == 0 {
+		// Persistent flags before the first subcommand name.
+		return fmt.Sprintf("parsing flags: %s", e.Err)
+	}
+	return fmt.Sprintf("parsing flags for %s: %s", strings.Join(e.Path, " "), e.Err)
+}
+
+// Unwrap unwraps the nested error in e.
+func (e FlagErr) Unwrap() error {
+	return e.Err
+}
+
+// newFlagErr produces a FlagErr from err,
+// an error returned from parsing fs.
+func newFlagErr(ctx context.Context, fs *flag.FlagSet, params []Param, err error) FlagErr {
+	result := FlagErr{
+		Path: CommandPath(ctx),
+		Flag: flagErrName(err.Error()),
+		Err:  err,
+	}
+	if fs.Lookup(result.Flag) != nil {
+		for _, p := range params {
+			if strings.TrimLeft(p.Name, "-") == result.Flag {
+				result.Type = p.Type
+				break
+			}
+		}
+	}
+	return result
+}
+
+// flagErrName extracts the flag name from an error message produced by the flag package.
+func flagErrName(msg string) string {
+	for _, prefix := range []string{"flag provided but not defined: ", "flag needs an argument: ", "invalid boolean flag "} {
+		if rest, ok := strings.CutPrefix(msg, prefix); ok {
+			name, _, _ := strings.Cut(rest, ":")
+			return strings.TrimLeft(name, "-")
+		}
+	}
+	if rest, ok := strings.CutPrefix(msg, "bad flag syntax: "); ok {
+		return rest
+	}
+	if strings.HasPrefix(msg, "invalid ") {
+		// invalid value "x" for flag -name: ...
+		// invalid boolean value "x" for -name: ...
+		if idx := strings.Index(msg, " -"); idx >= 0 {
+			name, _, _ := strings.Cut(msg[idx+2:], ":")
+			return name
+		}
+	}
+	return ""
+}
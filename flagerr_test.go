@@ -0,0 +1,40 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFlagErr(t *testing.T) {
+	cases := []struct {
+		args     []string
+		wantFlag string
+		wantType Type
+	}{
+		{args: []string{"-nope"}, wantFlag: "nope"},
+		{args: []string{"-a2", "x"}, wantFlag: "a2", wantType: Int},
+		{args: []string{"-a1=x"}, wantFlag: "a1", wantType: Bool},
+		{args: []string{"-a3"}, wantFlag: "a3", wantType: String},
+		{args: []string{"---a3"}, wantFlag: "---a3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.wantFlag, func(t *testing.T) {
+			err := Run(context.Background(), errtestcmd{}, append([]string{"a"}, tc.args...))
+			var e FlagErr
+			if !errors.As(err, &e) {
+				t.Fatalf("got %v, want FlagErr", err)
+			}
+			if !reflect.DeepEqual(e.Path, []string{"a"}) {
+				t.Errorf("got path %v, want [a]", e.Path)
+			}
+			if e.Flag != tc.wantFlag {
+				t.Errorf(`got flag "%s", want "%s"`, e.Flag, tc.wantFlag)
+			}
+			if e.Type != tc.wantType {
+				t.Errorf("got type %v, want %v", e.Type, tc.wantType)
+			}
+		})
+	}
+}
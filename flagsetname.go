@@ -0,0 +1,45 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// flagSetName is the name for the FlagSet of the subcommand being run:
+// the program name followed by the subcommand path,
+// as in "prog db migrate".
+func flagSetName(ctx context.Context) string {
+	return strings.Join(append([]string{ProgName(ctx)}, CommandPath(ctx)...), " ")
+}
+
+// parseFlags parses args with fs,
+// which it names with flagSetName.
+// It reports errors, and usage, to [Stderr]
+// the way fs.Parse would,
+// except that error messages are prefixed with the FlagSet's name,
+// as in "prog db migrate: flag provided but not defined: -x".
+//
+// Nothing is reported for flag.ErrHelp,
+// which Run turns into a HelpRequestedErr.
+func parseFlags(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	fs.Init(flagSetName(ctx), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	err := fs.Parse(args)
+	fs.SetOutput(Stderr(ctx))
+
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	fmt.Fprintf(fs.Output(), "%s: %s\n", fs.Name(), err)
+	if fs.Usage != nil {
+		fs.Usage()
+	} else {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		fs.PrintDefaults()
+	}
+	return err
+}
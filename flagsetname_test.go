@@ -0,0 +1,26 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFlagSetName(t *testing.T) {
+	var (
+		c      = nestedtestcmd{migrate: func(context.Context, int, string, []string) {}}
+		stderr = new(strings.Builder)
+		opts   = []Option{WithProgName("prog"), WithStdio(nil, nil, stderr)}
+	)
+
+	err := Run(context.Background(), c, []string{"db", "migrate", "-x", "dir"}, opts...)
+	var ferr FlagErr
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got %v, want FlagErr", err)
+	}
+	want := "prog db migrate: flag provided but not defined: -x\nUsage of prog db migrate:\n"
+	if got := stderr.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("got %q, want prefix %q", got, want)
+	}
+}
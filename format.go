@@ -0,0 +1,83 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+)
+
+// Formatter is an interface for customizing the wording of common errors returned by [Run].
+// See [WithFormatter].
+type Formatter interface {
+	// FormatError returns the message to use for err,
+	// or "" to use err's own message.
+	// It is called with errors of type *[MissingSubcmdErr], *[UnknownSubcmdErr], and [ParseErr],
+	// and with errors that wrap [ErrTooFewArgs].
+	FormatError(err error) string
+}
+
+// WithFormatter is an [Option] that uses f to produce the messages
+// for the most common errors returned by [Run]:
+// missing and unknown subcommands,
+// too few arguments,
+// and unparseable positional arguments.
+// This allows programs to use their own wording,
+// or to localize the messages.
+//
+// The resulting errors wrap the original ones,
+// so they can still be inspected with [errors.Is] and [errors.As].
+func WithFormatter(f Formatter) Option {
+	return func(c *config) error {
+		c.formatter = f
+		return nil
+	}
+}
+
+type formattedErr struct {
+	err error
+	msg string
+}
+
+func (e *formattedErr) Error() string {
+	return e.msg
+}
+
+func (e *formattedErr) Unwrap() error {
+	return e.err
+}
+
+// formatErr applies the Formatter in ctx, if there is one, to err,
+// if it is one of the kinds of error that Formatters handle.
+func formatErr(ctx context.Context, err error) error {
+	if ferr := reformat(ctx, err); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// reformat is like formatErr
+// but returns nil if err is not reformatted.
+func reformat(ctx context.Context, err error) *formattedErr {
+	if !formattable(err) {
+		return nil
+	}
+	if f := getConfig(ctx).formatter; f != nil {
+		if msg := f.FormatError(err); msg != "" {
+			return &formattedErr{err: err, msg: msg}
+		}
+	}
+	if errors.Is(err, ErrTooFewArgs) {
+		// This is the only formattable error whose message is not already localized (see Catalog).
+		if msg := catalog(ctx).tr(MsgTooFewArgs); msg != MsgTooFewArgs {
+			return &formattedErr{err: err, msg: msg}
+		}
+	}
+	return nil
+}
+
+func formattable(err error) bool {
+	switch err.(type) {
+	case *MissingSubcmdErr, *UnknownSubcmdErr, ParseErr:
+		return true
+	}
+	return errors.Is(err, ErrTooFewArgs)
+}
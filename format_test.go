@@ -0,0 +1,61 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type formattesttype struct{}
+
+func (formattesttype) FormatError(err error) string {
+	var (
+		m *MissingSubcmdErr
+		u *UnknownSubcmdErr
+		p ParseErr
+	)
+	switch {
+	case errors.As(err, &m):
+		return "pick one of " + strings.Join(m.Names(), ", ")
+	case errors.As(err, &u):
+		return fmt.Sprintf("never heard of %s", u.Name())
+	case errors.As(err, &p):
+		return "that doesn't look right"
+	case errors.Is(err, ErrTooFewArgs):
+		return "more, please"
+	}
+	return ""
+}
+
+func TestFormatter(t *testing.T) {
+	cases := []struct {
+		args   []string
+		want   string
+		wantIs error
+	}{
+		{args: nil, want: "pick one of a, bb, ccc"},
+		{args: []string{"dddd"}, want: "never heard of dddd"},
+		{args: []string{"a"}, want: "more, please", wantIs: ErrTooFewArgs},
+		{args: []string{"a", "x"}, want: "that doesn't look right"},
+	}
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			err := Run(context.Background(), errtestcmd{}, tc.args, WithFormatter(formattesttype{}))
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if err.Error() != tc.want {
+				t.Errorf(`got "%s", want "%s"`, err, tc.want)
+			}
+			if tc.wantIs != nil && !errors.Is(err, tc.wantIs) {
+				t.Errorf("error does not wrap %v", tc.wantIs)
+			}
+			var u UsageErr
+			if len(tc.args) < 2 && tc.wantIs == nil && !errors.As(err, &u) {
+				t.Error("error does not wrap a UsageErr")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package subcmd
+
+import (
+	"flag"
+	"reflect"
+)
+
+// getterType is the type of the value that a subcommand function may receive for p
+// in place of a [flag.Value]:
+// the type of the result of Get,
+// when p has type Value and its default is a [flag.Getter].
+// Otherwise it is nil.
+func (p Param) getterType() reflect.Type {
+	if p.Type != Value {
+		return nil
+	}
+	g, ok := p.Default.(flag.Getter)
+	if !ok {
+		return nil
+	}
+	return reflect.TypeOf(g.Get())
+}
+
+// fromGetter tells whether argval is a [flag.Getter] whose Get method produces a value of type t,
+// and if so returns that value.
+func fromGetter(argval reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	g, ok := argval.Interface().(flag.Getter)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(g.Get())
+	if !v.IsValid() || !v.Type().AssignableTo(t) {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
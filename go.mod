@@ -1,8 +1,5 @@
 module github.com/bobg/subcmd/v2
 
-go 1.14
+go 1.21
 
-require (
-	github.com/google/go-cmp v0.5.6
-	github.com/pkg/errors v0.9.1
-)
+require github.com/google/go-cmp v0.5.6
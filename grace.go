@@ -0,0 +1,121 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// WithGracePeriod is an [Option] that gives subcommands time to finish
+// after the context passed to [Run] is canceled
+// (e.g. by a signal or a timeout).
+//
+// When that happens,
+// Run calls the subcommand's Cleanup function (see [Subcmd]), if it has one,
+// and then waits for the subcommand function to return,
+// but no longer than d in total.
+// After that,
+// Run gives up and returns an error wrapping the context's error,
+// leaving the subcommand function running in the background.
+//
+// Plugin subprocesses (see [Prefixer]) are sent an interrupt signal when the context is canceled,
+// and are killed if they have not exited after d.
+//
+// Without this option,
+// Run waits for the subcommand function to return no matter what,
+// and kills plugin subprocesses as soon as the context is canceled.
+func WithGracePeriod(d time.Duration) Option {
+	return func(c *config) error {
+		c.gracePeriod = d
+		return nil
+	}
+}
+
+// DeadlineEnvVar is the name of the environment variable used by [Run]
+// to pass the deadline of its context, if it has one, to a plugin subprocess
+// (see [Prefixer]).
+// It is in RFC 3339 format with nanoseconds.
+// Use [EnvDeadline] to apply it.
+const DeadlineEnvVar = "SUBCMD_DEADLINE"
+
+// EnvDeadline returns a copy of ctx with the deadline given in the SUBCMD_DEADLINE environment variable,
+// if it is set.
+// Executables that implement subcommands can use this at startup
+// (along with [ParseEnv])
+// to honor the deadline of the parent process.
+func EnvDeadline(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	val := os.Getenv(DeadlineEnvVar)
+	if val == "" {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, val)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", DeadlineEnvVar, err)
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	return ctx, cancel, nil
+}
+
+// callWithGrace calls f,
+// honoring the grace period in ctx, if there is one
+// (see WithGracePeriod).
+func callWithGrace(ctx context.Context, cleanup func(context.Context) error, f func() error) error {
+	grace := getConfig(ctx).gracePeriod
+	if grace <= 0 {
+		return f()
+	}
+
+	type result struct {
+		err      error
+		panicked bool
+		val      interface{}
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		defer func() {
+			if val := recover(); val != nil {
+				r = result{panicked: true, val: val}
+			}
+			done <- r
+		}()
+		r.err = f()
+	}()
+
+	// rethrow re-panics in the calling goroutine if f panicked.
+	rethrow := func(r result) error {
+		if r.panicked {
+			panic(r.val)
+		}
+		return r.err
+	}
+
+	select {
+	case r := <-done:
+		return rethrow(r)
+	case <-ctx.Done():
+	}
+
+	logDebug(ctx, "canceled, waiting for grace period", "grace", grace)
+
+	graceCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), grace)
+	defer cancel()
+
+	var cleanupErr error
+	if cleanup != nil {
+		if err := cleanup(graceCtx); err != nil {
+			cleanupErr = fmt.Errorf("cleaning up: %w", err)
+		}
+	}
+
+	select {
+	case r := <-done:
+		return errors.Join(rethrow(r), cleanupErr)
+	case <-graceCtx.Done():
+		return errors.Join(fmt.Errorf("grace period expired: %w", context.Cause(ctx)), cleanupErr)
+	}
+}
@@ -0,0 +1,68 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGracePeriod(t *testing.T) {
+	var (
+		cleanedUp bool
+		release   = make(chan struct{})
+		errDone   = errors.New("done")
+	)
+	defer close(release)
+
+	c := testcmdfunc(func() Map {
+		return Map{
+			"finishes": Subcmd{
+				F: func(ctx context.Context, _ []string) error {
+					<-ctx.Done()
+					return errDone
+				},
+				Cleanup: func(context.Context) error {
+					cleanedUp = true
+					return nil
+				},
+			},
+			"hangs": Subcmd{
+				F: func(context.Context, []string) {
+					<-release
+				},
+			},
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Run(ctx, c, []string{"finishes"}, WithGracePeriod(time.Second)); !errors.Is(err, errDone) {
+		t.Errorf("got %v, want %v", err, errDone)
+	}
+	if !cleanedUp {
+		t.Error("cleanup function not called")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Run(ctx, c, []string{"hangs"}, WithGracePeriod(10*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestEnvDeadline(t *testing.T) {
+	deadline := time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+	defer testSetenv(DeadlineEnvVar, deadline.Format(time.RFC3339Nano))()
+
+	ctx, cancel, err := EnvDeadline(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	got, ok := ctx.Deadline()
+	if !ok || !got.Equal(deadline) {
+		t.Errorf("got deadline %v (%v), want %v", got, ok, deadline)
+	}
+}
@@ -0,0 +1,219 @@
+package subcmd
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HelpInfo is a structured description of the help requested with the "help" pseudo-subcommand.
+// It is the JSON form of a [HelpRequestedErr] (see [HelpRequestedErr.MarshalJSON])
+// and is part of the data passed to help templates (see [HelpData]).
+type HelpInfo struct {
+	// Name is the name of the subcommand for which help was requested,
+	// or "" if help was requested for all subcommands.
+	Name string `json:"name,omitempty"`
+
+	// Desc is the subcommand's description.
+	Desc string `json:"desc,omitempty"`
+
+	// Deprecated is the subcommand's deprecation message, if any.
+	Deprecated string `json:"deprecated,omitempty"`
+
+	// Usage is the subcommand's one-line usage summary.
+	Usage string `json:"usage,omitempty"`
+
+	// Params describes the subcommand's parameters.
+	Params []HelpParam `json:"params,omitempty"`
+
+	// Examples are the subcommand's example command lines.
+	Examples []string `json:"examples,omitempty"`
+
+	// Subcmds describes the available subcommands,
+	// when Name is "",
+	// or the nested subcommands of the named one (see Subcmd.Sub).
+	// If the help pseudo-subcommand was given the -all flag,
+	// each of these includes its own nested subcommands, and so on.
+	Subcmds []HelpInfo `json:"subcmds,omitempty"`
+}
+
+// HelpParam describes one [Param] in a [HelpInfo].
+type HelpParam struct {
+	// Name is the parameter name,
+	// without any leading "-" or trailing "?".
+	Name string `json:"name"`
+
+	// Type is the name of the parameter's type (see [Type.String]).
+	Type string `json:"type"`
+
+	// Flag tells whether the parameter is a flag (vs. positional).
+	Flag bool `json:"flag,omitempty"`
+
+	// Optional tells whether the parameter is an optional positional parameter.
+	Optional bool `json:"optional,omitempty"`
+
+	// Required tells whether the parameter is a required flag.
+	Required bool `json:"required,omitempty"`
+
+	// Env is the environment variable for the parameter, if any (see Param.Env).
+	Env string `json:"env,omitempty"`
+
+	// Choices are the allowed values for the parameter, if any (see Param.Choices).
+	Choices []string `json:"choices,omitempty"`
+
+	// Default is the parameter's default value.
+	Default interface{} `json:"default,omitempty"`
+
+	// Doc is the parameter's docstring.
+	Doc string `json:"doc,omitempty"`
+}
+
+// MarshalJSON implements [json.Marshaler].
+// It produces a structured description of the help that was requested:
+// either the name and description of each available subcommand,
+// or, when help for a specific subcommand was requested,
+// that subcommand's name, description, usage line, and parameters
+// (with their types, defaults, and docs).
+// See [HelpInfo].
+//
+// This is what Detail produces
+// when the help pseudo-subcommand is given the -json flag,
+// as in "prog help -json" or "prog help -json subcmd".
+func (e *HelpRequestedErr) MarshalJSON() ([]byte, error) {
+	info, err := e.info()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(info)
+}
+
+// info produces the HelpInfo for e.
+func (e *HelpRequestedErr) info() (HelpInfo, error) {
+	if e.name == "" {
+		// foo bar help
+		return HelpInfo{Subcmds: subcmdsInfo(listSubcmds(e.cmd, e.subcmds), e.all)}, nil
+	}
+
+	// foo bar help baz
+	subcmd, ok := lookupSubcmd(e.cmd, e.subcmds, e.name)
+	if !ok {
+		return HelpInfo{}, e.unknown()
+	}
+	usage, err := e.usageLine()
+	if err != nil {
+		return HelpInfo{}, err
+	}
+	info := HelpInfo{
+		Name:       e.name,
+		Desc:       subcmd.Desc,
+		Deprecated: subcmd.Deprecated,
+		Usage:      usage,
+		Examples:   subcmd.Examples,
+	}
+	info.Params = helpParams(subcmd.Params)
+	if subcmd.Sub != nil {
+		info.Subcmds = subcmdsInfo(subcmd.Sub.Subcmds(), e.all)
+	}
+	return info, nil
+}
+
+// helpParams describes params.
+func helpParams(params []Param) []HelpParam {
+	var result []HelpParam
+	for _, p := range params {
+		result = append(result, HelpParam{
+			Name:     strings.TrimSuffix(strings.TrimLeft(p.Name, "-"), "?"),
+			Type:     p.Type.String(),
+			Flag:     strings.HasPrefix(p.Name, "-"),
+			Optional: strings.HasSuffix(p.Name, "?"),
+			Required: p.Required && strings.HasPrefix(p.Name, "-"),
+			Env:      p.Env,
+			Choices:  p.Choices,
+			Default:  jsonDefault(p),
+			Doc:      p.Doc,
+		})
+	}
+	return result
+}
+
+// subcmdsInfo describes the given subcommands in order,
+// and, if all is true
+// (as when the -all flag is given to the help pseudo-subcommand),
+// their nested subcommands at every depth.
+func subcmdsInfo(subcmds Map, all bool) []HelpInfo {
+	var result []HelpInfo
+	for _, name := range subcmdNames(subcmds) {
+		subcmd := subcmds[name]
+		info := HelpInfo{Name: name, Desc: subcmd.Desc, Deprecated: subcmd.Deprecated}
+		if all && subcmd.Sub != nil {
+			info.Subcmds = subcmdsInfo(subcmd.Sub.Subcmds(), true)
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// jsonDefault converts the default value of p to a form suitable for JSON encoding.
+func jsonDefault(p Param) interface{} {
+	switch p.Type {
+	case Bool:
+		v, _ := p.Default.(bool)
+		return v
+	case Int:
+		return asInt(p.Default)
+	case Int64:
+		return asInt64(p.Default)
+	case Uint:
+		return asUint(p.Default)
+	case Uint64:
+		return asUint64(p.Default)
+	case String, ExistingFile, ExistingDir:
+		v, _ := p.Default.(string)
+		return v
+	case Float64:
+		return asFloat64(p.Default)
+	case Duration:
+		return asDuration(p.Default).String()
+	case Value:
+		if v, ok := p.Default.(flag.Value); ok {
+			return v.String()
+		}
+	case Strings:
+		if v, ok := p.Default.([]string); ok && len(v) > 0 {
+			return v
+		}
+	case Time:
+		if v, ok := p.Default.(time.Time); ok && !v.IsZero() {
+			return v.Format(timeLayouts(p.Layouts)[0])
+		}
+	case JSON:
+		return p.Default
+	case Custom:
+		if p.Default != nil {
+			return fmt.Sprint(p.Default)
+		}
+	case Text:
+		if m, ok := p.Default.(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	case StringMap:
+		if v, ok := p.Default.(map[string]string); ok && len(v) > 0 {
+			return v
+		}
+	}
+	return nil
+}
+
+// detailJSON is the result of Detail for "help -json".
+func (e *HelpRequestedErr) detailJSON() string {
+	j, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("error constructing JSON help: %s", err.Error())
+	}
+	return string(j) + "\n"
+}
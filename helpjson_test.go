@@ -0,0 +1,92 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHelpJSON(t *testing.T) {
+	err := Run(context.Background(), errtestcmd{}, []string{"help", "-json", "a"}, WithProgName("prog"))
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+
+	want := `{
+  "name": "a",
+  "desc": "Do a",
+  "usage": "prog a [-a1] [-a2 int] [-a3 word] a4 [a5]",
+  "params": [
+    {
+      "name": "a1",
+      "type": "bool",
+      "flag": true,
+      "default": false,
+      "doc": "the a1 flag"
+    },
+    {
+      "name": "a2",
+      "type": "int",
+      "flag": true,
+      "default": 0,
+      "doc": "the a2 flag"
+    },
+    {
+      "name": "a3",
+      "type": "string",
+      "flag": true,
+      "default": "",
+      "doc": "a ` + "`word`" + ` flag"
+    },
+    {
+      "name": "a4",
+      "type": "time.Duration",
+      "default": "0s",
+      "doc": "positional duration"
+    },
+    {
+      "name": "a5",
+      "type": "bool",
+      "optional": true,
+      "default": false,
+      "doc": "optional positional bool"
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(want, herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	err = Run(context.Background(), nestedtestcmd{}, []string{"help", "--json"})
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	want = `{
+  "subcmds": [
+    {
+      "name": "a",
+      "desc": "Do a"
+    },
+    {
+      "name": "bb",
+      "desc": "Do b"
+    },
+    {
+      "name": "ccc",
+      "desc": "Do c"
+    },
+    {
+      "name": "db",
+      "desc": "database commands"
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(want, herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,35 @@
+package subcmd
+
+import "context"
+
+// helpPath resolves the leading elements of names
+// as a path through nested commands (see [Subcmd.Sub]),
+// starting at c, whose subcommands are cmds,
+// for "prog help db migrate".
+// It stops before the first name that is not a nested command,
+// or before the last name if that is one
+// (so that "prog help db" describes db itself, as it always has).
+//
+// It returns the context, subcommand path, Cmd, and Map for the level it reached,
+// and the names that remain.
+func helpPath(ctx context.Context, c Cmd, cmds Map, names []string) (context.Context, []subcmdPair, Cmd, Map, []string) {
+	pairs := subcmdPairList(ctx)
+	pairs = pairs[:len(pairs):len(pairs)] // appends below must not clobber the caller's path
+
+	for len(names) > 1 {
+		subcmd, ok := lookupSubcmd(c, cmds, names[0])
+		if !ok || subcmd.F != nil || subcmd.Sub == nil {
+			break
+		}
+		pairs = append(pairs, subcmdPair{name: names[0], subcmd: subcmd})
+		c = subcmd.Sub
+		ctx = withCatalog(ctx, c)
+		cmds = nil
+		if _, ok := cmdAs[Resolver](c); !ok {
+			cmds = c.Subcmds()
+		}
+		names = names[1:]
+	}
+
+	return ctx, pairs, c, cmds, names
+}
@@ -0,0 +1,96 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// HelpTemplater is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// the template returned by HelpTemplate is used to render the help
+// requested with the "help" pseudo-subcommand of that Cmd
+// (see [HelpRequestedErr.Detail]),
+// in preference to any template set with [WithHelpTemplate].
+// If HelpTemplate returns nil,
+// the help is rendered as if the Cmd did not implement this interface.
+type HelpTemplater interface {
+	HelpTemplate() *template.Template
+}
+
+// WithHelpTemplate is an [Option] that causes the help
+// requested with the "help" pseudo-subcommand
+// to be rendered by executing tmpl
+// instead of in the default format.
+// This allows programs to brand their help output,
+// reorder its sections,
+// add footers,
+// and so on.
+//
+// The template is executed with a [HelpData] value.
+// Its Text field contains the default rendering,
+// so a template may simply wrap that with a header or footer.
+//
+// A [Cmd] may supply its own template by implementing [HelpTemplater].
+// Help requested with "help -json" is not affected.
+func WithHelpTemplate(tmpl *template.Template) Option {
+	return func(c *config) error {
+		c.helpTemplate = tmpl
+		return nil
+	}
+}
+
+// HelpData is the value with which a help template is executed.
+// See [WithHelpTemplate].
+// It is also passed to a [DetailFormatter].
+type HelpData struct {
+	HelpInfo
+
+	// Prog is the name of the program.
+	Prog string
+
+	// Path is the sequence of subcommand names leading to the help pseudo-subcommand
+	// (or to the missing or unknown subcommand, for a DetailFormatter).
+	// It is empty at the top level.
+	Path []string
+
+	// Text is the default rendering of the help.
+	Text string
+}
+
+// newHelpData produces the HelpData for info.
+func newHelpData(info HelpInfo, prog string, pairs []subcmdPair, text string) HelpData {
+	data := HelpData{
+		HelpInfo: info,
+		Prog:     prog,
+		Text:     text,
+	}
+	for _, pair := range pairs {
+		data.Path = append(data.Path, pair.name)
+	}
+	return data
+}
+
+// helpTemplate returns the template to use for rendering help for c, if any.
+func helpTemplate(ctx context.Context, c Cmd) *template.Template {
+	if ht, ok := cmdAs[HelpTemplater](c); ok {
+		if tmpl := ht.HelpTemplate(); tmpl != nil {
+			return tmpl
+		}
+	}
+	return getConfig(ctx).helpTemplate
+}
+
+func (e *HelpRequestedErr) detailTemplate() string {
+	info, err := e.info()
+	if err != nil {
+		return err.Error()
+	}
+	data := newHelpData(info, e.prog, e.pairs, e.detailText())
+	buf := new(strings.Builder)
+	if err := e.tmpl.Execute(buf, data); err != nil {
+		return fmt.Sprintf("error rendering help: %s", err.Error())
+	}
+	return buf.String()
+}
@@ -0,0 +1,85 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHelpTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`{{.Prog}} {{.Name}}: {{.Desc}}
+{{range .Params}}{{if .Flag}}-{{end}}{{.Name}} ({{.Type}})
+{{end}}{{.Text}}--
+See the manual.
+`))
+
+	err := Run(context.Background(), errtestcmd{}, []string{"help", "a"}, WithProgName("prog"), WithHelpTemplate(tmpl))
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+
+	detail := herr.Detail()
+	wantPrefix := `prog a: Do a
+-a1 (bool)
+-a2 (int)
+-a3 (string)
+a4 (time.Duration)
+a5 (bool)
+`
+	if !strings.HasPrefix(detail, wantPrefix) {
+		t.Errorf("got %q, want prefix %q", detail, wantPrefix)
+	}
+	if !strings.HasSuffix(detail, "--\nSee the manual.\n") {
+		t.Errorf("got %q, want footer", detail)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		err := Run(context.Background(), errtestcmd{}, []string{"help", "-json", "a"}, WithProgName("prog"), WithHelpTemplate(tmpl))
+		var herr *HelpRequestedErr
+		if !errors.As(err, &herr) {
+			t.Fatalf("got %v, want *HelpRequestedErr", err)
+		}
+		if !strings.HasPrefix(herr.Detail(), "{") {
+			t.Errorf("got %q, want JSON", herr.Detail())
+		}
+	})
+
+	t.Run("exec_error", func(t *testing.T) {
+		bad := template.Must(template.New("").Parse(`{{.Nonesuch}}`))
+		err := Run(context.Background(), errtestcmd{}, []string{"help"}, WithHelpTemplate(bad))
+		var herr *HelpRequestedErr
+		if !errors.As(err, &herr) {
+			t.Fatalf("got %v, want *HelpRequestedErr", err)
+		}
+		if !strings.HasPrefix(herr.Detail(), "error rendering help: ") {
+			t.Errorf("got %q, want rendering error", herr.Detail())
+		}
+	})
+}
+
+type templatedcmd struct {
+	errtestcmd
+}
+
+func (templatedcmd) HelpTemplate() *template.Template {
+	return template.Must(template.New("").Parse(`{{range .Subcmds}}* {{.Name}}
+{{end}}`))
+}
+
+func TestHelpTemplater(t *testing.T) {
+	global := template.Must(template.New("").Parse(`global`))
+
+	err := Run(context.Background(), templatedcmd{}, []string{"help"}, WithHelpTemplate(global))
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if diff := cmp.Diff("* a\n* bb\n* ccc\n", herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,45 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+)
+
+// WithInheritedFlags is an [Option] for use when a subcommand function calls [Run] again,
+// to dispatch to a deeper level of subcommands.
+// It causes the flags of the calling subcommand
+// (found in the context; see [FlagSet])
+// to be accepted by the subcommands of the nested call too,
+// unless they declare flags of the same names.
+// So if "prog -verbose" is a flag of the outer subcommand,
+// it can still be given as "prog outer inner -verbose"
+// without the inner subcommand's redeclaring it.
+//
+// An inherited flag shares its [flag.Value] with the calling subcommand's FlagSet,
+// so its value can be retrieved in the inner subcommand's function
+// (and in the outer one, after the nested Run returns)
+// with [Lookup].
+func WithInheritedFlags() Option {
+	return func(c *config) error {
+		c.inheritFlags = true
+		return nil
+	}
+}
+
+// inheritFlags adds to fs the flags of the FlagSet in ctx,
+// if WithInheritedFlags is in effect,
+// except for those that fs already defines.
+func inheritFlags(ctx context.Context, fs *flag.FlagSet) {
+	if !getConfig(ctx).inheritFlags {
+		return
+	}
+	parent, ok := ctx.Value(fsKey).(*flag.FlagSet)
+	if !ok {
+		return
+	}
+	parent.VisitAll(func(f *flag.Flag) {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInheritedFlags(t *testing.T) {
+	var (
+		innerVerbose, outerVerbose bool
+		level                      int
+	)
+
+	inner := testcmdfunc(func() Map {
+		return Commands(
+			"inner", func(ctx context.Context, n int, _ []string) error {
+				level = n
+				innerVerbose, _ = Lookup[bool](ctx, "verbose")
+				return nil
+			}, "inner command", Params(
+				"-level", Int, 0, "level",
+			),
+		)
+	})
+
+	outer := func(opts ...Option) Cmd {
+		return testcmdfunc(func() Map {
+			return Commands(
+				"outer", func(ctx context.Context, _ bool, level int, args []string) error {
+					if err := Run(ctx, inner, args, opts...); err != nil {
+						return err
+					}
+					outerVerbose, _ = Lookup[bool](ctx, "verbose")
+					return nil
+				}, "outer command", Params(
+					"-verbose", Bool, false, "be verbose",
+					"-level", Int, 1, "level",
+				),
+			)
+		})
+	}
+
+	if err := Run(context.Background(), outer(), []string{"outer", "-level", "2", "inner", "-verbose", "-level", "3"}, WithInheritedFlags()); err != nil {
+		t.Fatal(err)
+	}
+	if !innerVerbose {
+		t.Error("got innerVerbose false, want true")
+	}
+	if !outerVerbose {
+		t.Error("got outerVerbose false, want true")
+	}
+	if level != 3 {
+		t.Errorf("got level %d, want 3", level)
+	}
+
+	err := Run(context.Background(), outer(), []string{"outer", "inner", "-verbose"})
+	var ferr FlagErr
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got %v, want FlagErr without WithInheritedFlags", err)
+	}
+}
@@ -0,0 +1,120 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// WithInstance is an [Option] that makes v available for injection
+// into subcommand functions taking a parameter of type T.
+// See [WithProvider].
+func WithInstance[T any](v T) Option {
+	return WithProvider(func(context.Context) (T, error) { return v, nil })
+}
+
+// WithProvider is an [Option] that registers f as the constructor for values of type T.
+// When [Run] calls a subcommand function that takes a parameter of type T
+// in addition to the parameters described by its Params,
+// f is called to supply it.
+// This allows dependencies such as a *sql.DB or an *http.Client
+// to be passed directly to the functions that need them,
+// instead of being stored in the [Cmd].
+//
+// Injected parameters must come immediately after the initial context.Context parameter,
+// as in:
+//
+//	func(context.Context, *sql.DB, OPTS, []string) error
+//
+// They must be of pointer, interface, map, channel, function, or struct type
+// (so they can't be confused with the types of the Params).
+//
+// The function f is called anew for each subcommand invocation that needs a T.
+// If there is no provider for an injected parameter's type,
+// Run returns a [NoProviderErr].
+func WithProvider[T any](f func(context.Context) (T, error)) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(c *config) error {
+		if !isInjectable(t) {
+			return fmt.Errorf("type %s cannot be injected", t)
+		}
+		if c.providers == nil {
+			c.providers = make(map[reflect.Type]provider)
+		}
+		c.providers[t] = func(ctx context.Context) (reflect.Value, error) {
+			v, err := f(ctx)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		}
+		return nil
+	}
+}
+
+type provider func(context.Context) (reflect.Value, error)
+
+// NoProviderErr is the error when a subcommand function takes an injected parameter
+// whose type has no provider.
+// See [WithProvider].
+type NoProviderErr struct {
+	Type reflect.Type
+}
+
+func (e NoProviderErr) Error() string {
+	return fmt.Sprintf("no provider for type %s", e.Type)
+}
+
+func isInjectable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Chan, reflect.Func, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// numInjected tells how many injected parameters a function of type ft takes,
+// given that it also takes parameters described by params.
+// These are the parameters following the initial context.Context
+// in excess of the ones the params call for,
+// provided they are all of injectable types.
+func numInjected(ft reflect.Type, params []Param) int {
+	if ft.Kind() != reflect.Func {
+		return 0
+	}
+	n := ft.NumIn() - len(params) - 2
+	if n <= 0 {
+		return 0
+	}
+	for i := 1; i <= n; i++ {
+		if !isInjectable(ft.In(i)) {
+			return 0
+		}
+	}
+	return n
+}
+
+// inject returns argvals with values for the n injected parameters of ft
+// inserted after the initial context.
+func inject(ctx context.Context, ft reflect.Type, n int, argvals []reflect.Value) ([]reflect.Value, error) {
+	if n == 0 {
+		return argvals, nil
+	}
+	ctx = argvals[0].Interface().(context.Context)
+	providers := getConfig(ctx).providers
+	result := make([]reflect.Value, 0, len(argvals)+n)
+	result = append(result, argvals[0])
+	for i := 1; i <= n; i++ {
+		t := ft.In(i)
+		p, ok := providers[t]
+		if !ok {
+			return nil, NoProviderErr{Type: t}
+		}
+		v, err := p(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("providing %s: %w", t, err)
+		}
+		result = append(result, v)
+	}
+	return append(result, argvals[1:]...), nil
+}
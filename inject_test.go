@@ -0,0 +1,60 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type injecttestdb struct {
+	name string
+}
+
+func TestInject(t *testing.T) {
+	var (
+		gotDB *injecttestdb
+		gotR  io.Reader
+		gotN  int
+	)
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, db *injecttestdb, r io.Reader, n int, _ []string) {
+			gotDB, gotR, gotN = db, r, n
+		}, "", Params(
+			"-n", Int, 0, "",
+		))
+	})
+
+	var (
+		ctx  = context.Background()
+		db   = &injecttestdb{name: "db"}
+		r    = strings.NewReader("")
+		opts = []Option{
+			WithInstance(db),
+			WithProvider(func(context.Context) (io.Reader, error) { return r, nil }),
+		}
+	)
+
+	if err := Run(ctx, c, []string{"x", "-n", "7"}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if gotDB != db {
+		t.Errorf("got db %v, want %v", gotDB, db)
+	}
+	if gotR != r {
+		t.Errorf("got reader %v, want %v", gotR, r)
+	}
+	if gotN != 7 {
+		t.Errorf("got n %d, want 7", gotN)
+	}
+
+	var e NoProviderErr
+	if err := Run(ctx, c, []string{"x"}, opts[0]); !errors.As(err, &e) {
+		t.Errorf("got %v, want NoProviderErr", err)
+	}
+
+	if err := Run(ctx, c, []string{"x"}, WithInstance(3)); err == nil {
+		t.Error("got no error for non-injectable type")
+	}
+}
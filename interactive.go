@@ -0,0 +1,63 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// WithInteractive is an [Option] that marks a call to [Run] as interactive or non-interactive,
+// overriding the automatic detection performed by [Interactive].
+//
+// Features of this package that would prompt the user,
+// use colors,
+// or invoke a pager,
+// are disabled in non-interactive runs.
+// Subcommand functions can use [Interactive] to do the same.
+func WithInteractive(interactive bool) Option {
+	return func(c *config) error {
+		c.interactive = &interactive
+		return nil
+	}
+}
+
+// Interactive tells whether the current run is interactive.
+// This is the value set with [WithInteractive], if any.
+// Otherwise a run is considered non-interactive if any of the following is true:
+//
+//   - the environment variable CI is set to anything other than "", "0", or "false";
+//   - the environment variable NO_TTY is set to anything other than "", "0", or "false";
+//   - the environment variable TERM is "dumb";
+//   - the standard input or output (see [Stdin] and [Stdout]) is not a terminal.
+func Interactive(ctx context.Context) bool {
+	if p := getConfig(ctx).interactive; p != nil {
+		return *p
+	}
+	if envTrue("CI") || envTrue("NO_TTY") {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(Stdin(ctx)) && isTerminal(Stdout(ctx))
+}
+
+func envTrue(name string) bool {
+	switch strings.ToLower(os.Getenv(name)) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+func isTerminal(x interface{}) bool {
+	f, ok := x.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,52 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestInteractive(t *testing.T) {
+	restoreCI := testSetenv("CI", "")
+	defer restoreCI()
+	restoreNoTTY := testSetenv("NO_TTY", "")
+	defer restoreNoTTY()
+
+	var got bool
+	c := dotenvtestcmd(func(ctx context.Context, _ []string) {
+		got = Interactive(ctx)
+	})
+	ctx := context.Background()
+
+	if err := Run(ctx, c, []string{"x"}, WithInteractive(true)); err != nil {
+		t.Fatal(err)
+	}
+	if !got {
+		t.Error("got non-interactive with WithInteractive(true)")
+	}
+
+	// Stdout is not a terminal.
+	if err := Run(ctx, c, []string{"x"}, WithStdio(nil, new(bytes.Buffer), nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got {
+		t.Error("got interactive with non-terminal stdout")
+	}
+
+	restoreCI2 := testSetenv("CI", "true")
+	defer restoreCI2()
+
+	if err := Run(ctx, c, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got {
+		t.Error("got interactive with CI=true")
+	}
+
+	if err := Run(ctx, c, []string{"x"}, WithInteractive(true)); err != nil {
+		t.Fatal(err)
+	}
+	if !got {
+		t.Error("WithInteractive(true) did not override CI=true")
+	}
+}
@@ -0,0 +1,96 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// jsonValue is the [flag.Value] for a parameter of type [JSON].
+type jsonValue struct {
+	// holder is a pointer to the pointer that is passed to the subcommand's function.
+	holder reflect.Value
+}
+
+// newJSONValue produces a jsonValue for p
+// whose initial value is a copy of p's default.
+func newJSONValue(p Param) (*jsonValue, error) {
+	dflt := reflect.ValueOf(p.Default)
+	if dflt.Kind() != reflect.Pointer || dflt.IsNil() {
+		return nil, fmt.Errorf("param %s has type JSON but default value %v is not a non-nil pointer", p.Name, p.Default)
+	}
+	holder := reflect.New(dflt.Type())
+	holder.Elem().Set(copyPointee(dflt))
+	return &jsonValue{holder: holder}, nil
+}
+
+func (v *jsonValue) String() string {
+	if v == nil || !v.holder.IsValid() {
+		return ""
+	}
+	j, err := json.Marshal(v.holder.Elem().Interface())
+	if err != nil {
+		return ""
+	}
+	return string(j)
+}
+
+func (v *jsonValue) Set(s string) error {
+	ptr, err := unmarshalJSONArg(v.holder.Elem(), s)
+	if err != nil {
+		return err
+	}
+	v.holder.Elem().Set(ptr)
+	return nil
+}
+
+// Get implements [flag.Getter].
+// The result is the pointer that is passed to the subcommand's function.
+func (v *jsonValue) Get() interface{} {
+	return v.holder.Elem().Interface()
+}
+
+// copyPointee returns a pointer to a (shallow) copy of what ptr points to.
+func copyPointee(ptr reflect.Value) reflect.Value {
+	result := reflect.New(ptr.Type().Elem())
+	result.Elem().Set(ptr.Elem())
+	return result
+}
+
+// unmarshalJSONArg unmarshals arg,
+// which is JSON or "@" followed by the name of a file containing JSON,
+// into a copy of what dflt points to,
+// returning a pointer to the copy.
+func unmarshalJSONArg(dflt reflect.Value, arg string) (reflect.Value, error) {
+	data := []byte(arg)
+	if filename, ok := strings.CutPrefix(arg, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(filename); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	result := copyPointee(dflt)
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return result, nil
+}
+
+func parseJSONPos(args *[]string, argvals *[]reflect.Value, p Param) error {
+	dflt := reflect.ValueOf(p.Default)
+	if dflt.Kind() != reflect.Pointer || dflt.IsNil() {
+		return ParseErr{Err: fmt.Errorf("param %s has type JSON but default value %v is not a non-nil pointer", p.Name, p.Default)}
+	}
+	val := copyPointee(dflt)
+	if len(*args) > 0 {
+		var err error
+		if val, err = unmarshalJSONArg(dflt, (*args)[0]); err != nil {
+			return parseErr(p, (*args)[0], err)
+		}
+		*args = (*args)[1:]
+	}
+	*argvals = append(*argvals, val)
+	return nil
+}
@@ -0,0 +1,67 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type jsontestpayload struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestJSONParam(t *testing.T) {
+	dflt := &jsontestpayload{Name: "default", Count: 1}
+
+	var gotOpts, gotBody *jsontestpayload
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, opts, body *jsontestpayload, _ []string) { gotOpts, gotBody = opts, body }, "", Params(
+			"-opts", JSON, dflt, "",
+			"body?", JSON, &jsontestpayload{}, "",
+		))
+	})
+
+	path := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(path, []byte(`{"name": "from file", "count": 7}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(context.Background(), c, []string{"x", "-opts", `{"count": 3}`, "@" + path}); err != nil {
+		t.Fatal(err)
+	}
+	if *gotOpts != (jsontestpayload{Name: "default", Count: 3}) {
+		t.Errorf("got opts %+v, want default name with count 3", *gotOpts)
+	}
+	if *gotBody != (jsontestpayload{Name: "from file", Count: 7}) {
+		t.Errorf("got body %+v, want contents of file", *gotBody)
+	}
+	if *dflt != (jsontestpayload{Name: "default", Count: 1}) {
+		t.Errorf("default value was modified: %+v", *dflt)
+	}
+
+	if err := Run(context.Background(), c, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotOpts == dflt || *gotOpts != *dflt {
+		t.Errorf("got opts %+v, want a copy of the default", *gotOpts)
+	}
+
+	var f FlagErr
+	if err := Run(context.Background(), c, []string{"x", "-opts", "{"}); !errors.As(err, &f) {
+		t.Errorf("got %v, want FlagErr", err)
+	}
+	var p ParseErr
+	if err := Run(context.Background(), c, []string{"x", "@" + filepath.Join(t.TempDir(), "nope")}); !errors.As(err, &p) {
+		t.Errorf("got %v, want ParseErr", err)
+	}
+
+	if err := Check(Subcmd{F: func(context.Context, *jsontestpayload, []string) {}, Params: Params("-opts", JSON, jsontestpayload{}, "")}); err == nil {
+		t.Error("got no error for non-pointer JSON default")
+	}
+	if err := Check(Subcmd{F: func(context.Context, *int, []string) {}, Params: Params("-opts", JSON, dflt, "")}); err == nil {
+		t.Error("got no error for mismatched JSON type")
+	}
+}
@@ -0,0 +1,34 @@
+package subcmd
+
+import "context"
+
+// Initer is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// [Run] calls Init after finding the subcommand named in its args
+// and before running it.
+// This is the place to do setup needed by all of c's subcommands,
+// such as opening a database connection.
+// The context passed to Init includes the [CommandPath].
+//
+// If Init returns an error,
+// Run returns that error without running the subcommand
+// (and without calling Close; see [Closer]).
+//
+// Init is not called for the "help" pseudo-subcommand,
+// nor when the subcommand is missing or unknown.
+type Initer interface {
+	Init(context.Context) error
+}
+
+// Closer is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// [Run] calls Close after running the subcommand named in its args,
+// whether or not the subcommand succeeded.
+// This is the place to do teardown,
+// such as closing a database connection or flushing telemetry.
+//
+// An error from Close is combined with any error from the subcommand
+// (see [errors.Join]).
+type Closer interface {
+	Close(context.Context) error
+}
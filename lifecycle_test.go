@@ -0,0 +1,70 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLifecycle(t *testing.T) {
+	var (
+		calls    []string
+		initErr  error
+		closeErr = errors.New("close failed")
+		runErr   = errors.New("run failed")
+	)
+	c := &lifecycletestcmd{
+		calls: &calls,
+		f: func(context.Context, []string) error {
+			calls = append(calls, "run")
+			return runErr
+		},
+		initErr:  &initErr,
+		closeErr: closeErr,
+	}
+
+	err := Run(context.Background(), c, []string{"x"})
+	if !errors.Is(err, runErr) || !errors.Is(err, closeErr) {
+		t.Errorf("got %v, want both %v and %v", err, runErr, closeErr)
+	}
+	if want := []string{"init", "run", "close"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	calls = nil
+	initErr = errors.New("init failed")
+	if err := Run(context.Background(), c, []string{"x"}); !errors.Is(err, initErr) {
+		t.Errorf("got %v, want %v", err, initErr)
+	}
+	if want := []string{"init"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	calls = nil
+	_ = Run(context.Background(), c, []string{"help"})
+	if len(calls) != 0 {
+		t.Errorf("got calls %v for help, want none", calls)
+	}
+}
+
+type lifecycletestcmd struct {
+	calls    *[]string
+	f        func(context.Context, []string) error
+	initErr  *error
+	closeErr error
+}
+
+func (c *lifecycletestcmd) Subcmds() Map {
+	return Commands("x", c.f, "", nil)
+}
+
+func (c *lifecycletestcmd) Init(context.Context) error {
+	*c.calls = append(*c.calls, "init")
+	return *c.initErr
+}
+
+func (c *lifecycletestcmd) Close(context.Context) error {
+	*c.calls = append(*c.calls, "close")
+	return c.closeErr
+}
@@ -0,0 +1,142 @@
+package subcmd
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// WithLogger is an [Option] that supplies a logger to [Run].
+// Run logs its dispatch decisions and the parsed values of subcommand parameters to it at Debug level.
+// Values of parameters whose names suggest they hold secrets
+// are logged as "REDACTED".
+// Those are names with a word
+// (delimited by "-", "_", ".", or a lower-to-upper case change)
+// that is "password", "passwd", "secret", "token", or "credential" (or the plural of one),
+// or "apikey",
+// and names with the word "key" right after "api", "access", "private", "signing", or "encryption"
+// (as in "-api-key" and "-privateKey")
+// or consisting of "key" alone.
+//
+// Subcommand functions can obtain the logger with [Logger].
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// Logger returns the logger supplied with [WithLogger],
+// or [slog.Default] if there isn't one.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger := getConfig(ctx).logger; logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// logDebug logs to the logger supplied with WithLogger, if there is one.
+func logDebug(ctx context.Context, msg string, args ...interface{}) {
+	if logger := getConfig(ctx).logger; logger != nil {
+		logger.DebugContext(ctx, "subcmd: "+msg, args...)
+	}
+}
+
+// logParsed logs the values that parseArgs produced for params.
+func logParsed(ctx context.Context, name string, params []Param, argvals []reflect.Value, variadic bool) {
+	logger := getConfig(ctx).logger
+	if logger == nil || !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	// parseArgs produces the flag values first, then the positional ones.
+	var ordered []Param
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			ordered = append(ordered, p)
+		}
+	}
+	for _, p := range params {
+		if !strings.HasPrefix(p.Name, "-") {
+			ordered = append(ordered, p)
+		}
+	}
+
+	attrs := []interface{}{"name", name}
+	for i, p := range ordered {
+		val := argvals[i+1].Interface()
+		if isSecretName(p.Name) {
+			val = "REDACTED"
+		}
+		attrs = append(attrs, slog.Any(strings.TrimLeft(p.Name, "-"), val))
+	}
+
+	rest := argvals[len(ordered)+1:]
+	if variadic {
+		var strs []string
+		for _, v := range rest {
+			strs = append(strs, v.String())
+		}
+		attrs = append(attrs, "args", strs)
+	} else if len(rest) > 0 {
+		attrs = append(attrs, "args", rest[0].Interface())
+	}
+
+	logger.DebugContext(ctx, "subcmd: parsed arguments", attrs...)
+}
+
+// isSecretName tells whether the parameter name suggests a secret value.
+// See WithLogger.
+func isSecretName(name string) bool {
+	words := nameWords(strings.TrimSuffix(strings.TrimLeft(name, "-"), "?"))
+	for i, w := range words {
+		switch strings.TrimSuffix(w, "s") {
+		case "password", "passwd", "secret", "token", "credential", "apikey":
+			return true
+		case "key":
+			if len(words) == 1 {
+				return true
+			}
+			if i > 0 {
+				switch words[i-1] {
+				case "api", "access", "private", "signing", "encryption":
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// nameWords splits a parameter name into lowercase words
+// at "-", "_", and "." and at lower-to-upper case changes,
+// so "api-key", "api_key", and "apiKey" all produce ["api", "key"].
+func nameWords(name string) []string {
+	var (
+		words []string
+		cur   []rune
+		prev  rune
+	)
+	for _, r := range name {
+		switch {
+		case r == '-' || r == '_' || r == '.':
+			if len(cur) > 0 {
+				words = append(words, strings.ToLower(string(cur)))
+				cur = nil
+			}
+			prev = r
+			continue
+		case unicode.IsUpper(r) && unicode.IsLower(prev) && len(cur) > 0:
+			words = append(words, strings.ToLower(string(cur)))
+			cur = nil
+		}
+		cur = append(cur, r)
+		prev = r
+	}
+	if len(cur) > 0 {
+		words = append(words, strings.ToLower(string(cur)))
+	}
+	return words
+}
@@ -0,0 +1,76 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		got    *slog.Logger
+	)
+
+	c := logtestcmd(func(ctx context.Context, _ string, _ int, _ []string) {
+		got = Logger(ctx)
+	})
+	if err := Run(context.Background(), c, []string{"x", "-api-token", "hunter2", "17", "rest"}, WithLogger(logger)); err != nil {
+		t.Fatal(err)
+	}
+	if got != logger {
+		t.Error("Logger did not return the logger from WithLogger")
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="subcmd: dispatching" name=x`,
+		`msg="subcmd: parsed arguments" name=x api-token=REDACTED n=17 args=[rest]`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output does not contain %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("log output contains secret:\n%s", out)
+	}
+}
+
+type logtestcmd func(context.Context, string, int, []string)
+
+func (c logtestcmd) Subcmds() Map {
+	return Commands("x", (func(context.Context, string, int, []string))(c), "", Params(
+		"-api-token", String, "", "API token",
+		"n", Int, 0, "a number",
+	))
+}
+
+func TestIsSecretName(t *testing.T) {
+	cases := map[string]bool{
+		"-password":      true,
+		"-db-passwd":     true,
+		"-api-token":     true,
+		"client_secret":  true,
+		"-credentials":   true,
+		"-key":           true,
+		"-api-key":       true,
+		"-privateKey":    true,
+		"-APIKey":        true,
+		"-apikey":        true,
+		"access_key?":    true,
+		"-monkey":        false,
+		"-sort-key":      false,
+		"-keyboard":      false,
+		"-tokenizer":     false,
+		"-verbose":       false,
+		"secretary-name": false,
+	}
+	for name, want := range cases {
+		if got := isSecretName(name); got != want {
+			t.Errorf("isSecretName(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
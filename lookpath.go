@@ -0,0 +1,104 @@
+//go:build !js && !wasip1
+
+package subcmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// lookPathCache holds the results of exec.LookPath for plugin executables (see Prefixer),
+// so that programs that dispatch many times,
+// such as REPLs and daemons,
+// don't have to search $PATH every time.
+var lookPathCache = struct {
+	mu      sync.Mutex
+	entries map[string]lookPathEntry
+}{entries: make(map[string]lookPathEntry)}
+
+type lookPathEntry struct {
+	pathEnv string
+	pathExt string // the value of $PATHEXT, which on Windows lists the extensions of executable files
+	path    string // "" if not found
+	mtimes  map[string]time.Time
+}
+
+// cachedLookPath is like exec.LookPath,
+// but reuses earlier results if $PATH (and on Windows, $PATHEXT) has not changed
+// and neither have the modification times of the directories in it
+// (which change when files are added or removed)
+// or of the executable that was found.
+func cachedLookPath(name string) (string, error) {
+	var (
+		pathEnv = os.Getenv("PATH")
+		pathExt = os.Getenv("PATHEXT")
+	)
+
+	lookPathCache.mu.Lock()
+	entry, ok := lookPathCache.entries[name]
+	lookPathCache.mu.Unlock()
+
+	if ok && entry.pathEnv == pathEnv && entry.pathExt == pathExt && mtimesMatch(entry.mtimes) {
+		if entry.path == "" {
+			return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+		return entry.path, nil
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil && !errors.Is(err, exec.ErrNotFound) {
+		return "", err
+	}
+
+	entry = lookPathEntry{
+		pathEnv: pathEnv,
+		pathExt: pathExt,
+		path:    path,
+		mtimes:  pathMtimes(pathEnv, path),
+	}
+	lookPathCache.mu.Lock()
+	lookPathCache.entries[name] = entry
+	lookPathCache.mu.Unlock()
+
+	return path, err
+}
+
+// pathMtimes returns the modification times of the directories in pathEnv
+// and of the file at path (if it is not "").
+func pathMtimes(pathEnv, path string) map[string]time.Time {
+	result := make(map[string]time.Time)
+	stamp := func(name string) {
+		if info, err := os.Stat(name); err == nil {
+			result[name] = info.ModTime()
+		} else {
+			result[name] = time.Time{}
+		}
+	}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			dir = "."
+		}
+		stamp(dir)
+	}
+	if path != "" {
+		stamp(path)
+	}
+	return result
+}
+
+func mtimesMatch(mtimes map[string]time.Time) bool {
+	for name, mtime := range mtimes {
+		var got time.Time
+		if info, err := os.Stat(name); err == nil {
+			got = info.ModTime()
+		}
+		if !got.Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+//go:build !js && !wasip1
+
+package subcmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCachedLookPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses Unix executable permissions")
+	}
+
+	dir := t.TempDir()
+	defer testSetenv("PATH", dir)()
+
+	const name = "subcmd-lookpath-test"
+
+	if _, err := cachedLookPath(name); !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("got %v, want %v", err, exec.ErrNotFound)
+	}
+
+	// Adding a file changes the directory's modification time,
+	// invalidating the cached negative result.
+	// Make sure the change is detectable even with coarse timestamps.
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(dir, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cachedLookPath(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != path {
+		t.Errorf(`got "%s", want "%s"`, got, path)
+	}
+
+	// A cached result.
+	got, err = cachedLookPath(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != path {
+		t.Errorf(`got "%s", want "%s"`, got, path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(dir, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedLookPath(name); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("after removal, got %v, want %v", err, exec.ErrNotFound)
+	}
+}
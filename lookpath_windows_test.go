@@ -0,0 +1,27 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrefixPathExt(t *testing.T) {
+	dir := t.TempDir()
+	defer testSetenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))()
+	defer testSetenv("PATHEXT", ".COM;.EXE;.BAT;.CMD")()
+
+	if err := os.WriteFile(filepath.Join(dir, "foo-winplugin.bat"), []byte("@echo off\r\necho plugin %1\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(strings.Builder)
+	if err := Run(context.Background(), testPrefixMainCmd{}, []string{"winplugin", "x"}, WithStdio(nil, out, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "plugin x" {
+		t.Errorf(`got "%s", want "plugin x"`, got)
+	}
+}
@@ -0,0 +1,130 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// ExitCoder is an interface that errors may implement
+// to specify the exit status of the process.
+// If a subcommand function returns an error that is or wraps an ExitCoder,
+// [Main] uses its ExitCode as the exit status,
+// and callers of [Run] can do the same with [errors.As].
+//
+// The *exec.ExitError returned from a failed plugin subprocess (see [Prefixer])
+// is an ExitCoder,
+// so Main exits with the same status as the plugin.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// Main runs c with [Run] and then exits the process,
+// taking care of the error reporting that a typical main function would otherwise have to do itself.
+// It does not return.
+//
+// The exit status is:
+//
+//   - 0 if Run succeeds,
+//     or if help was requested
+//     (with the "help" pseudo-subcommand or with a -h or -help flag);
+//   - 2 for a usage error,
+//     such as a missing or unknown subcommand,
+//     a bad flag,
+//     or a bad or missing positional argument;
+//   - the value of ExitCode for an error that is or wraps an [ExitCoder];
+//   - 1 for any other error.
+//
+// Help requested with the "help" pseudo-subcommand is written to [Stdout].
+// For usage errors (see [UsageErr]),
+// the error's Detail is written to [Stderr].
+// Other errors are written to Stderr on a single line,
+// prefixed with the program name (see [ProgName]).
+// Errors from parsing flags are not written,
+// since the [flag] package has already reported them.
+//
+// Typical usage is:
+//
+//	func main() {
+//		subcmd.Main(context.Background(), command{}, os.Args[1:])
+//	}
+func Main(ctx context.Context, c Cmd, args []string, opts ...Option) {
+	os.Exit(mainStatus(ctx, c, args, opts))
+}
+
+// mainStatus is the implementation of Main.
+// It returns the process exit status.
+func mainStatus(ctx context.Context, c Cmd, args []string, opts []Option) int {
+	ctx, err := withOptions(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(Stderr(ctx), "%s: applying options: %s\n", ProgName(ctx), err)
+		return 1
+	}
+
+	err = Run(ctx, c, args)
+	if err == nil {
+		return 0
+	}
+
+	var (
+		herr  *HelpRequestedErr
+		ferr  FlagErr
+		usage UsageErr
+	)
+	switch {
+	case errors.As(err, &herr):
+		fmt.Fprint(Stdout(ctx), herr.Detail())
+
+	case errors.Is(err, flag.ErrHelp), errors.As(err, &ferr):
+		// Nothing to write.
+
+	case errors.As(err, &usage):
+		fmt.Fprint(Stderr(ctx), usage.Detail())
+
+	default:
+		fmt.Fprintf(Stderr(ctx), "%s: %s\n", ProgName(ctx), err)
+	}
+
+	return ExitStatus(err)
+}
+
+// ExitStatus is the process exit status that [Main] uses
+// for err, the result of a call to [Run].
+// See Main for details.
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var (
+		herr *HelpRequestedErr
+		ec   ExitCoder
+	)
+	switch {
+	case errors.As(err, &herr), errors.Is(err, flag.ErrHelp):
+		return 0
+
+	case isUsageErr(err):
+		return 2
+
+	case errors.As(err, &ec):
+		return ec.ExitCode()
+	}
+
+	return 1
+}
+
+// isUsageErr tells whether err reports a problem with the command line,
+// such as a missing or unknown subcommand,
+// a bad flag,
+// or a bad or missing positional argument.
+func isUsageErr(err error) bool {
+	var (
+		ferr  FlagErr
+		perr  ParseErr
+		usage UsageErr
+	)
+	return errors.As(err, &ferr) || errors.As(err, &usage) || errors.As(err, &perr) || errors.Is(err, ErrTooFewArgs)
+}
@@ -0,0 +1,86 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMainStatus(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"ok", func(context.Context, []string) error { return nil }, "succeed", nil,
+			"fail", func(context.Context, []string) error { return errors.New("boom") }, "fail", nil,
+			"coded", func(context.Context, []string) error { return fmt.Errorf("wrapped: %w", exitCodeErr(3)) }, "fail with a status", nil,
+			"num", func(context.Context, int, []string) error { return nil }, "take a number", Params(
+				"n", Int, 0, "a number",
+			),
+		)
+	})
+
+	cases := []struct {
+		args       []string
+		wantStatus int
+		wantStdout string
+		wantStderr string
+	}{{
+		args: []string{"ok"},
+	}, {
+		args:       []string{"fail"},
+		wantStatus: 1,
+		wantStderr: "prog: running fail: boom\n",
+	}, {
+		args:       []string{"coded"},
+		wantStatus: 3,
+		wantStderr: "prog: running coded: wrapped: status 3\n",
+	}, {
+		args:       []string{"help"},
+		wantStdout: "Subcommands are:",
+	}, {
+		args:       []string{},
+		wantStatus: 2,
+		wantStderr: "Missing subcommand, want one of:",
+	}, {
+		args:       []string{"xyz"},
+		wantStatus: 2,
+		wantStderr: `Unknown subcommand "xyz"`,
+	}, {
+		args:       []string{"num", "x"},
+		wantStatus: 2,
+		wantStderr: `prog: marshaling args: argument 'n': cannot parse "x" as int`,
+	}, {
+		args:       []string{"num"},
+		wantStatus: 2,
+		wantStderr: "prog: marshaling args: too few arguments",
+	}}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, "_"), func(t *testing.T) {
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			status := mainStatus(context.Background(), c, tc.args, []Option{WithProgName("prog"), WithStdio(nil, stdout, stderr)})
+			if status != tc.wantStatus {
+				t.Errorf("got status %d, want %d", status, tc.wantStatus)
+			}
+			if !strings.HasPrefix(stdout.String(), tc.wantStdout) {
+				t.Errorf("got stdout %q, want prefix %q", stdout, tc.wantStdout)
+			}
+			if !strings.HasPrefix(stderr.String(), tc.wantStderr) {
+				t.Errorf("got stderr %q, want prefix %q", stderr, tc.wantStderr)
+			}
+			if tc.wantStdout == "" && stdout.Len() > 0 {
+				t.Errorf("got unexpected stdout %q", stdout)
+			}
+			if tc.wantStderr == "" && stderr.Len() > 0 {
+				t.Errorf("got unexpected stderr %q", stderr)
+			}
+		})
+	}
+}
+
+type exitCodeErr int
+
+func (e exitCodeErr) Error() string { return fmt.Sprintf("status %d", int(e)) }
+func (e exitCodeErr) ExitCode() int { return int(e) }
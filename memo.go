@@ -0,0 +1,94 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Memoize wraps c in a [Cmd] whose Subcmds method calls c.Subcmds only once,
+// returning the same [Map] on every subsequent call.
+// Use it when building c's Map is expensive
+// and the same Cmd is used for many calls to [Run],
+// or walked by functions like [Docopt].
+//
+// The Map must not be modified by its callers.
+//
+// [Run] and the other functions in this package
+// see through the result to the optional interfaces that c implements
+// (such as [Resolver], [Initer], and [PersistentFlagger]),
+// so memoizing c does not change its behavior.
+// Other code that checks for those interfaces
+// will not find them on the result,
+// with the exception of [Prefixer]:
+// if c is a Prefixer,
+// so is the result.
+// The result marshals to JSON the same way c does.
+func Memoize(c Cmd) Cmd {
+	m := &memoCmd{cmd: c}
+	if p, ok := c.(Prefixer); ok {
+		return &memoPrefixer{memoCmd: m, p: p}
+	}
+	return m
+}
+
+type memoCmd struct {
+	cmd     Cmd
+	once    sync.Once
+	subcmds Map
+}
+
+func (m *memoCmd) Subcmds() Map {
+	m.once.Do(func() {
+		m.subcmds = m.cmd.Subcmds()
+	})
+	return m.subcmds
+}
+
+func (m *memoCmd) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.cmd)
+}
+
+type memoPrefixer struct {
+	*memoCmd
+	p Prefixer
+}
+
+func (m *memoPrefixer) Prefix() string {
+	return m.p.Prefix()
+}
+
+// unwrapCmd returns the Cmd wrapped by c,
+// if c was produced by Memoize or [Compile],
+// and otherwise c itself.
+func unwrapCmd(c interface{}) interface{} {
+	switch m := c.(type) {
+	case *memoCmd:
+		return unwrapCmd(m.cmd)
+	case *memoPrefixer:
+		return unwrapCmd(m.cmd)
+	case *Program:
+		return unwrapCmd(m.cmd)
+	}
+	return c
+}
+
+// cmdAs converts c to T,
+// which is one of the optional interfaces that a [Cmd] can implement,
+// looking through the wrappers produced by Memoize and [Compile] if necessary.
+// It takes an interface{} rather than a Cmd
+// so that it also works on the values passed to [ParseEnv] and friends.
+func cmdAs[T any](c interface{}) (T, bool) {
+	if t, ok := c.(T); ok {
+		return t, true
+	}
+	switch m := c.(type) {
+	case *memoCmd:
+		return cmdAs[T](m.cmd)
+	case *memoPrefixer:
+		return cmdAs[T](m.cmd)
+	case *Program:
+		return cmdAs[T](m.cmd)
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,114 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMemoize(t *testing.T) {
+	c := &countingcmd{}
+
+	err := Run(context.Background(), c, []string{"bogus"})
+	var u UsageErr
+	if !errors.As(err, &u) {
+		t.Fatalf("got %v, want UsageErr", err)
+	}
+	_, _ = u.Error(), u.Detail()
+	if c.n != 1 {
+		t.Errorf("Run called Subcmds %d times, want 1", c.n)
+	}
+
+	m := Memoize(c)
+	for i := 0; i < 3; i++ {
+		if err := Run(context.Background(), m, []string{"bb"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.n != 2 {
+		t.Errorf("Subcmds called %d times, want 2", c.n)
+	}
+
+	if _, ok := m.(Prefixer); ok {
+		t.Error("memoized non-Prefixer is a Prefixer")
+	}
+	mp := Memoize(testPrefixMainCmd{Data: "xyz"})
+	p, ok := mp.(Prefixer)
+	if !ok {
+		t.Fatal("memoized Prefixer is not a Prefixer")
+	}
+	if got := p.Prefix(); got != "foo-" {
+		t.Errorf(`got prefix "%s", want "foo-"`, got)
+	}
+	j, err := json.Marshal(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(j), `{"data":"xyz"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+type countingcmd struct {
+	n int
+}
+
+func (c *countingcmd) Subcmds() Map {
+	c.n++
+	return errtestcmd{}.Subcmds()
+}
+
+type memoOptionalCmd struct {
+	inited bool
+	got    string
+}
+
+func (c *memoOptionalCmd) Subcmds() Map {
+	return Commands("new", func(ctx context.Context, _ []string) {
+		c.got, _ = Persistent[string](ctx, "who")
+	}, "", nil)
+}
+
+func (c *memoOptionalCmd) Renames() map[string]string {
+	return map[string]string{"old": "new"}
+}
+
+func (c *memoOptionalCmd) Init(context.Context) error {
+	c.inited = true
+	return nil
+}
+
+func (c *memoOptionalCmd) PersistentFlags() []Param {
+	return Params("-who", String, "", "")
+}
+
+func TestMemoizeOptionalInterfaces(t *testing.T) {
+	c := &memoOptionalCmd{}
+	if err := Run(context.Background(), Memoize(c), []string{"-who", "me", "old"}, WithWarningHandler(func(context.Context, string) {})); err != nil {
+		t.Fatal(err)
+	}
+	if !c.inited {
+		t.Error("Init was not called")
+	}
+	if c.got != "me" {
+		t.Errorf(`got persistent flag "%s", want "me"`, c.got)
+	}
+}
+
+func TestProgramOptionalInterfaces(t *testing.T) {
+	c := &memoOptionalCmd{}
+	p, err := Compile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(context.Background(), p, []string{"-who", "me", "old"}, WithWarningHandler(func(context.Context, string) {})); err != nil {
+		t.Fatal(err)
+	}
+	if !c.inited {
+		t.Error("Init was not called")
+	}
+	if c.got != "me" {
+		t.Errorf(`got persistent flag "%s", want "me"`, c.got)
+	}
+}
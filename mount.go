@@ -0,0 +1,28 @@
+package subcmd
+
+// Mount produces a subcommand name and [Subcmd]
+// that attach sub under name in another [Cmd]'s [Map].
+// This lets a library that exposes its own Cmd
+// be made part of a host program's command line,
+// as in "prog name subsubcmd ...".
+//
+// The resulting Subcmd has a nil F and its Sub field set to sub
+// (so [Run] dispatches to sub directly).
+// If sub has a method Desc() string,
+// it is used for the Subcmd's Desc.
+//
+// Example:
+//
+//	func (c command) Subcmds() subcmd.Map {
+//	  m := subcmd.Commands(...)
+//	  name, s := subcmd.Mount("lib", lib.Cmd())
+//	  m[name] = s
+//	  return m
+//	}
+func Mount(name string, sub Cmd) (string, Subcmd) {
+	s := Subcmd{Sub: sub}
+	if d, ok := sub.(interface{ Desc() string }); ok {
+		s.Desc = d.Desc()
+	}
+	return name, s
+}
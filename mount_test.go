@@ -0,0 +1,41 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	var got []string
+	lib := mounttestlib(func(ctx context.Context, _ []string) {
+		got = CommandPath(ctx)
+	})
+
+	c := testcmdfunc(func() Map {
+		m := errtestcmd{}.Subcmds()
+		name, s := Mount("lib", lib)
+		m[name] = s
+		return m
+	})
+
+	if err := CheckMap(c.Subcmds()); err != nil {
+		t.Fatal(err)
+	}
+	if desc := c.Subcmds()["lib"].Desc; desc != "the library" {
+		t.Errorf(`got desc "%s", want "the library"`, desc)
+	}
+	if err := Run(context.Background(), c, []string{"lib", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "lib" || got[1] != "x" {
+		t.Errorf("got path %v, want [lib x]", got)
+	}
+}
+
+type mounttestlib func(context.Context, []string)
+
+func (l mounttestlib) Subcmds() Map {
+	return Commands("x", (func(context.Context, []string))(l), "", nil)
+}
+
+func (mounttestlib) Desc() string { return "the library" }
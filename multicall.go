@@ -0,0 +1,56 @@
+package subcmd
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// MultiCall maps entry-point names to top-level [Cmd]s,
+// for a "multi-call" binary:
+// one executable that behaves as several different programs
+// depending on the name it's invoked as
+// (typically via symlinks or hard links),
+// in the style of busybox.
+//
+// A MultiCall is itself a Cmd,
+// with one subcommand (see Subcmd.Sub) for each of its entries.
+type MultiCall map[string]Cmd
+
+// Subcmds implements [Cmd].
+func (m MultiCall) Subcmds() Map {
+	result := make(Map, len(m))
+	for name, c := range m {
+		result[name] = Subcmd{Sub: c}
+	}
+	return result
+}
+
+// Run runs a multi-call binary.
+// Unlike the other Run functions in this package,
+// args must include the program name,
+// as in os.Args.
+//
+// If the base name of args[0] is one of m's entry-point names,
+// the corresponding Cmd is run with the remaining args,
+// and with the entry-point name as the program name in usage messages
+// (see [WithProgName]).
+// Otherwise args[1] is taken to be the entry-point name
+// (as in "busybox ls").
+func (m MultiCall) Run(ctx context.Context, args []string, opts ...Option) error {
+	if len(args) == 0 {
+		return Run(ctx, m, nil, opts...)
+	}
+
+	base := filepath.Base(args[0])
+	if runtime.GOOS == "windows" {
+		base = strings.TrimSuffix(base, ".exe")
+	}
+	opts = append([]Option{WithProgName(base)}, opts...)
+
+	if c, ok := m[base]; ok {
+		return Run(ctx, c, args[1:], opts...)
+	}
+	return Run(ctx, m, args[1:], opts...)
+}
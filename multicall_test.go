@@ -0,0 +1,45 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMultiCall(t *testing.T) {
+	var got string
+	m := MultiCall{
+		"foo": dotenvtestcmd(func(context.Context, []string) { got = "foo" }),
+		"bar": dotenvtestcmd(func(context.Context, []string) { got = "bar" }),
+	}
+	ctx := context.Background()
+
+	if err := m.Run(ctx, []string{"/usr/local/bin/foo", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "foo" {
+		t.Errorf(`got "%s", want "foo"`, got)
+	}
+
+	if err := m.Run(ctx, []string{"/usr/local/bin/multi", "bar", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "bar" {
+		t.Errorf(`got "%s", want "bar"`, got)
+	}
+
+	err := m.Run(ctx, []string{"/usr/local/bin/foo", "help", "x"})
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if got, want := h.Error(), "usage: foo x"; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+
+	err = m.Run(ctx, []string{"/usr/local/bin/multi", "baz"})
+	var u *UnknownSubcmdErr
+	if !errors.As(err, &u) {
+		t.Fatalf("got %v, want *UnknownSubcmdErr", err)
+	}
+}
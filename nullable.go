@@ -0,0 +1,43 @@
+package subcmd
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// wrapNullables replaces the values in argvals (produced by parseArgs)
+// of the Nullable params with pointers to those values,
+// or with nil pointers for the ones that were not supplied.
+// Flags were supplied if they were set in fs.
+// Positional parameters were supplied according to posGiven.
+func wrapNullables(fs *flag.FlagSet, params []Param, posGiven []bool, argvals []reflect.Value) {
+	var set map[string]bool
+	ordered := argOrder(params)
+	nflags := len(ordered) - len(posGiven)
+	for i, p := range ordered {
+		if !p.Nullable {
+			continue
+		}
+
+		var given bool
+		if i < nflags {
+			if set == nil {
+				set = make(map[string]bool)
+				fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+			}
+			given = set[strings.TrimLeft(p.Name, "-")]
+		} else {
+			given = posGiven[i-nflags]
+		}
+
+		v := argvals[1+i]
+		if !given {
+			argvals[1+i] = reflect.Zero(reflect.PointerTo(v.Type()))
+			continue
+		}
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		argvals[1+i] = ptr
+	}
+}
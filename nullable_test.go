@@ -0,0 +1,52 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNullable(t *testing.T) {
+	var (
+		gotN   *int
+		gotPos *string
+	)
+	c := testcmdfunc(func() Map {
+		return Map{
+			"run": Subcmd{
+				F: func(_ context.Context, n *int, pos *string, _ []string) {
+					gotN, gotPos = n, pos
+				},
+				Params: []Param{
+					{Name: "-n", Type: Int, Default: 0, Nullable: true},
+					{Name: "pos?", Type: String, Default: "", Nullable: true},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"run"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != nil || gotPos != nil {
+		t.Errorf("got n=%v, pos=%v; want nil, nil", gotN, gotPos)
+	}
+
+	if err := Run(context.Background(), c, []string{"run", "-n", "0", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if gotN == nil || *gotN != 0 {
+		t.Errorf("got n=%v, want pointer to 0", gotN)
+	}
+	if gotPos == nil || *gotPos != "" {
+		t.Errorf("got pos=%v, want pointer to empty string", gotPos)
+	}
+
+	// The function must take pointers.
+	err := Check(Subcmd{
+		F:      func(context.Context, int, []string) {},
+		Params: []Param{{Name: "-n", Type: Int, Default: 0, Nullable: true}},
+	})
+	if err == nil {
+		t.Error("got no error for non-pointer function parameter")
+	}
+}
@@ -0,0 +1,124 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"text/template"
+	"time"
+)
+
+// Option is the type of an option that can be passed to [Run].
+//
+// Options passed to Run are placed in the context object
+// given to the subcommand's function,
+// and are inherited by nested calls to Run using that context.
+// Options passed to a nested call are applied on top of the inherited ones.
+type Option func(*config) error
+
+type config struct {
+	stackTraces bool
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	interactive *bool
+
+	logger *slog.Logger
+
+	timings *Timings
+
+	progName string
+
+	strictArgs bool
+
+	treeCmd bool
+
+	providers map[reflect.Type]provider
+
+	warningHandler func(context.Context, string)
+
+	auditLog io.Writer
+
+	envPrefix string
+
+	configFlag    bool
+	configFormats map[string]func([]byte, interface{}) error
+	configData    map[string]interface{}
+
+	wizard bool
+
+	formatter Formatter
+
+	gracePeriod time.Duration
+
+	profileDir string
+
+	parentFlags map[string][]string
+
+	watch        bool
+	watchTrigger func(context.Context, time.Duration) <-chan struct{}
+
+	explain   bool
+	explainTo io.Writer
+
+	helpTemplate *template.Template
+
+	inheritFlags bool
+
+	handleSignals bool
+	signals       []os.Signal
+
+	promptMissing bool
+
+	catalog Catalog
+
+	detailFormatter DetailFormatter
+
+	pluginEnv []string
+
+	parsedFlagSet **flag.FlagSet
+}
+
+func (c *config) clone() *config {
+	result := *c
+	result.pluginEnv = append([]string(nil), c.pluginEnv...)
+	if c.configFormats != nil {
+		result.configFormats = make(map[string]func([]byte, interface{}) error, len(c.configFormats))
+		for ext, f := range c.configFormats {
+			result.configFormats[ext] = f
+		}
+	}
+	if c.providers != nil {
+		result.providers = make(map[reflect.Type]provider, len(c.providers))
+		for t, p := range c.providers {
+			result.providers[t] = p
+		}
+	}
+	return &result
+}
+
+func getConfig(ctx context.Context) *config {
+	if c, ok := ctx.Value(configKey).(*config); ok {
+		return c
+	}
+	return new(config)
+}
+
+// withOptions applies opts on top of the config already in ctx (if any)
+// and returns a new context containing the result.
+func withOptions(ctx context.Context, opts []Option) (context.Context, error) {
+	if len(opts) == 0 {
+		return ctx, nil
+	}
+	c := getConfig(ctx).clone()
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return ctx, err
+		}
+	}
+	return context.WithValue(ctx, configKey, c), nil
+}
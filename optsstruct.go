@@ -0,0 +1,115 @@
+package subcmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// optsPlan describes how to fill an options struct
+// (see the F field of [Subcmd])
+// from the values of a subcommand's parameters.
+type optsPlan struct {
+	typ    reflect.Type
+	fields [][]int // for each Param, in argOrder, the index sequence of its field in typ
+}
+
+// newOptsPlan checks whether a function of type ft takes an options struct
+// in place of individual arguments for params.
+// If it does not, the result is nil, 0, nil.
+// If it does, the result is the plan for filling the struct
+// and the number of injected parameters preceding it (see [WithProvider]),
+// or an error if the struct's fields do not correspond to params.
+func newOptsPlan(ft reflect.Type, params []Param) (*optsPlan, int, error) {
+	if len(params) == 0 || ft.Kind() != reflect.Func {
+		return nil, 0, nil
+	}
+	nIn := ft.NumIn()
+	if nIn < 3 || ft.In(0) != ctxType || ft.In(nIn-1) != strSliceType {
+		return nil, 0, nil
+	}
+	switch ft.NumOut() {
+	case 0:
+	case 1:
+		if ft.Out(0) != errType {
+			return nil, 0, nil
+		}
+	default:
+		return nil, 0, nil
+	}
+	typ := ft.In(nIn - 2)
+	if typ.Kind() != reflect.Struct {
+		return nil, 0, nil
+	}
+	n := nIn - 3
+	for i := 1; i <= n; i++ {
+		if !isInjectable(ft.In(i)) {
+			return nil, 0, nil
+		}
+	}
+
+	plan := &optsPlan{typ: typ}
+	used := make(map[string]bool)
+	for _, p := range argOrder(params) {
+		f, ok := optsField(typ, p.Name)
+		if !ok {
+			return nil, 0, fmt.Errorf("param %s has no corresponding field in %s", p.Name, typ)
+		}
+		if used[f.Name] {
+			return nil, 0, fmt.Errorf("param %s has the same field in %s as another param", p.Name, typ)
+		}
+		used[f.Name] = true
+		if f.Type != p.reflectType() && f.Type != p.getterType() {
+			return nil, 0, fmt.Errorf("field %s of %s has type %s, but param %s has type %s", f.Name, typ, f.Type, p.Name, p.reflectType())
+		}
+		plan.fields = append(plan.fields, f.Index)
+	}
+	return plan, n, nil
+}
+
+// optsField finds the field of the struct type typ
+// corresponding to the parameter named name.
+// That is the exported field with the tag `subcmd:"NAME"`,
+// where NAME is name without leading dashes or a trailing "?";
+// or, if there is none,
+// the exported field whose name matches NAME
+// ignoring case, dashes, and underscores
+// (so the field DryRun corresponds to the flag -dry-run).
+func optsField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	name = strings.TrimSuffix(strings.TrimLeft(name, "-"), "?")
+	fields := reflect.VisibleFields(typ)
+	for _, f := range fields {
+		if f.IsExported() && f.Tag.Get("subcmd") == name {
+			return f, true
+		}
+	}
+	norm := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s))
+	}
+	for _, f := range fields {
+		if f.IsExported() && !f.Anonymous && f.Tag.Get("subcmd") == "" && norm(f.Name) == norm(name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// fill replaces the parameter values in argvals
+// (which follow the initial context)
+// with a single options struct containing them.
+func (plan *optsPlan) fill(argvals []reflect.Value) []reflect.Value {
+	opts := reflect.New(plan.typ).Elem()
+	for i, index := range plan.fields {
+		v := argvals[1+i]
+		f := opts.FieldByIndex(index)
+		if !v.Type().AssignableTo(f.Type()) {
+			if gv, ok := fromGetter(v, f.Type()); ok {
+				v = gv
+			}
+		}
+		f.Set(v)
+	}
+	result := make([]reflect.Value, 0, len(argvals)-len(plan.fields)+1)
+	result = append(result, argvals[0], opts)
+	return append(result, argvals[1+len(plan.fields):]...)
+}
@@ -0,0 +1,81 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type optstestOptions struct {
+	DryRun  bool
+	Count   int
+	Target  string `subcmd:"dest"`
+	Wait    time.Duration
+	Ignored string
+}
+
+func TestOptsStruct(t *testing.T) {
+	var (
+		got     optstestOptions
+		gotArgs []string
+	)
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"run", func(_ context.Context, opts optstestOptions, args []string) error {
+				got, gotArgs = opts, args
+				return nil
+			}, "", Params(
+				"-dry-run", Bool, false, "",
+				"-count", Int, 1, "",
+				"dest", String, "", "",
+				"wait?", Duration, time.Second, "",
+			),
+		)
+	})
+
+	if err := Run(context.Background(), c, []string{"run", "-dry-run", "there", "2s", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	want := optstestOptions{DryRun: true, Count: 1, Target: "there", Wait: 2 * time.Second}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != "x" {
+		t.Errorf("got args %v, want [x]", gotArgs)
+	}
+}
+
+func TestOptsStructCheck(t *testing.T) {
+	f := func(context.Context, optstestOptions, []string) {}
+
+	cases := []struct {
+		name    string
+		params  []Param
+		wantErr bool
+	}{{
+		name:   "ok",
+		params: Params("-count", Int, 0, "", "dest", String, "", ""),
+	}, {
+		name:    "noField",
+		params:  Params("-verbose", Bool, false, ""),
+		wantErr: true,
+	}, {
+		name:    "wrongType",
+		params:  Params("-count", String, "", ""),
+		wantErr: true,
+	}, {
+		name:    "sameField",
+		params:  Params("-count", Int, 0, "", "count?", Int, 0, ""),
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(Subcmd{F: f, Params: tc.params})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("got err %v, wantErr is %v", err, tc.wantErr)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,116 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// FlagsEnvVar is the name of the environment variable used by [Run]
+// to pass the values of the parent process's flags to a plugin subprocess
+// (see [Prefixer]).
+// Use [ParsePluginEnv] to decode it.
+//
+// The flags passed are the ones explicitly set on the parent's command line,
+// both in the global [flag.CommandLine]
+// and in the [FlagSet] of the subcommand (if any) that called Run.
+// The value is a JSON object mapping flag names to their string values,
+// or to arrays of strings for flags given more than once
+// (such as [Strings] and [StringMap] flags).
+// Flags whose names suggest they hold secrets,
+// as described at [WithLogger],
+// are not passed.
+const FlagsEnvVar = "SUBCMD_FLAGS"
+
+// ParsePluginEnv is a richer form of [ParseEnv] for use in plugin executables
+// (see [Prefixer]).
+// In addition to decoding the parent's [Cmd] into the value pointed to by ptr,
+// it returns an [Option] that,
+// when passed to [Run],
+// makes the values of the parent's flags (see [FlagsEnvVar])
+// the defaults for the plugin's flags with the same names.
+// So if "mytool -verbose ext" runs the plugin "mytool-ext",
+// and a subcommand of mytool-ext has a -verbose flag,
+// that flag is true unless overridden on the plugin's command line.
+//
+// Parent flag values that cannot be parsed by the plugin's corresponding flags
+// produce warnings (see [WithWarningHandler]).
+func ParsePluginEnv(ptr interface{}) (Option, error) {
+	if err := ParseEnv(ptr); err != nil {
+		return nil, err
+	}
+
+	var flags map[string][]string
+	if val := os.Getenv(FlagsEnvVar); val != "" {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(val), &raw); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", FlagsEnvVar, err)
+		}
+		flags = make(map[string][]string, len(raw))
+		for name, r := range raw {
+			var (
+				s    string
+				vals []string
+			)
+			if err := json.Unmarshal(r, &s); err == nil {
+				vals = []string{s}
+			} else if err := json.Unmarshal(r, &vals); err != nil {
+				return nil, fmt.Errorf("parsing %s: value for flag %s: %w", FlagsEnvVar, name, err)
+			}
+			flags[name] = vals
+		}
+	}
+
+	return func(c *config) error {
+		c.parentFlags = flags
+		return nil
+	}, nil
+}
+
+// parentFlagsJSON encodes the flags that should be passed to a plugin subprocess.
+// See FlagsEnvVar.
+func parentFlagsJSON(ctx context.Context) ([]byte, error) {
+	flags := make(map[string]interface{})
+	visit := func(f *flag.Flag) {
+		if isSecretName(f.Name) {
+			return
+		}
+		if vals := setValues(f); len(vals) == 1 {
+			flags[f.Name] = vals[0]
+		} else {
+			flags[f.Name] = vals
+		}
+	}
+	flag.CommandLine.Visit(visit)
+	if fs, ok := ctx.Value(fsKey).(*flag.FlagSet); ok {
+		fs.Visit(visit)
+	}
+	return json.Marshal(flags)
+}
+
+// applyParentFlags sets the flags in fs from the parent process's flags,
+// if ParsePluginEnv was used.
+func applyParentFlags(ctx context.Context, fs *flag.FlagSet) {
+	parentFlags := getConfig(ctx).parentFlags
+
+	names := make([]string, 0, len(parentFlags))
+	for name := range parentFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			continue
+		}
+		for _, val := range parentFlags[name] {
+			if err := fs.Set(name, val); err != nil {
+				warn(ctx, "setting -%s from parent: %s", name, err)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"testing"
+)
+
+func TestParsePluginEnv(t *testing.T) {
+	defer testSetenv(EnvVar, `{"data": "xyz"}`)()
+	defer testSetenv(FlagsEnvVar, `{"n": "4", "verbose": "true", "tag": ["a", "b"]}`)()
+
+	var parent testPrefixMainCmd
+	opt, err := ParsePluginEnv(&parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.Data != "xyz" {
+		t.Errorf(`got data "%s", want "xyz"`, parent.Data)
+	}
+
+	var gotN int
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, _ string, _ []string) { gotN = n }}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, opt); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 4 {
+		t.Errorf("got n=%d, want 4", gotN)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "5", "dir"}, opt); err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 5 {
+		t.Errorf("with command-line flag, got n=%d, want 5", gotN)
+	}
+
+	var gotTags []string
+	tc := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, tags []string, _ []string) { gotTags = tags }, "", Params(
+			"-tag", Strings, []string(nil), "tags",
+		))
+	})
+	if err := Run(context.Background(), tc, []string{"x"}, opt); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTags) != 2 || gotTags[0] != "a" || gotTags[1] != "b" {
+		t.Errorf("got tags %v, want [a b]", gotTags)
+	}
+}
+
+func TestParentFlagsJSON(t *testing.T) {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.Int("n", 1, "")
+	fs.Bool("unset", false, "")
+	fs.Var(&stringsValue{vals: new([]string)}, "tag", "")
+	fs.String("password", "", "")
+	fs.String("api-key", "", "")
+	if err := fs.Parse([]string{"-n", "3", "-tag", "a", "-tag", "b,c", "-password", "hunter2", "-api-key", "xyz"}); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := parentFlagsJSON(withFlagSet(context.Background(), fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(j, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["n"] != "3" {
+		t.Errorf(`got n=%v, want "3"`, got["n"])
+	}
+	if tags, ok := got["tag"].([]interface{}); !ok || len(tags) != 2 || tags[1] != "b,c" {
+		t.Errorf(`got tag=%v, want ["a", "b,c"]`, got["tag"])
+	}
+	if _, ok := got["unset"]; ok {
+		t.Error("got value for unset flag")
+	}
+	for _, name := range []string{"password", "api-key"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("got value for secret flag %s", name)
+		}
+	}
+}
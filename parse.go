@@ -1,49 +1,127 @@
 package subcmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 // If variadic is false, the length of the resulting slice is len(params)+2.
 // If it's true, the length is >= len(params)+1.
-func parseArgs(ctx context.Context, params []Param, args []string, variadic bool) ([]reflect.Value, error) {
-	fs, ptrs, positional, err := ToFlagSet(params)
-	if err != nil {
+// The args remaining after parsing params must satisfy limits.
+// The FlagSet for params is built from bp.
+func parseArgs(ctx context.Context, params []Param, bp *flagSetBlueprint, args []string, variadic bool, limits argLimits) ([]reflect.Value, error) {
+	var (
+		fs, ptrs   = bp.build()
+		positional = bp.positional
+		err        error
+	)
+
+	inheritFlags(ctx, fs)
+
+	x := newExplanation(ctx, fs)
+
+	applyParentFlags(ctx, fs)
+	x.note(func(string) string { return "parent process" })
+	endSource(fs)
+	if err = applyPersistentFlags(ctx, fs); err != nil {
 		return nil, err
 	}
-
-	err = fs.Parse(args)
+	x.note(func(string) string { return "persistent flag" })
+	endSource(fs)
+	if err = applyConfigFile(ctx, fs); err != nil {
+		return nil, err
+	}
+	x.note(func(string) string { return "config file" })
+	endSource(fs)
+	if err = applyEnv(ctx, fs, params); err != nil {
+		return nil, err
+	}
+	x.note(func(name string) string {
+		varname, _, _ := lookupFlagEnv(ctx, params, name)
+		return "$" + varname
+	})
+	endSource(fs)
+
+	origArgs := args
+
+	err = parseFlags(ctx, fs, args)
+	storeParsedFlagSet(ctx, fs)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, fmt.Errorf("parsing args: %w", err)
+	}
 	if err != nil {
-		return nil, errors.Wrap(err, "parsing args")
+		return nil, newFlagErr(ctx, fs, params, err)
+	}
+	if err = checkRequired(fs, params, catalog(ctx)); err != nil {
+		return nil, err
+	}
+	if err = checkRequires(fs, params, catalog(ctx)); err != nil {
+		return nil, err
+	}
+	if err = resolvePathFlags(fs, params); err != nil {
+		return nil, err
 	}
 
 	args = fs.Args()
 	ctx = withFlagSet(ctx, fs)
 
 	argvals := []reflect.Value{reflect.ValueOf(ctx)}
-	for _, ptr := range ptrs {
-		if ptr.Type().Implements(valueType) {
+	var i int
+	for _, p := range params {
+		if !strings.HasPrefix(p.Name, "-") {
+			continue
+		}
+		// Decide by the param type rather than by whether ptr implements flag.Value,
+		// since a Custom param's pointer might.
+		if ptr := ptrs[i]; p.Type == Value {
 			argvals = append(argvals, ptr)
 		} else {
 			argvals = append(argvals, ptr.Elem())
 		}
+		i++
 	}
 
+	var (
+		posGiven = make([]bool, 0, len(positional))
+		stdin    *bufio.Reader // for promptMissing
+	)
 	for _, p := range positional {
+		if len(args) == 0 && !strings.HasSuffix(p.Name, "?") {
+			if args, err = promptMissing(ctx, &stdin, p, args); err != nil {
+				return nil, err
+			}
+		}
+		posGiven = append(posGiven, len(args) > 0)
 		err = parsePositionalArg(p, &args, &argvals)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if len(positional) > 0 {
+		posvals := make(map[string]interface{}, len(positional))
+		for i, p := range positional {
+			posvals[strings.TrimSuffix(p.Name, "?")] = argvals[1+len(ptrs)+i].Interface()
+		}
+		argvals[0] = reflect.ValueOf(context.WithValue(ctx, positionalKey, posvals))
+	}
+
+	x.print(params, origArgs, args, argvals[1+len(ptrs):])
+
+	wrapNullables(fs, params, posGiven, argvals)
+
+	if err = limits.check(args, catalog(ctx)); err != nil {
+		return nil, err
+	}
+
 	if variadic {
 		for _, arg := range args {
 			argvals = append(argvals, reflect.ValueOf(arg))
@@ -88,6 +166,29 @@ func parsePositionalArg(p Param, args *[]string, argvals *[]reflect.Value) error
 	case Value:
 		return parseValuePos(args, argvals, p)
 
+	case Strings, StringMap:
+		return fmt.Errorf("param %s has type %s but is not a flag", p.Name, p.Type)
+
+	case ExistingFile, ExistingDir:
+		return parsePathPos(args, argvals, p)
+
+	case Time:
+		return parseTimePos(args, argvals, p)
+
+	case JSON:
+		return parseJSONPos(args, argvals, p)
+
+	case Custom:
+		return parseCustomPos(args, argvals, p)
+
+	case Text:
+		parse, err := textParseFunc(p)
+		if err != nil {
+			return ParseErr{Err: err}
+		}
+		p.Parse = parse
+		return parseCustomPos(args, argvals, p)
+
 	default:
 		return fmt.Errorf("unknown arg type %v", p.Type)
 	}
@@ -99,7 +200,7 @@ func parseBoolPos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseBool((*args)[0])
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -114,7 +215,7 @@ func parseIntPos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseInt((*args)[0], 10, 32)
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -129,7 +230,7 @@ func parseInt64Pos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseInt((*args)[0], 10, 64)
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -144,7 +245,7 @@ func parseUintPos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseUint((*args)[0], 10, 32)
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -159,7 +260,7 @@ func parseUint64Pos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseUint((*args)[0], 10, 64)
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -171,6 +272,11 @@ func parseStringPos(args *[]string, argvals *[]reflect.Value, p Param) error {
 	val, _ := p.Default.(string)
 	if len(*args) > 0 {
 		val = (*args)[0]
+		if len(p.Choices) > 0 {
+			if err := checkChoice(p.Choices, val); err != nil {
+				return parseErr(p, val, err)
+			}
+		}
 		*args = (*args)[1:]
 	}
 	*argvals = append(*argvals, reflect.ValueOf(val))
@@ -184,7 +290,7 @@ func parseFloat64Pos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = strconv.ParseFloat((*args)[0], 64)
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -199,7 +305,7 @@ func parseDurationPos(args *[]string, argvals *[]reflect.Value, p Param) error {
 		var err error
 		val, err = time.ParseDuration((*args)[0])
 		if err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -217,7 +323,7 @@ func parseValuePos(args *[]string, argvals *[]reflect.Value, p Param) error {
 	}
 	if len(*args) > 0 {
 		if err := val.Set((*args)[0]); err != nil {
-			return ParseErr{Err: err}
+			return parseErr(p, (*args)[0], err)
 		}
 		*args = (*args)[1:]
 	}
@@ -358,6 +464,269 @@ func asDuration(val interface{}) time.Duration {
 	return 0
 }
 
+// unquoteUsage is like [flag.UnquoteUsage]
+// but also knows the value names for this package's own [flag.Value] types.
+func unquoteUsage(f *flag.Flag) (name string, usage string) {
+	name, usage = flag.UnquoteUsage(f)
+	if name == "value" {
+		switch f.Value.(type) {
+		case *choiceValue, *stringsValue:
+			name = "string"
+		case *timeValue:
+			name = "time"
+		case *jsonValue:
+			name = "json"
+		case *stringMapValue:
+			name = "key=value"
+		}
+	}
+	return name, usage
+}
+
+// sourceEnder is implemented by flag values that accumulate the results of repeated calls to Set.
+type sourceEnder interface {
+	// endSource tells the value that subsequent calls to Set come from a higher-precedence source
+	// (e.g. the command line after the environment),
+	// whose values should replace, not add to, the ones so far.
+	endSource()
+}
+
+// endSource calls endSource on the values in fs that implement sourceEnder.
+// It is called after each lower-precedence source of flag values
+// (parent process, persistent flags, config file, environment)
+// is applied.
+func endSource(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		if se, ok := f.Value.(sourceEnder); ok {
+			se.endSource()
+		}
+	})
+}
+
+// setValues returns the individual values given to f,
+// suitable for passing one at a time to Set on a flag of the same type.
+// For a [Strings] flag, these are its separate strings;
+// for a [StringMap] flag, they are the key=value pairs that were set (in key order);
+// and for other flags, the single string f.Value.String().
+func setValues(f *flag.Flag) []string {
+	switch v := f.Value.(type) {
+	case *stringsValue:
+		if v.vals == nil {
+			return nil
+		}
+		return append([]string(nil), *v.vals...)
+
+	case *stringMapValue:
+		keys := make([]string, 0, len(v.seen))
+		for k := range v.seen {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, k+"="+v.m[k])
+		}
+		return result
+	}
+	return []string{f.Value.String()}
+}
+
+// stringsValue is the [flag.Value] for a parameter of type [Strings].
+type stringsValue struct {
+	vals *[]string
+	set  bool
+}
+
+func (v *stringsValue) String() string {
+	if v == nil || v.vals == nil {
+		return ""
+	}
+	return strings.Join(*v.vals, ",")
+}
+
+func (v *stringsValue) Set(s string) error {
+	if !v.set {
+		*v.vals = nil
+		v.set = true
+	}
+	*v.vals = append(*v.vals, s)
+	return nil
+}
+
+// Get implements [flag.Getter].
+func (v *stringsValue) Get() interface{} {
+	return *v.vals
+}
+
+// endSource implements sourceEnder.
+// The next call to Set replaces the list so far.
+func (v *stringsValue) endSource() {
+	v.set = false
+}
+
+// stringMapValue is the [flag.Value] for a parameter of type [StringMap].
+type stringMapValue struct {
+	m      map[string]string
+	seen   map[string]bool
+	policy DuplicatePolicy
+}
+
+func (v *stringMapValue) String() string {
+	if v == nil || len(v.m) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(v.m))
+	for k := range v.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+v.m[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *stringMapValue) Set(s string) error {
+	key, val, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("want key=value")
+	}
+	if v.seen[key] {
+		switch v.policy {
+		case DuplicateFirst:
+			return nil
+		case DuplicateError:
+			return fmt.Errorf("duplicate key %s", key)
+		}
+	}
+	v.seen[key] = true
+	v.m[key] = val
+	return nil
+}
+
+// Get implements [flag.Getter].
+func (v *stringMapValue) Get() interface{} {
+	return v.m
+}
+
+// endSource implements sourceEnder.
+// Keys set so far may be set again,
+// replacing their values,
+// regardless of the DuplicatePolicy,
+// which applies only among the values from a single source.
+func (v *stringMapValue) endSource() {
+	v.seen = make(map[string]bool)
+}
+
+// checkRequired returns a [MissingFlagErr]
+// if any of the required flags in params were not set in fs.
+func checkRequired(fs *flag.FlagSet, params []Param, cat Catalog) error {
+	required := requiredFlags(params)
+	if len(required) == 0 {
+		return nil
+	}
+	fs.Visit(func(f *flag.Flag) {
+		delete(required, f.Name)
+	})
+
+	var missing []string
+	for _, p := range params {
+		if name := strings.TrimLeft(p.Name, "-"); required[name] {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return MissingFlagErr{Flags: missing, cat: cat}
+	}
+	return nil
+}
+
+// checkRequires returns a [FlagRequiresErr]
+// if any flag in params that was set in fs
+// requires another flag that wasn't.
+func checkRequires(fs *flag.FlagSet, params []Param, cat Catalog) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for _, p := range params {
+		name := strings.TrimLeft(p.Name, "-")
+		if len(p.Requires) == 0 || !strings.HasPrefix(p.Name, "-") || !set[name] {
+			continue
+		}
+		var missing []string
+		for _, req := range p.Requires {
+			if req = strings.TrimLeft(req, "-"); !set[req] {
+				missing = append(missing, "-"+req)
+			}
+		}
+		if len(missing) > 0 {
+			return FlagRequiresErr{Flag: "-" + name, Missing: missing, cat: cat}
+		}
+	}
+	return nil
+}
+
+// requiredFlags returns the set of names (without leading "-") of the required flags in params.
+func requiredFlags(params []Param) map[string]bool {
+	result := make(map[string]bool)
+	for _, p := range params {
+		if p.Required && strings.HasPrefix(p.Name, "-") {
+			result[strings.TrimLeft(p.Name, "-")] = true
+		}
+	}
+	return result
+}
+
+// flagToken describes a flag found in an argument list by scanFlagArgs.
+type flagToken struct {
+	name string // the flag name, without leading "-"
+	idx  int    // the index of the flag in the argument list
+	val  int    // the index of the flag's value, if it's a separate argument; otherwise -1
+}
+
+// scanFlagArgs finds the flags for params in args,
+// following the parsing rules of the [flag] package.
+// It also returns the index of the first non-flag argument
+// (which is len(args) if there isn't one).
+func scanFlagArgs(params []Param, args []string) ([]flagToken, int) {
+	flags := make(map[string]Param)
+	for _, p := range params {
+		if strings.HasPrefix(p.Name, "-") {
+			flags[strings.TrimLeft(p.Name, "-")] = p
+		}
+	}
+
+	var result []flagToken
+
+	i := 0
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			i++
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		name, _, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		p, ok := flags[name]
+		if !ok {
+			continue
+		}
+		tok := flagToken{name: name, idx: i, val: -1}
+		if !hasVal && p.Type != Bool && i+1 < len(args) {
+			i++
+			tok.val = i
+		}
+		result = append(result, tok)
+	}
+
+	return result, i
+}
+
 // ToFlagSet takes a slice of [Param] and produces:
 //
 //   - a [flag.FlagSet],
@@ -366,67 +735,198 @@ func asDuration(val interface{}) time.Duration {
 //
 // On a successful return, len(ptrs)+len(positional) == len(params).
 func ToFlagSet(params []Param) (fs *flag.FlagSet, ptrs []reflect.Value, positional []Param, err error) {
-	fs = flag.NewFlagSet("", flag.ContinueOnError)
+	bp, err := newFlagSetBlueprint(params)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fs, ptrs = bp.build()
+	return fs, ptrs, bp.positional, nil
+}
+
+// flagSetBlueprint is the validated and preprocessed form of a list of params,
+// from which FlagSets can be built repeatedly
+// without redoing that work
+// (see [Compile]).
+type flagSetBlueprint struct {
+	// flags are the flag params,
+	// with leading dashes removed from their names,
+	// their defaults converted to the types of their values,
+	// and, for Text params, their Parse functions filled in.
+	flags []Param
+
+	positional []Param
+}
+
+func newFlagSetBlueprint(params []Param) (*flagSetBlueprint, error) {
+	bp := new(flagSetBlueprint)
 
 	for _, p := range params {
 		if !strings.HasPrefix(p.Name, "-") {
-			positional = append(positional, p)
+			bp.positional = append(bp.positional, p)
 			continue
 		}
 
+		p.Name = strings.TrimLeft(p.Name, "-")
+
+		switch p.Type {
+		case Bool:
+			p.Default, _ = p.Default.(bool)
+
+		case Int:
+			p.Default = asInt(p.Default)
+
+		case Int64:
+			p.Default = asInt64(p.Default)
+
+		case Uint:
+			p.Default = asUint(p.Default)
+
+		case Uint64:
+			p.Default = asUint64(p.Default)
+
+		case String, ExistingFile, ExistingDir:
+			p.Default, _ = p.Default.(string)
+
+		case Float64:
+			p.Default = asFloat64(p.Default)
+
+		case Duration:
+			p.Default = asDuration(p.Default)
+
+		case Value:
+			if _, ok := p.Default.(flag.Value); !ok {
+				return nil, fmt.Errorf("param -%s has type Value but default value %v is not a ValueType", p.Name, p.Default)
+			}
+
+		case Time:
+			p.Default, _ = p.Default.(time.Time)
+
+		case JSON:
+			if _, err := newJSONValue(p); err != nil {
+				return nil, err
+			}
+
+		case Text:
+			var err error
+			if p.Parse, err = textParseFunc(p); err != nil {
+				return nil, err
+			}
+
+		case Custom:
+			if p.Default == nil || p.Parse == nil {
+				return nil, fmt.Errorf("param -%s has type Custom but no default value or Parse function", p.Name)
+			}
+
+		case StringMap:
+			p.Default, _ = p.Default.(map[string]string)
+
+		case Strings:
+			p.Default, _ = p.Default.([]string)
+
+		default:
+			return nil, fmt.Errorf("unknown arg type %v", p.Type)
+		}
+
+		bp.flags = append(bp.flags, p)
+	}
+
+	return bp, nil
+}
+
+// build produces a new FlagSet from bp,
+// and the pointers in which its values are stored
+// (see ToFlagSet).
+func (bp *flagSetBlueprint) build() (*flag.FlagSet, []reflect.Value) {
+	var (
+		fs   = flag.NewFlagSet("", flag.ContinueOnError)
+		ptrs = make([]reflect.Value, 0, len(bp.flags))
+	)
+
+	for _, p := range bp.flags {
 		var (
-			name = strings.TrimLeft(p.Name, "-")
+			name = p.Name
 			v    interface{}
 		)
 
 		switch p.Type {
 		case Bool:
-			dflt, _ := p.Default.(bool)
-			v = fs.Bool(name, dflt, p.Doc)
+			v = fs.Bool(name, p.Default.(bool), p.Doc)
 
 		case Int:
-			v = fs.Int(name, asInt(p.Default), p.Doc)
+			v = fs.Int(name, p.Default.(int), p.Doc)
 
 		case Int64:
-			v = fs.Int64(name, asInt64(p.Default), p.Doc)
+			v = fs.Int64(name, p.Default.(int64), p.Doc)
 
 		case Uint:
-			v = fs.Uint(name, asUint(p.Default), p.Doc)
+			v = fs.Uint(name, p.Default.(uint), p.Doc)
 
 		case Uint64:
-			v = fs.Uint64(name, asUint64(p.Default), p.Doc)
+			v = fs.Uint64(name, p.Default.(uint64), p.Doc)
 
 		case String:
-			dflt, _ := p.Default.(string)
-			v = fs.String(name, dflt, p.Doc)
+			dflt := p.Default.(string)
+			if len(p.Choices) == 0 {
+				v = fs.String(name, dflt, p.Doc)
+			} else {
+				ptr := &dflt
+				fs.Var(&choiceValue{ptr: ptr, choices: p.Choices}, name, p.Doc)
+				v = ptr
+			}
 
 		case Float64:
-			v = fs.Float64(name, asFloat64(p.Default), p.Doc)
+			v = fs.Float64(name, p.Default.(float64), p.Doc)
 
 		case Duration:
-			v = fs.Duration(name, asDuration(p.Default), p.Doc)
+			v = fs.Duration(name, p.Default.(time.Duration), p.Doc)
 
 		case Value:
-			val, ok := p.Default.(flag.Value)
-			if !ok {
-				err = fmt.Errorf("param %s has type Value but default value %v is not a ValueType", p.Name, p.Default)
-				return
-			}
+			val := p.Default.(flag.Value)
 			if copier, ok := val.(Copier); ok {
 				val = copier.Copy()
 			}
 			fs.Var(val, name, p.Doc)
 			v = val
 
-		default:
-			err = fmt.Errorf("unknown arg type %v", p.Type)
-			return
+		case ExistingFile, ExistingDir:
+			v = fs.String(name, p.Default.(string), p.Doc)
+
+		case Time:
+			dflt := p.Default.(time.Time)
+			ptr := &dflt
+			fs.Var(&timeValue{ptr: ptr, layouts: p.Layouts}, name, p.Doc)
+			v = ptr
+
+		case JSON:
+			jv, _ := newJSONValue(p) // checked in newFlagSetBlueprint
+			fs.Var(jv, name, p.Doc)
+			v = jv.holder.Interface()
+
+		case Text, Custom:
+			cv := newCustomValue(p)
+			fs.Var(cv, name, p.Doc)
+			v = cv.ptr.Interface()
+
+		case StringMap:
+			dflt := p.Default.(map[string]string)
+			m := make(map[string]string, len(dflt))
+			for k, val := range dflt {
+				m[k] = val
+			}
+			fs.Var(&stringMapValue{m: m, seen: make(map[string]bool), policy: p.OnDuplicate}, name, p.Doc)
+			v = &m
+
+		case Strings:
+			ptr := new([]string)
+			*ptr = append([]string(nil), p.Default.([]string)...)
+			fs.Var(&stringsValue{vals: ptr}, name, p.Doc)
+			v = ptr
 		}
 
 		ptrs = append(ptrs, reflect.ValueOf(v))
 	}
 
-	return fs, ptrs, positional, nil
+	return fs, ptrs
 }
 
 // Copier is a [flag.Value] that can copy itself.
@@ -1,7 +1,12 @@
 package subcmd
 
 import (
+	"context"
 	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -43,3 +48,194 @@ func TestToFlagSet(t *testing.T) {
 		})
 	}
 }
+
+func TestStrings(t *testing.T) {
+	var gotTags []string
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, tags []string, _ []string) { gotTags = tags }, "", Params(
+			"-tag", Strings, []string{"default"}, "tags",
+		))
+	})
+
+	cases := []struct {
+		args []string
+		want []string
+	}{{
+		args: []string{"x"},
+		want: []string{"default"},
+	}, {
+		args: []string{"x", "-tag", "a"},
+		want: []string{"a"},
+	}, {
+		args: []string{"x", "-tag", "a", "--tag=b", "-tag", "c"},
+		want: []string{"a", "b", "c"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			if err := Run(context.Background(), c, tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotTags, tc.want) {
+				t.Errorf("got %v, want %v", gotTags, tc.want)
+			}
+		})
+	}
+
+	if err := Check(Subcmd{F: func(context.Context, []string, []string) {}, Params: Params("tags", Strings, []string(nil), "")}); err == nil {
+		t.Error("got no error for positional Strings parameter")
+	}
+}
+
+func TestStringMap(t *testing.T) {
+	var got map[string]string
+	newCmd := func(policy DuplicatePolicy) Cmd {
+		return testcmdfunc(func() Map {
+			return Map{
+				"x": Subcmd{
+					F: func(_ context.Context, labels map[string]string, _ []string) { got = labels },
+					Params: []Param{{
+						Name:        "-label",
+						Type:        StringMap,
+						Default:     map[string]string{"env": "dev"},
+						OnDuplicate: policy,
+					}},
+				},
+			}
+		})
+	}
+
+	cases := []struct {
+		name    string
+		policy  DuplicatePolicy
+		args    []string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "default",
+		args: []string{"x"},
+		want: map[string]string{"env": "dev"},
+	}, {
+		name: "merge",
+		args: []string{"x", "-label", "env=prod", "-label", "team=infra=core"},
+		want: map[string]string{"env": "prod", "team": "infra=core"},
+	}, {
+		name: "last",
+		args: []string{"x", "-label", "a=1", "-label", "a=2"},
+		want: map[string]string{"env": "dev", "a": "2"},
+	}, {
+		name:   "first",
+		policy: DuplicateFirst,
+		args:   []string{"x", "-label", "a=1", "-label", "a=2"},
+		want:   map[string]string{"env": "dev", "a": "1"},
+	}, {
+		name:    "error",
+		policy:  DuplicateError,
+		args:    []string{"x", "-label", "a=1", "-label", "a=2"},
+		wantErr: true,
+	}, {
+		name:    "malformed",
+		args:    []string{"x", "-label", "a"},
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			err := Run(context.Background(), newCmd(tc.policy), tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAccumulatingFlagSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"x": {"tag": ["a", "b"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		gotTags   []string
+		gotLabels map[string]string
+	)
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, tags []string, labels map[string]string, _ []string) {
+					gotTags, gotLabels = tags, labels
+				},
+				Params: []Param{{
+					Name: "-tag",
+					Type: Strings,
+					Env:  "SUBCMD_TEST_TAG",
+				}, {
+					Name:        "-label",
+					Type:        StringMap,
+					Env:         "SUBCMD_TEST_LABEL",
+					OnDuplicate: DuplicateError,
+				}},
+			},
+		}
+	})
+
+	cases := []struct {
+		name       string
+		env        bool
+		args       []string
+		wantTags   []string
+		wantLabels map[string]string
+	}{{
+		name:     "config",
+		args:     []string{"-config", path, "x"},
+		wantTags: []string{"a", "b"},
+	}, {
+		name:     "config and flag",
+		args:     []string{"-config", path, "x", "-tag", "c", "-tag", "d"},
+		wantTags: []string{"c", "d"},
+	}, {
+		name:       "config and env",
+		env:        true,
+		args:       []string{"-config", path, "x"},
+		wantTags:   []string{"e"},
+		wantLabels: map[string]string{"a": "1"},
+	}, {
+		name:       "env and flag",
+		env:        true,
+		args:       []string{"x", "-tag", "c", "-label", "a=2", "-label", "b=3"},
+		wantTags:   []string{"c"},
+		wantLabels: map[string]string{"a": "2", "b": "3"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env {
+				defer testSetenv("SUBCMD_TEST_TAG", "e")()
+				defer testSetenv("SUBCMD_TEST_LABEL", "a=1")()
+			}
+			gotTags, gotLabels = nil, nil
+			if err := Run(context.Background(), c, tc.args, WithConfigFlag()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotTags, tc.wantTags) {
+				t.Errorf("got tags %v, want %v", gotTags, tc.wantTags)
+			}
+			if len(gotLabels) > 0 || len(tc.wantLabels) > 0 {
+				if !reflect.DeepEqual(gotLabels, tc.wantLabels) {
+					t.Errorf("got labels %v, want %v", gotLabels, tc.wantLabels)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package subcmd
+
+import (
+	"context"
+	"flag"
+)
+
+// WithParsedFlagSet is an [Option] that causes [Run] to store in *fs
+// the [flag.FlagSet] it parses for the subcommand it invokes
+// (the same one that [FlagSet] returns to the subcommand's function).
+// It is stored even if parsing fails,
+// so that tests can examine the flags of a command line that was rejected.
+// *fs is left alone if Run does not reach the point of parsing flags.
+//
+// The caller must not access *fs until Run returns.
+// See also the subcmdtest package.
+func WithParsedFlagSet(fs **flag.FlagSet) Option {
+	return func(c *config) error {
+		c.parsedFlagSet = fs
+		return nil
+	}
+}
+
+// storeParsedFlagSet stores fs as requested with WithParsedFlagSet, if it was.
+func storeParsedFlagSet(ctx context.Context, fs *flag.FlagSet) {
+	if p := getConfig(ctx).parsedFlagSet; p != nil {
+		*p = fs
+	}
+}
@@ -0,0 +1,37 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestWithParsedFlagSet(t *testing.T) {
+	var (
+		c    = nestedtestcmd{migrate: func(context.Context, int, string, []string) {}}
+		opts = []Option{WithProgName("prog"), WithStdio(nil, nil, io.Discard)}
+		fs   *flag.FlagSet
+	)
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "-n", "3", "here"}, append(opts, WithParsedFlagSet(&fs))...); err != nil {
+		t.Fatal(err)
+	}
+	if fs == nil {
+		t.Fatal("got no FlagSet")
+	}
+	if got := fs.Lookup("n").Value.String(); got != "3" {
+		t.Errorf(`got -n "%s", want "3"`, got)
+	}
+
+	fs = nil
+	err := Run(context.Background(), c, []string{"db", "migrate", "-x"}, append(opts, WithParsedFlagSet(&fs))...)
+	var ferr FlagErr
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got %v, want FlagErr", err)
+	}
+	if fs == nil {
+		t.Error("got no FlagSet after a parse error")
+	}
+}
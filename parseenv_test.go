@@ -37,3 +37,22 @@ func TestParseEnv(t *testing.T) {
 		t.Errorf("got %#v, want %#v", got, want)
 	}
 }
+
+func TestParseEnvVarName(t *testing.T) {
+	defer testSetenv(EnvVar, `{"data": "wrong"}`)()
+	defer testSetenv("MYTOOL_ENV", `{"data": "right"}`)()
+
+	var got testEnvNameCmd
+	if err := ParseEnv(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Data != "right" {
+		t.Errorf(`got data "%s", want "right"`, got.Data)
+	}
+}
+
+type testEnvNameCmd struct {
+	testPrefixMainCmd
+}
+
+func (testEnvNameCmd) EnvVarName() string { return "MYTOOL_ENV" }
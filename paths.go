@@ -0,0 +1,70 @@
+package subcmd
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// checkPath checks that path exists and is a file or directory,
+// according to typ ([ExistingFile] or [ExistingDir]),
+// and returns its cleaned absolute form.
+func checkPath(typ Type, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case typ == ExistingFile && info.IsDir():
+		return "", &os.PathError{Op: "check", Path: path, Err: errors.New("is a directory")}
+	case typ == ExistingDir && !info.IsDir():
+		return "", &os.PathError{Op: "check", Path: path, Err: errors.New("not a directory")}
+	}
+	return filepath.Abs(path)
+}
+
+func parsePathPos(args *[]string, argvals *[]reflect.Value, p Param) error {
+	val, _ := p.Default.(string)
+	if len(*args) > 0 {
+		val = (*args)[0]
+		*args = (*args)[1:]
+	}
+	if val != "" {
+		abs, err := checkPath(p.Type, val)
+		if err != nil {
+			return parseErr(p, val, err)
+		}
+		val = abs
+	}
+	*argvals = append(*argvals, reflect.ValueOf(val))
+	return nil
+}
+
+// resolvePathFlags checks the values of the [ExistingFile] and [ExistingDir] flags in fs
+// and replaces them with their absolute forms.
+// It sets the flags' values directly rather than with fs.Set,
+// so that flags not given on the command line still count as unset
+// (see [Changed] and [Param.Nullable]).
+func resolvePathFlags(fs *flag.FlagSet, params []Param) error {
+	for _, p := range params {
+		if (p.Type != ExistingFile && p.Type != ExistingDir) || !strings.HasPrefix(p.Name, "-") {
+			continue
+		}
+		f := fs.Lookup(strings.TrimLeft(p.Name, "-"))
+		val := f.Value.String()
+		if val == "" {
+			continue
+		}
+		abs, err := checkPath(p.Type, val)
+		if err != nil {
+			return parseErr(p, val, err)
+		}
+		if err := f.Value.Set(abs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExistingPaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotDir, gotFile string
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, d, f string, _ []string) { gotDir, gotFile = d, f }, "", Params(
+			"-dir", ExistingDir, "", "",
+			"file", ExistingFile, "", "",
+		))
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-dir", dir + "/.", filepath.Join(dir, "..", filepath.Base(dir), "file")}); err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != dir {
+		t.Errorf("got dir %s, want %s", gotDir, dir)
+	}
+	if gotFile != file {
+		t.Errorf("got file %s, want %s", gotFile, file)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		path string
+	}{{
+		name: "file_is_dir",
+		args: []string{"x", dir},
+		path: dir,
+	}, {
+		name: "dir_is_file",
+		args: []string{"x", "-dir", file, file},
+		path: file,
+	}, {
+		name: "missing",
+		args: []string{"x", filepath.Join(dir, "nope")},
+		path: filepath.Join(dir, "nope"),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Run(context.Background(), c, tc.args)
+			var (
+				p  ParseErr
+				pe *os.PathError
+			)
+			if !errors.As(err, &p) || !errors.As(err, &pe) {
+				t.Fatalf("got %v, want ParseErr wrapping *os.PathError", err)
+			}
+			if pe.Path != tc.path {
+				t.Errorf("got path %s, want %s", pe.Path, tc.path)
+			}
+			if p.Input != tc.path {
+				t.Errorf("got input %s, want %s", p.Input, tc.path)
+			}
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		var (
+			gotDir     string
+			gotFile    *string
+			dirChanged bool
+		)
+		c := testcmdfunc(func() Map {
+			return Map{"x": Subcmd{
+				F: func(ctx context.Context, d string, f *string, _ []string) {
+					gotDir, gotFile, dirChanged = d, f, Changed(ctx, "dir")
+				},
+				Params: []Param{
+					{Name: "-dir", Type: ExistingDir, Default: dir + "/."},
+					{Name: "-file", Type: ExistingFile, Default: file, Nullable: true},
+				},
+			}}
+		})
+		if err := Run(context.Background(), c, []string{"x"}); err != nil {
+			t.Fatal(err)
+		}
+		if gotDir != dir {
+			t.Errorf("got dir %s, want %s", gotDir, dir)
+		}
+		if dirChanged {
+			t.Error("default -dir reported as changed")
+		}
+		if gotFile != nil {
+			t.Errorf("got file %s, want nil", *gotFile)
+		}
+	})
+}
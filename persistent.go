@@ -0,0 +1,185 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// PersistentFlagger is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// the flags described by PersistentFlags apply to all of the Cmd's subcommands,
+// including nested ones (see Subcmd.Sub).
+// They may appear on the command line before the subcommand name,
+// at this level or at any deeper level,
+// as in "prog -verbose db migrate" or "prog db -verbose migrate".
+//
+// Their values are available to subcommand functions via [Persistent].
+// In addition,
+// if a subcommand has a flag parameter with the same name as a persistent flag
+// that was given on the command line,
+// the persistent flag's value becomes that parameter's default.
+// So "prog -verbose list" is equivalent to "prog list -verbose"
+// when "list" has a -verbose flag.
+//
+// Each [Param] returned by PersistentFlags must be a flag,
+// with a name beginning with "-".
+type PersistentFlagger interface {
+	PersistentFlags() []Param
+}
+
+// persistentFlags is the state of the persistent flags parsed so far by [Run],
+// stored in the context.
+type persistentFlags struct {
+	params []Param                // all persistent flags in effect, outermost first
+	vals   map[string]interface{} // flag name -> value
+	set    map[string][]string    // flag name -> values (see setValues), for flags given on the command line
+}
+
+// Persistent returns the value of the named persistent flag
+// (see [PersistentFlagger])
+// parsed by [Run] on the way to the current subcommand.
+// The name may be given with or without its leading "-".
+//
+// If T is the type of the flag's value,
+// the result is that value.
+// For a flag of type [Value],
+// T may be flag.Value (or another interface the value implements),
+// or the type reported by its Get method (see [flag.Getter]).
+//
+// The boolean result is false if there is no such flag
+// or its value cannot be converted to type T.
+func Persistent[T any](ctx context.Context, name string) (T, bool) {
+	var zero T
+
+	pf, ok := ctx.Value(persistentKey).(*persistentFlags)
+	if !ok {
+		return zero, false
+	}
+	val, ok := pf.vals[strings.TrimLeft(name, "-")]
+	if !ok {
+		return zero, false
+	}
+	if v, ok := val.(T); ok {
+		return v, true
+	}
+	if getter, ok := val.(flag.Getter); ok {
+		if v, ok := getter.Get().(T); ok {
+			return v, true
+		}
+	}
+	return zero, false
+}
+
+// persistentParams returns the persistent flags in effect for c:
+// those of the Cmds that dispatched to it,
+// plus those of c, if it is a PersistentFlagger.
+func persistentParams(ctx context.Context, c Cmd) ([]Param, error) {
+	var params []Param
+	if outer, _ := ctx.Value(persistentKey).(*persistentFlags); outer != nil {
+		params = append(params, outer.params...)
+	}
+	if pf, ok := cmdAs[PersistentFlagger](c); ok {
+		seen := make(map[string]bool, len(params))
+		for _, p := range params {
+			seen[strings.TrimLeft(p.Name, "-")] = true
+		}
+		for _, p := range pf.PersistentFlags() {
+			if !strings.HasPrefix(p.Name, "-") {
+				return nil, fmt.Errorf("persistent parameter %s is not a flag", p.Name)
+			}
+			if name := strings.TrimLeft(p.Name, "-"); !seen[name] {
+				// An outer Cmd's persistent flag takes precedence over one with the same name here.
+				seen[name] = true
+				params = append(params, p)
+			}
+		}
+	}
+	return params, nil
+}
+
+// parsePersistentFlags parses any persistent flags at the beginning of args:
+// those of c, if it is a PersistentFlagger,
+// and those of the Cmds that dispatched to it.
+// It returns the remaining args
+// and a context containing the values of all the persistent flags in effect.
+func parsePersistentFlags(ctx context.Context, c Cmd, args []string) (context.Context, []string, error) {
+	outer, _ := ctx.Value(persistentKey).(*persistentFlags)
+
+	params, err := persistentParams(ctx, c)
+	if err != nil {
+		return ctx, args, err
+	}
+	if len(params) == 0 {
+		return ctx, args, nil
+	}
+
+	fs, ptrs, _, err := ToFlagSet(params)
+	if err != nil {
+		return ctx, args, fmt.Errorf("constructing persistent flags: %w", err)
+	}
+	err = parseFlags(ctx, fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ctx, args, fmt.Errorf("parsing args: %w", err)
+	}
+	if err != nil {
+		return ctx, args, newFlagErr(ctx, fs, params, err)
+	}
+
+	inner := &persistentFlags{
+		params: params,
+		vals:   make(map[string]interface{}, len(params)),
+		set:    make(map[string][]string),
+	}
+	if outer != nil {
+		for name, val := range outer.vals {
+			inner.vals[name] = val
+		}
+		for name, val := range outer.set {
+			inner.set[name] = val
+		}
+	}
+
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+		inner.set[f.Name] = setValues(f)
+	})
+	for i, p := range params {
+		name := strings.TrimLeft(p.Name, "-")
+		if _, ok := inner.vals[name]; ok && !given[name] {
+			continue
+		}
+		if ptr := ptrs[i]; p.Type == Value {
+			inner.vals[name] = ptr.Interface()
+		} else {
+			inner.vals[name] = ptr.Elem().Interface()
+		}
+	}
+
+	return context.WithValue(ctx, persistentKey, inner), fs.Args(), nil
+}
+
+// applyPersistentFlags sets the flags in fs from the persistent flags given on the command line,
+// if any.
+func applyPersistentFlags(ctx context.Context, fs *flag.FlagSet) error {
+	pf, ok := ctx.Value(persistentKey).(*persistentFlags)
+	if !ok {
+		return nil
+	}
+	for _, p := range pf.params {
+		name := strings.TrimLeft(p.Name, "-")
+		vals, ok := pf.set[name]
+		if !ok || fs.Lookup(name) == nil {
+			continue
+		}
+		for _, val := range vals {
+			if err := fs.Set(name, val); err != nil {
+				return fmt.Errorf("setting -%s from persistent flag: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type persistenttestcmd struct {
+	got *persistentResult
+}
+
+type persistentResult struct {
+	verbose, local bool
+	level          int
+}
+
+func (c persistenttestcmd) PersistentFlags() []Param {
+	return Params(
+		"-verbose", Bool, false, "be verbose",
+		"-level", Int, 1, "level",
+	)
+}
+
+func (c persistenttestcmd) Subcmds() Map {
+	return Commands(
+		"a", func(ctx context.Context, verbose bool, _ []string) error {
+			c.got.local = verbose
+			c.got.verbose, _ = Persistent[bool](ctx, "verbose")
+			c.got.level, _ = Persistent[int](ctx, "-level")
+			return nil
+		}, "do a", Params(
+			"-verbose", Bool, false, "be verbose",
+		),
+		"sub", Subcmd{Sub: persistentsubcmd{got: c.got}},
+	)
+}
+
+type persistentsubcmd struct {
+	got *persistentResult
+}
+
+func (c persistentsubcmd) Subcmds() Map {
+	return Commands(
+		"b", func(ctx context.Context, _ []string) error {
+			c.got.verbose, _ = Persistent[bool](ctx, "verbose")
+			c.got.level, _ = Persistent[int](ctx, "level")
+			return nil
+		}, "do b", nil,
+	)
+}
+
+func TestPersistentFlags(t *testing.T) {
+	cases := []struct {
+		args []string
+		want persistentResult
+	}{{
+		args: []string{"a"},
+		want: persistentResult{level: 1},
+	}, {
+		args: []string{"-verbose", "a"},
+		want: persistentResult{verbose: true, local: true, level: 1},
+	}, {
+		args: []string{"-level", "3", "a", "-verbose"},
+		want: persistentResult{local: true, level: 3},
+	}, {
+		args: []string{"-verbose", "sub", "b"},
+		want: persistentResult{verbose: true, level: 1},
+	}, {
+		args: []string{"-level", "2", "sub", "-level", "5", "-verbose", "b"},
+		want: persistentResult{verbose: true, level: 5},
+	}, {
+		args: []string{"-level", "2", "sub", "-verbose", "b"},
+		want: persistentResult{verbose: true, level: 2},
+	}}
+
+	for _, tc := range cases {
+		t.Run("", func(t *testing.T) {
+			var got persistentResult
+			if err := Run(context.Background(), persistenttestcmd{got: &got}, tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("bad", func(t *testing.T) {
+		var got persistentResult
+		err := Run(context.Background(), persistenttestcmd{got: &got}, []string{"-level", "x", "a"})
+		var ferr FlagErr
+		if !errors.As(err, &ferr) {
+			t.Fatalf("got %v, want FlagErr", err)
+		}
+		if ferr.Flag != "level" {
+			t.Errorf(`got flag "%s", want "level"`, ferr.Flag)
+		}
+	})
+}
+
+type persistentmulticmd struct {
+	tags   *[]string
+	labels *map[string]string
+}
+
+func (c persistentmulticmd) PersistentFlags() []Param {
+	return Params(
+		"-tag", Strings, []string(nil), "tags",
+		"-label", StringMap, map[string]string(nil), "labels",
+	)
+}
+
+func (c persistentmulticmd) Subcmds() Map {
+	return Commands(
+		"x", func(_ context.Context, tags []string, labels map[string]string, _ []string) {
+			*c.tags, *c.labels = tags, labels
+		}, "", Params(
+			"-tag", Strings, []string(nil), "tags",
+			"-label", StringMap, map[string]string{"env": "dev"}, "labels",
+		),
+	)
+}
+
+func TestPersistentMultiValued(t *testing.T) {
+	var (
+		tags   []string
+		labels map[string]string
+		c      = persistentmulticmd{tags: &tags, labels: &labels}
+	)
+	if err := Run(context.Background(), c, []string{"-tag", "a", "-tag", "b", "-label", "k=v", "-label", "j=w", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("got tags %v, want [a b]", tags)
+	}
+	if want := map[string]string{"env": "dev", "k": "v", "j": "w"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("got labels %v, want %v", labels, want)
+	}
+
+	if err := Run(context.Background(), c, []string{"-tag", "a", "-tag", "b", "x", "-tag", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"c"}) {
+		t.Errorf("with command-line flag, got tags %v, want [c]", tags)
+	}
+}
+
+func TestPersistentFlagErrors(t *testing.T) {
+	var (
+		c      = persistenttestcmd{got: new(persistentResult)}
+		stderr = new(strings.Builder)
+		opts   = []Option{WithProgName("prog"), WithStdio(nil, nil, stderr)}
+	)
+
+	err := Run(context.Background(), c, []string{"-bogus", "a"}, opts...)
+	var ferr FlagErr
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got %v, want FlagErr", err)
+	}
+	if got, want := err.Error(), "parsing flags: flag provided but not defined: -bogus"; got != want {
+		t.Errorf(`got error "%s", want "%s"`, got, want)
+	}
+	if want := "prog: flag provided but not defined: -bogus\nUsage of prog:\n"; !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("got stderr %q, want prefix %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	if err := Run(context.Background(), c, []string{"sub", "-bogus", "b"}, opts...); err == nil {
+		t.Error("got no error for bad persistent flag after sub")
+	}
+	if want := "prog sub: flag provided but not defined: -bogus\n"; !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("got stderr %q, want prefix %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	err = Run(context.Background(), c, []string{"-verbose", "-h"}, opts...)
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected output on stderr: %q", stderr.String())
+	}
+}
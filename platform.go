@@ -0,0 +1,34 @@
+package subcmd
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// OnlyOn produces a function suitable for the Supported field of a [Subcmd].
+// It reports that the subcommand is supported only on the given platforms.
+// Each platform is either an operating system ("linux"),
+// an architecture ("arm64"),
+// or both separated by a slash ("darwin/arm64"),
+// as in the values of runtime.GOOS and runtime.GOARCH.
+//
+// The reason given for an unsupported subcommand
+// is like "not supported on windows/amd64".
+func OnlyOn(platforms ...string) func() (bool, string) {
+	return func() (bool, string) {
+		for _, p := range platforms {
+			if platformMatches(p, runtime.GOOS, runtime.GOARCH) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+func platformMatches(platform, goos, goarch string) bool {
+	if os, arch, ok := strings.Cut(platform, "/"); ok {
+		return os == goos && arch == goarch
+	}
+	return platform == goos || platform == goarch
+}
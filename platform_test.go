@@ -0,0 +1,54 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPlatformMatches(t *testing.T) {
+	cases := []struct {
+		platform string
+		want     bool
+	}{
+		{"linux", true},
+		{"amd64", true},
+		{"linux/amd64", true},
+		{"linux/arm64", false},
+		{"windows", false},
+	}
+	for _, tc := range cases {
+		if got := platformMatches(tc.platform, "linux", "amd64"); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.platform, got, tc.want)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Map{
+			"here":  Subcmd{F: func(context.Context, []string) {}, Supported: OnlyOn(runtime.GOOS)},
+			"there": Subcmd{F: func(context.Context, []string) {}, Supported: OnlyOn("plan9/mips")},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"here"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Run(context.Background(), c, []string{"there"})
+	var u *UnsupportedErr
+	if !errors.As(err, &u) {
+		t.Fatalf("got %v, want *UnsupportedErr", err)
+	}
+	if want := "not supported on " + runtime.GOOS + "/" + runtime.GOARCH; u.Reason != want {
+		t.Errorf(`got reason "%s", want "%s"`, u.Reason, want)
+	}
+
+	err = Run(context.Background(), c, []string{"help"})
+	if err == nil || strings.Contains(err.Error(), "there") {
+		t.Errorf("got %v, want help without unsupported subcommand", err)
+	}
+}
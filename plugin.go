@@ -0,0 +1,44 @@
+package subcmd
+
+import "context"
+
+// ExecPlugin runs the plugin executable for the subcommand name of c
+// (see [Prefixer]),
+// just as [Run] does when it encounters an unknown subcommand,
+// but deliberately rather than as a fallback.
+// This is useful for applications that want to dispatch to plugins
+// under their own control,
+// such as from a REPL,
+// or to try a plugin before a built-in subcommand of the same name.
+//
+// The executable is c.Prefix() plus name,
+// found in $PATH,
+// and it is run with args as its arguments.
+// If it is not found,
+// the error wraps [os/exec.ErrNotFound].
+//
+// The plugin's standard input, output, and error
+// may be set with [WithStdio],
+// and additions to its environment with [WithPluginEnv].
+// The plugin also receives the usual plugin environment variables;
+// see [ProtocolEnvVar].
+//
+// On platforms that cannot run subprocesses,
+// the error wraps [errors.ErrUnsupported].
+func ExecPlugin(ctx context.Context, c Prefixer, name string, args []string, opts ...Option) error {
+	return runWithOptions(ctx, opts, func(ctx context.Context) error {
+		return execPrefixed(ctx, c, name, args)
+	})
+}
+
+// WithPluginEnv is an [Option] that adds settings,
+// each of the form "KEY=value",
+// to the environment of plugin subprocesses,
+// whether run by [ExecPlugin] or by [Run] (see [Prefixer]).
+// Settings added this way take precedence over inherited ones with the same key.
+func WithPluginEnv(env ...string) Option {
+	return func(c *config) error {
+		c.pluginEnv = append(c.pluginEnv, env...)
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !js && !wasip1 && !windows
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecPlugin(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testSetenv("PATH", os.Getenv("PATH")+":"+filepath.Join(wd, "testdata"))()
+
+	ctx := context.Background()
+
+	out := new(strings.Builder)
+	err = ExecPlugin(ctx, testPrefixMainCmd{}, "echoenv", []string{"a", "b"}, WithStdio(nil, out, nil), WithPluginEnv("PLUGIN_EXTRA=extra"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "extra a b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	err = ExecPlugin(ctx, testPrefixMainCmd{}, "nosubcmd", nil)
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("got %v, want %v", err, exec.ErrNotFound)
+	}
+}
@@ -0,0 +1,109 @@
+//go:build !js && !wasip1
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// runPrefixed looks for the executable prefix+name in $PATH and runs it.
+// If it is not found, the result is unknownSubcmdErr.
+//
+// On Windows, the search (done by exec.LookPath) tries each of the extensions in $PATHEXT,
+// so the executable may be prefix+name+".exe", prefix+name+".bat", and so on.
+//
+// A name containing a path separator
+// (which on Windows includes both / and \)
+// is never found,
+// nor is an executable that would be found only relative to the current directory
+// (see exec.ErrDot).
+func runPrefixed(ctx context.Context, c Cmd, prefix, name string, args []string, unknownSubcmdErr error) error {
+	path, err := lookPlugin(prefix, name)
+	if errors.Is(err, exec.ErrNotFound) {
+		return unknownSubcmdErr
+	}
+	if err != nil {
+		return err
+	}
+	return execPlugin(ctx, c, path, name, args)
+}
+
+// execPrefixed is the implementation of ExecPlugin.
+func execPrefixed(ctx context.Context, c Prefixer, name string, args []string) error {
+	path, err := lookPlugin(c.Prefix(), name)
+	if err != nil {
+		return err
+	}
+	return execPlugin(ctx, c, path, name, args)
+}
+
+// lookPlugin finds the executable for the plugin prefix+name.
+// See runPrefixed for the rules.
+// If there is none, the error wraps exec.ErrNotFound.
+func lookPlugin(prefix, name string) (string, error) {
+	if filepath.Base(name) != name {
+		return "", &exec.Error{Name: prefix + name, Err: exec.ErrNotFound}
+	}
+	path, err := cachedLookPath(prefix + name)
+	if errors.Is(err, exec.ErrDot) {
+		return "", &exec.Error{Name: prefix + name, Err: exec.ErrNotFound}
+	}
+	if err != nil {
+		return "", fmt.Errorf("looking for %s%s: %w", prefix, name, err)
+	}
+	return path, nil
+}
+
+// execPlugin runs the plugin executable at path,
+// invoked as the subcommand name of c.
+func execPlugin(ctx context.Context, c interface{}, path, name string, args []string) error {
+	execCmd := exec.CommandContext(ctx, path, args...)
+	execCmd.Stdin, execCmd.Stdout, execCmd.Stderr = Stdin(ctx), Stdout(ctx), Stderr(ctx)
+
+	if grace := getConfig(ctx).gracePeriod; grace > 0 {
+		execCmd.Cancel = func() error {
+			if err := execCmd.Process.Signal(os.Interrupt); err != nil {
+				return execCmd.Process.Kill()
+			}
+			return nil
+		}
+		execCmd.WaitDelay = grace
+	}
+
+	val, version, err := encodeEnv(c)
+	if err != nil {
+		return fmt.Errorf("marshaling Cmd: %w", err)
+	}
+	execCmd.Env = append(os.Environ(), cmdEnvVar(c)+"="+val)
+
+	protoEnv, err := protocolEnv(ctx, name, version)
+	if err != nil {
+		return fmt.Errorf("marshaling command path: %w", err)
+	}
+	execCmd.Env = append(execCmd.Env, protoEnv...)
+
+	flagsJSON, err := parentFlagsJSON(ctx)
+	if err != nil {
+		return fmt.Errorf("marshaling flags: %w", err)
+	}
+	execCmd.Env = append(execCmd.Env, FlagsEnvVar+"="+string(flagsJSON))
+
+	if profiling.Load() {
+		// Only the outermost process profiles (see WithProfiling).
+		execCmd.Env = append(execCmd.Env, ProfileEnvVar+"=")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		execCmd.Env = append(execCmd.Env, DeadlineEnvVar+"="+deadline.Format(time.RFC3339Nano))
+	}
+
+	execCmd.Env = append(execCmd.Env, getConfig(ctx).pluginEnv...)
+
+	return execCmd.Run()
+}
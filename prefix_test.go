@@ -77,6 +77,18 @@ func TestPrefix(t *testing.T) {
 	})
 }
 
+func TestPrefixSeparator(t *testing.T) {
+	for _, name := range []string{"a/b", "../subcmd", ""} {
+		t.Run(name, func(t *testing.T) {
+			err := Run(context.Background(), testPrefixMainCmd{}, []string{name})
+			var u *UnknownSubcmdErr
+			if !errors.As(err, &u) {
+				t.Errorf("got %v, want UnknownSubcmdErr", err)
+			}
+		})
+	}
+}
+
 type testPrefixMainCmd struct {
 	Data string `json:"data"`
 }
@@ -0,0 +1,21 @@
+//go:build js || wasip1
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// runPrefixed is a stub for platforms that cannot run subprocesses.
+// It treats every prefixed subcommand as not found.
+func runPrefixed(_ context.Context, _ Cmd, _, _ string, _ []string, unknownSubcmdErr error) error {
+	return unknownSubcmdErr
+}
+
+// execPrefixed is the implementation of ExecPlugin.
+// It always fails on platforms that cannot run subprocesses.
+func execPrefixed(_ context.Context, c Prefixer, name string, _ []string) error {
+	return fmt.Errorf("running %s%s: %w", c.Prefix(), name, errors.ErrUnsupported)
+}
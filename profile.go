@@ -0,0 +1,135 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync/atomic"
+)
+
+// ProfileEnvVar is the name of an environment variable
+// that has the same effect as [WithProfiling] when set to a directory name.
+// This allows any program built with this package to be profiled
+// without changes to its code.
+const ProfileEnvVar = "SUBCMD_PROFILE"
+
+// WithProfiling is an [Option] that causes [Run] to capture profiles
+// while the subcommand function runs,
+// writing them to files in dir
+// (which is created if necessary).
+// The files are named for the subcommand's [CommandPath],
+// as in "db-migrate.cpu.pprof",
+// with these suffixes:
+//
+//   - .cpu.pprof: a CPU profile;
+//   - .heap.pprof: a heap profile taken after the function returns;
+//   - .trace: an execution trace.
+//
+// The profiles can be examined with "go tool pprof" and "go tool trace".
+// Failures to capture a profile are reported as warnings (see [WithWarningHandler]),
+// and do not prevent the subcommand from running.
+//
+// Profiles are captured only by the outermost subcommand being profiled:
+// not by nested calls to Run from its function,
+// nor by plugin subprocesses that it runs (see [Prefixer]).
+//
+// See also [ProfileEnvVar].
+func WithProfiling(dir string) Option {
+	return func(c *config) error {
+		c.profileDir = dir
+		return nil
+	}
+}
+
+// profiling is true while profile is capturing profiles.
+// CPU profiles and execution traces are process-wide,
+// so only the outermost call to profile
+// (e.g. not one in a nested call to Run from a subcommand function)
+// captures them.
+var profiling atomic.Bool
+
+// profile calls f,
+// capturing profiles if requested with WithProfiling or ProfileEnvVar.
+func profile(ctx context.Context, f func() error) error {
+	dir := getConfig(ctx).profileDir
+	if dir == "" {
+		dir = os.Getenv(ProfileEnvVar)
+	}
+	if dir == "" {
+		return f()
+	}
+
+	if !profiling.CompareAndSwap(false, true) {
+		logDebug(ctx, "already profiling")
+		return f()
+	}
+	defer profiling.Store(false)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		warn(ctx, "creating profile directory: %s", err)
+		return f()
+	}
+	base := filepath.Join(dir, strings.Join(CommandPath(ctx), "-"))
+
+	logDebug(ctx, "profiling", "base", base)
+
+	// Profiles are collected in memory
+	// and written out only if they were started successfully,
+	// to avoid leaving empty files behind.
+
+	cpuBuf := new(bytes.Buffer)
+	if err := pprof.StartCPUProfile(cpuBuf); err != nil {
+		warn(ctx, "starting CPU profile: %s", err)
+		cpuBuf = nil
+	}
+
+	traceBuf := new(bytes.Buffer)
+	if err := trace.Start(traceBuf); err != nil {
+		warn(ctx, "starting trace: %s", err)
+		traceBuf = nil
+	}
+
+	err := f()
+
+	if traceBuf != nil {
+		trace.Stop()
+		if err := os.WriteFile(base+".trace", traceBuf.Bytes(), 0644); err != nil {
+			warn(ctx, "writing trace: %s", err)
+		}
+	}
+	if cpuBuf != nil {
+		pprof.StopCPUProfile()
+		if err := os.WriteFile(base+".cpu.pprof", cpuBuf.Bytes(), 0644); err != nil {
+			warn(ctx, "writing CPU profile: %s", err)
+		}
+	}
+
+	if err := writeHeapProfile(base + ".heap.pprof"); err != nil {
+		warn(ctx, "writing heap profile: %s", err)
+	}
+
+	return err
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	runtime.GC() // get up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	return nil
+}
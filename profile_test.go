@@ -0,0 +1,63 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiling(t *testing.T) {
+	dir := t.TempDir()
+
+	c := nestedtestcmd{}
+	if err := Run(context.Background(), c, []string{"db", "migrate", "dir"}, WithProfiling(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, suffix := range []string{".cpu.pprof", ".heap.pprof", ".trace"} {
+		info, err := os.Stat(filepath.Join(dir, "db-migrate"+suffix))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", info.Name())
+		}
+	}
+}
+
+func TestProfilingNested(t *testing.T) {
+	dir := t.TempDir()
+
+	var warnings []string
+	inner := nestedtestcmd{}
+	outer := testcmdfunc(func() Map {
+		return Commands("outer", func(ctx context.Context, _ []string) error {
+			return Run(ctx, inner, []string{"db", "migrate", "dir"})
+		}, "", nil)
+	})
+	err := Run(context.Background(), outer, []string{"outer"}, WithProfiling(dir), WithWarningHandler(func(_ context.Context, msg string) {
+		warnings = append(warnings, msg)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("got warnings %v", warnings)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{"outer.cpu.pprof", "outer.heap.pprof", "outer.trace"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %v, want %v", got, want)
+	}
+}
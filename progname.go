@@ -0,0 +1,27 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+)
+
+// WithProgName is an [Option] that sets the program name used in usage messages.
+// The default is os.Args[0],
+// which is not always appropriate,
+// e.g. for a "multi-call" binary invoked via differently named symlinks,
+// or for a binary built into a temporary directory by "go run" or "go test".
+func WithProgName(name string) Option {
+	return func(c *config) error {
+		c.progName = name
+		return nil
+	}
+}
+
+// ProgName returns the program name set with [WithProgName],
+// or os.Args[0] if none was set.
+func ProgName(ctx context.Context) string {
+	if name := getConfig(ctx).progName; name != "" {
+		return name
+	}
+	return os.Args[0]
+}
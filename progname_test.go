@@ -0,0 +1,25 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestProgName(t *testing.T) {
+	ctx := context.Background()
+
+	if got := ProgName(ctx); got != os.Args[0] {
+		t.Errorf(`got "%s", want "%s"`, got, os.Args[0])
+	}
+
+	err := Run(ctx, errtestcmd{}, []string{"help", "bb"}, WithProgName("myprog"))
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if got, want := h.Error(), "usage: myprog bb"; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+}
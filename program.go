@@ -0,0 +1,79 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Program is a precompiled form of a [Cmd],
+// produced by [Compile].
+// Its Run method is equivalent to calling [Run] on the original Cmd,
+// but avoids repeating the work of building the Cmd's [Map],
+// checking the types of its subcommands' functions,
+// and validating their params for [ToFlagSet] on every call.
+// Each call still gets a fresh [flag.FlagSet],
+// built from a blueprint prepared by Compile.
+// This matters for REPLs, daemons, and servers that dispatch many command lines.
+//
+// A Program reflects the state of its Cmd at the time Compile was called.
+// It is safe for concurrent use
+// (provided the subcommand functions are).
+//
+// A Program is itself a Cmd,
+// so it can be used anywhere a Cmd can.
+// [Run] and the other functions in this package
+// see through it to the optional interfaces that the original Cmd implements
+// (such as [Prefixer], [Initer], and [PersistentFlagger]).
+type Program struct {
+	cmd      Cmd
+	subcmds  Map
+	compiled map[string]*compiled
+	subs     map[string]*Program
+}
+
+// Compile validates c and its subcommands with [Check],
+// prepares the flag sets for the subcommands' params,
+// recursively compiling any nested commands (see Subcmd.Sub),
+// and produces a [Program].
+func Compile(c Cmd) (*Program, error) {
+	p := &Program{
+		cmd:      c,
+		subcmds:  c.Subcmds(),
+		compiled: make(map[string]*compiled),
+		subs:     make(map[string]*Program),
+	}
+	for name, subcmd := range p.subcmds {
+		if subcmd.F == nil && subcmd.Sub != nil {
+			sub, err := Compile(subcmd.Sub)
+			if err != nil {
+				return nil, fmt.Errorf("compiling %s: %w", name, err)
+			}
+			p.subs[name] = sub
+			continue
+		}
+
+		if err := Check(subcmd); err != nil {
+			return nil, fmt.Errorf("checking subcommand %s: %w", name, err)
+		}
+		cs, err := compile(subcmd)
+		if err != nil {
+			return nil, fmt.Errorf("checking subcommand %s: %w", name, err)
+		}
+		p.compiled[name] = cs
+	}
+	return p, nil
+}
+
+// Subcmds implements [Cmd].
+// It returns the Map that the original Cmd produced when p was compiled.
+func (p *Program) Subcmds() Map {
+	return p.subcmds
+}
+
+// Run runs the subcommand of p named in args[0].
+// See [Run] for details.
+func (p *Program) Run(ctx context.Context, args []string, opts ...Option) error {
+	return runWithOptions(ctx, opts, func(ctx context.Context) error {
+		return dispatch(ctx, p.cmd, p.subcmds, p, args)
+	})
+}
@@ -0,0 +1,94 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	c := &countingcmd{}
+	p, err := Compile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := p.Run(ctx, []string{"bb"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.n != 1 {
+		t.Errorf("Subcmds called %d times, want 1", c.n)
+	}
+
+	err = p.Run(ctx, []string{"a"})
+	if !errors.Is(err, ErrTooFewArgs) {
+		t.Errorf("got %v, want %s", err, ErrTooFewArgs)
+	}
+
+	err = p.Run(ctx, []string{"dddd"})
+	var u *UnknownSubcmdErr
+	if !errors.As(err, &u) {
+		t.Errorf("got %v, want *UnknownSubcmdErr", err)
+	}
+}
+
+func TestCompileNested(t *testing.T) {
+	var gotDir string
+	p, err := Compile(nestedtestcmd{migrate: func(_ context.Context, _ int, dir string, _ []string) { gotDir = dir }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Run(context.Background(), []string{"db", "migrate", "there"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != "there" {
+		t.Errorf(`got dir "%s", want "there"`, gotDir)
+	}
+}
+
+func TestCompileErr(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(context.Context, int, []string) {}, "", nil)
+	})
+	_, err := Compile(c)
+	var f FuncTypeErr
+	if !errors.As(err, &f) {
+		t.Errorf("got %v, want FuncTypeErr", err)
+	}
+}
+
+type testcmdfunc func() Map
+
+func (f testcmdfunc) Subcmds() Map { return f() }
+
+func TestCompileRepeated(t *testing.T) {
+	var got []string
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(_ context.Context, s []string, _ []string) { got = s }, "", Params("-s", Strings, []string{"a"}, "strings"))
+	})
+	p, err := Compile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"x", "-s", "b", "-s", "c"}, want: []string{"b", "c"}},
+		{args: []string{"x"}, want: []string{"a"}},
+		{args: []string{"x", "-s", "d"}, want: []string{"d"}},
+	}
+	for _, tc := range cases {
+		if err := p.Run(context.Background(), tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("after %v got %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
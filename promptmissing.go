@@ -0,0 +1,47 @@
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+)
+
+// WithPromptMissing is an [Option] that causes [Run],
+// when the arguments run out before all of a subcommand's required positional parameters are supplied,
+// to prompt for each missing one
+// (showing its type and doc string,
+// and re-prompting if a value cannot be parsed)
+// instead of returning [ErrTooFewArgs].
+//
+// Prompts are written to [Stdout] and responses are read from [Stdin].
+// This option has no effect if the run is not interactive (see [Interactive]).
+// See also [WithWizard].
+func WithPromptMissing() Option {
+	return func(c *config) error {
+		c.promptMissing = true
+		return nil
+	}
+}
+
+// promptMissing prompts for the value of the required positional parameter p,
+// if WithPromptMissing is in effect and the run is interactive,
+// and appends it to args.
+// Otherwise it returns args unchanged.
+//
+// Responses are read with *r,
+// which is created on first use
+// and must be shared by all the prompts in a run,
+// since it may read ahead in Stdin.
+func promptMissing(ctx context.Context, r **bufio.Reader, p Param, args []string) ([]string, error) {
+	if !getConfig(ctx).promptMissing || !Interactive(ctx) {
+		return args, nil
+	}
+	if *r == nil {
+		*r = bufio.NewReader(Stdin(ctx))
+	}
+	val, err := prompt(*r, Stdout(ctx), p)
+	if err != nil {
+		return nil, fmt.Errorf("reading value for %s: %w", p.Name, err)
+	}
+	return append(args, val), nil
+}
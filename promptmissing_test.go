@@ -0,0 +1,69 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptMissing(t *testing.T) {
+	var (
+		gotDur  time.Duration
+		gotBool bool
+	)
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"a", func(_ context.Context, d time.Duration, b bool, _ []string) {
+				gotDur, gotBool = d, b
+			}, "do a", Params(
+				"dur", Duration, time.Duration(0), "how long",
+				"b?", Bool, false, "optional bool",
+			),
+		)
+	})
+
+	var (
+		stdin  = strings.NewReader("soon\n5s\n")
+		stdout bytes.Buffer
+	)
+	err := Run(context.Background(), c, []string{"a"}, WithPromptMissing(), WithInteractive(true), WithStdio(stdin, &stdout, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDur != 5*time.Second {
+		t.Errorf("got duration %s, want 5s", gotDur)
+	}
+	if gotBool {
+		t.Error("got b true, want false")
+	}
+	if out := stdout.String(); !strings.HasPrefix(out, "dur (time.Duration) - how long: Invalid value: ") {
+		t.Errorf(`got output "%s"`, out)
+	}
+
+	t.Run("two missing", func(t *testing.T) {
+		var gotA, gotB string
+		c := testcmdfunc(func() Map {
+			return Commands("x", func(_ context.Context, a, b string, _ []string) { gotA, gotB = a, b }, "", Params(
+				"a", String, "", "",
+				"b", String, "", "",
+			))
+		})
+		err := Run(context.Background(), c, []string{"x"}, WithPromptMissing(), WithInteractive(true), WithStdio(strings.NewReader("one\ntwo\n"), new(bytes.Buffer), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotA != "one" || gotB != "two" {
+			t.Errorf(`got a="%s", b="%s"; want "one", "two"`, gotA, gotB)
+		}
+	})
+
+	t.Run("noninteractive", func(t *testing.T) {
+		err := Run(context.Background(), c, []string{"a"}, WithPromptMissing(), WithInteractive(false))
+		if !errors.Is(err, ErrTooFewArgs) {
+			t.Errorf("got %v, want ErrTooFewArgs", err)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ProtocolEnvVar is the name of the environment variable used by [Run]
+// to tell a plugin subprocess (see [Prefixer])
+// which version of the plugin protocol the parent speaks.
+// The protocol comprises the names and formats of the environment variables
+// passed to the plugin:
+// [EnvVar] (or the name given by [EnvVarNamer]), [FlagsEnvVar], [DeadlineEnvVar], [CommandPathEnvVar], and this one.
+//
+// [ParseEnv] and [ParsePluginEnv] check it,
+// so that a plugin built with an older version of this package
+// fails with a [ProtocolErr]
+// rather than misreading its environment.
+const ProtocolEnvVar = "SUBCMD_PROTOCOL"
+
+// ProtocolVersion is the version of the plugin protocol spoken by this package.
+// See [ProtocolEnvVar].
+//
+// Version 0 is what parents predating [ProtocolEnvVar] speak.
+// It is the same as version 1 except that it lacks ProtocolEnvVar and [CommandPathEnvVar].
+// Version 2 adds encodings of [EnvVar] other than plain JSON (see [EnvEncoder]).
+// A parent announces version 1 when it uses plain JSON,
+// so that plugins that speak only version 1 keep working.
+const ProtocolVersion = 2
+
+// CommandPathEnvVar is the name of the environment variable used by [Run]
+// to pass to a plugin subprocess (see [Prefixer])
+// the sequence of subcommand names by which it was invoked,
+// ending with the plugin's own subcommand name
+// (so "mytool db ext" running the plugin "mytool-ext" passes ["db", "ext"]).
+// The value is a JSON array of strings.
+// Use [PluginCommandPath] to decode it.
+const CommandPathEnvVar = "SUBCMD_PATH"
+
+// ProtocolErr is the error returned by [ParseEnv] and [ParsePluginEnv]
+// when the parent process speaks a newer version of the plugin protocol than this package does.
+// See [ProtocolEnvVar].
+type ProtocolErr struct {
+	// Version is the parent's protocol version.
+	Version int
+}
+
+func (e ProtocolErr) Error() string {
+	return fmt.Sprintf("parent process uses plugin protocol version %d, want at most %d", e.Version, ProtocolVersion)
+}
+
+// checkProtocol checks that this package can speak the protocol version of the parent process.
+func checkProtocol() error {
+	val := os.Getenv(ProtocolEnvVar)
+	if val == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(val)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", ProtocolEnvVar, err)
+	}
+	if v > ProtocolVersion {
+		return ProtocolErr{Version: v}
+	}
+	return nil
+}
+
+// PluginCommandPath returns the sequence of subcommand names
+// by which the parent process invoked this plugin.
+// See [CommandPathEnvVar].
+// The result is nil if this process is not a plugin,
+// or if its parent predates CommandPathEnvVar.
+func PluginCommandPath() ([]string, error) {
+	if err := checkProtocol(); err != nil {
+		return nil, err
+	}
+	val := os.Getenv(CommandPathEnvVar)
+	if val == "" {
+		return nil, nil
+	}
+	var path []string
+	if err := json.Unmarshal([]byte(val), &path); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", CommandPathEnvVar, err)
+	}
+	return path, nil
+}
+
+// protocolEnv returns the environment settings that identify the plugin protocol
+// for a plugin subprocess invoked as the subcommand name.
+// The version is the lowest one that describes what is passed to the subprocess,
+// so that plugins that speak only older versions work whenever possible.
+func protocolEnv(ctx context.Context, name string, version int) ([]string, error) {
+	path, err := json.Marshal(append(CommandPath(ctx), name))
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		ProtocolEnvVar + "=" + strconv.Itoa(version),
+		CommandPathEnvVar + "=" + string(path),
+	}, nil
+}
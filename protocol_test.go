@@ -0,0 +1,103 @@
+//go:build !js && !wasip1 && !windows
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProtocolEnv(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testSetenv("PATH", os.Getenv("PATH")+":"+filepath.Join(wd, "testdata"))()
+
+	out := new(strings.Builder)
+	if err := Run(context.Background(), testPrefixMainCmd{}, []string{"path"}, WithStdio(nil, out, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `1 ["path"]`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// An encoding other than JSON requires protocol version 2.
+	out.Reset()
+	if err := Run(context.Background(), testEnvEncodingCmd{encoding: "gob"}, []string{"path"}, WithStdio(nil, out, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `2 ["path"]`+"\n"; got != want {
+		t.Errorf("with gob encoding, got %q, want %q", got, want)
+	}
+}
+
+func TestPrefixEnvVarName(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testSetenv("PATH", os.Getenv("PATH")+":"+filepath.Join(wd, "testdata"))()
+
+	out := new(strings.Builder)
+	c := testEnvNameCmd{testPrefixMainCmd: testPrefixMainCmd{Data: "xyz"}}
+	if err := Run(context.Background(), c, []string{"envname"}, WithStdio(nil, out, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `{"data":"xyz"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseEnvProtocol(t *testing.T) {
+	defer testSetenv(EnvVar, `{"data": "xyz"}`)()
+
+	t.Run("current", func(t *testing.T) {
+		defer testSetenv(ProtocolEnvVar, "1")()
+		defer testSetenv(CommandPathEnvVar, `["db", "ext"]`)()
+
+		var parent testPrefixMainCmd
+		if err := ParseEnv(&parent); err != nil {
+			t.Fatal(err)
+		}
+		path, err := PluginCommandPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"db", "ext"}, path); diff != "" {
+			t.Errorf("command path mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		defer testSetenv(ProtocolEnvVar, "")()
+
+		var parent testPrefixMainCmd
+		if err := ParseEnv(&parent); err != nil {
+			t.Fatal(err)
+		}
+		if parent.Data != "xyz" {
+			t.Errorf(`got data "%s", want "xyz"`, parent.Data)
+		}
+	})
+
+	t.Run("future", func(t *testing.T) {
+		defer testSetenv(ProtocolEnvVar, "3")()
+
+		var parent testPrefixMainCmd
+		err := ParseEnv(&parent)
+		var perr ProtocolErr
+		if !errors.As(err, &perr) {
+			t.Fatalf("got %v, want ProtocolErr", err)
+		}
+		if perr.Version != 3 {
+			t.Errorf("got version %d, want 3", perr.Version)
+		}
+	})
+}
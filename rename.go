@@ -0,0 +1,16 @@
+package subcmd
+
+// Renamer is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// and a call to [Run] encounters an unknown subcommand name,
+// Run checks whether that name is a key in the map returned by Renames.
+// If so,
+// Run emits a warning that the name is deprecated
+// and runs the subcommand with the new name.
+//
+// This allows subcommands to be renamed without immediately breaking
+// scripts that use the old names.
+type Renamer interface {
+	// Renames maps old subcommand names to new ones.
+	Renames() map[string]string
+}
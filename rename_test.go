@@ -0,0 +1,58 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestRenamer(t *testing.T) {
+	var (
+		got    []string
+		stderr bytes.Buffer
+	)
+	c := renametestcmd(func(ctx context.Context, _ []string) {
+		got = CommandPath(ctx)
+	})
+
+	if err := Run(context.Background(), c, []string{"old"}, WithStdio(nil, nil, &stderr), WithProgName("prog")); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "new" {
+		t.Errorf("got path %v, want [new]", got)
+	}
+	if got, want := stderr.String(), "prog: warning: subcommand \"old\" is deprecated, use \"new\" instead\n"; got != want {
+		t.Errorf(`got warning "%s", want "%s"`, got, want)
+	}
+}
+
+type renametestcmd func(context.Context, []string)
+
+func (c renametestcmd) Subcmds() Map {
+	return Commands("new", (func(context.Context, []string))(c), "", nil)
+}
+
+func (renametestcmd) Renames() map[string]string {
+	return map[string]string{"old": "new"}
+}
+
+func TestWarningHandler(t *testing.T) {
+	var (
+		got    []string
+		stderr bytes.Buffer
+	)
+	c := renametestcmd(func(context.Context, []string) {})
+	h := func(_ context.Context, msg string) {
+		got = append(got, msg)
+	}
+
+	if err := Run(context.Background(), c, []string{"old"}, WithStdio(nil, nil, &stderr), WithWarningHandler(h)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != `subcommand "old" is deprecated, use "new" instead` {
+		t.Errorf("got warnings %v", got)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf(`got stderr "%s", want nothing`, stderr.String())
+	}
+}
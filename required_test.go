@@ -0,0 +1,97 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	var gotName string
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, name, region string, _ bool, _ []string) { gotName = name },
+				Params: []Param{
+					{Name: "-name", Type: String, Default: "", Required: true},
+					{Name: "-region", Type: String, Default: "", Required: true},
+					{Name: "-v", Type: Bool, Default: false},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-name", "foo", "-region", "", "-v"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotName != "foo" {
+		t.Errorf(`got name "%s", want "foo"`, gotName)
+	}
+
+	err := Run(context.Background(), c, []string{"x", "-v"})
+	var e MissingFlagErr
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want MissingFlagErr", err)
+	}
+	if want := []string{"-name", "-region"}; !reflect.DeepEqual(e.Flags, want) {
+		t.Errorf("got missing flags %v, want %v", e.Flags, want)
+	}
+
+	defer testSetenv("MYAPP_X_NAME", "env")()
+	err = Run(context.Background(), c, []string{"x"}, WithEnvPrefix("MYAPP"))
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want MissingFlagErr", err)
+	}
+	if want := []string{"-region"}; !reflect.DeepEqual(e.Flags, want) {
+		t.Errorf("with environment, got missing flags %v, want %v", e.Flags, want)
+	}
+
+	err = Run(context.Background(), c, []string{"help", "x"})
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want HelpRequestedErr", err)
+	}
+	if detail := h.Detail(); !strings.Contains(detail, " -name string -region string [-v]") {
+		t.Errorf("help usage does not show required flags:\n%s", detail)
+	}
+}
+
+func TestRequires(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(context.Context, string, string, string, []string) {},
+				Params: []Param{
+					{Name: "-key", Type: String, Default: "", Requires: []string{"-cert", "ca"}},
+					{Name: "-cert", Type: String, Default: ""},
+					{Name: "-ca", Type: String, Default: ""},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(context.Background(), c, []string{"x", "-key", "k", "-cert", "c", "-ca", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Run(context.Background(), c, []string{"x", "-key", "k", "-ca", "a"})
+	var e FlagRequiresErr
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want FlagRequiresErr", err)
+	}
+	if e.Flag != "-key" || !reflect.DeepEqual(e.Missing, []string{"-cert"}) {
+		t.Errorf("got %s requires %v, want -key requires [-cert]", e.Flag, e.Missing)
+	}
+	if got, want := e.Error(), "flag -key requires -cert"; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+
+	if err := Check(Subcmd{F: func(context.Context, string, []string) {}, Params: []Param{{Name: "-key", Type: String, Default: "", Requires: []string{"-nope"}}}}); err == nil {
+		t.Error("got no error for unknown required flag")
+	}
+}
@@ -0,0 +1,74 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	c := &resolvertestcmd{}
+	ctx := context.Background()
+
+	if err := Run(ctx, c, []string{"cmd42"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.got != "cmd42" {
+		t.Errorf(`got "%s", want "cmd42"`, c.got)
+	}
+	if c.listed {
+		t.Error("Subcmds was called during dispatch")
+	}
+
+	err := Run(ctx, c, []string{"help", "cmd7"})
+	var h *HelpRequestedErr
+	if !errors.As(err, &h) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	if got := h.Detail(); !strings.HasPrefix(got, "cmd7: command 7\n") {
+		t.Errorf("unexpected help detail %s", got)
+	}
+	if c.listed {
+		t.Error("Subcmds was called for help on a single subcommand")
+	}
+
+	err = Run(ctx, c, []string{"bogus"})
+	var u *UnknownSubcmdErr
+	if !errors.As(err, &u) {
+		t.Fatalf("got %v, want *UnknownSubcmdErr", err)
+	}
+	if got := u.Detail(); !strings.Contains(got, "cmd1  command 1\n") {
+		t.Errorf("unexpected unknown-subcommand detail %s", got)
+	}
+	if !c.listed {
+		t.Error("Subcmds was not called for the subcommand listing")
+	}
+}
+
+type resolvertestcmd struct {
+	got    string
+	listed bool
+}
+
+func (c *resolvertestcmd) Subcmds() Map {
+	c.listed = true
+	m := make(Map)
+	for i := 1; i <= 3; i++ {
+		name := "cmd" + string(rune('0'+i))
+		m[name], _ = c.Resolve(name)
+	}
+	return m
+}
+
+func (c *resolvertestcmd) Resolve(name string) (Subcmd, bool) {
+	if !strings.HasPrefix(name, "cmd") {
+		return Subcmd{}, false
+	}
+	return Subcmd{
+		F: func(context.Context, []string) {
+			c.got = name
+		},
+		Desc: "command " + strings.TrimPrefix(name, "cmd"),
+	}, true
+}
@@ -0,0 +1,102 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+)
+
+// WithSignals is an [Option] that causes [Run] to cancel the context
+// passed to the subcommand function
+// when the process receives any of the given signals
+// (or os.Interrupt or syscall.SIGTERM if none are given).
+// A second such signal exits the process immediately.
+// See [SignalContext].
+//
+// This is commonly combined with [WithGracePeriod].
+//
+// Only the outermost call to Run installs the signal handler.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(c *config) error {
+		c.handleSignals = true
+		c.signals = sigs
+		return nil
+	}
+}
+
+// SignalErr is the cause (see [context.Cause]) of the cancellation of a context
+// produced by [SignalContext] when a signal arrives.
+type SignalErr struct {
+	Signal os.Signal
+}
+
+func (e SignalErr) Error() string {
+	return fmt.Sprintf("received signal %s", e.Signal)
+}
+
+// SignalContext returns a copy of ctx that is canceled
+// when the process receives any of the given signals
+// (or os.Interrupt or syscall.SIGTERM if none are given),
+// with a [SignalErr] as the cause.
+// If another such signal arrives after that,
+// the process exits immediately,
+// with the status a shell conventionally reports for that signal
+// (128 plus the signal number).
+// This lets a user interrupt a program that is slow to shut down gracefully.
+//
+// The caller must call the returned [context.CancelFunc]
+// to stop relaying signals and release resources.
+func SignalContext(ctx context.Context, sigs ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-ch:
+			cancel(SignalErr{Signal: sig})
+		case <-stop:
+			return
+		}
+		select {
+		case sig := <-ch:
+			os.Exit(signalStatus(sig))
+		case <-stop:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		close(stop)
+		cancel(context.Canceled)
+	}
+}
+
+// signalStatus is the exit status conventionally reported by shells
+// for a process killed by sig.
+// Signals are integers on most platforms (see syscall.Signal) but not all.
+func signalStatus(sig os.Signal) int {
+	if v := reflect.ValueOf(sig); v.Kind() == reflect.Int {
+		return 128 + int(v.Int())
+	}
+	return 1
+}
+
+// withSignals applies SignalContext to ctx
+// if WithSignals is in effect and this is the outermost call to Run.
+func withSignals(ctx context.Context) (context.Context, context.CancelFunc) {
+	conf := getConfig(ctx)
+	if !conf.handleSignals || len(subcmdPairList(ctx)) > 0 {
+		return ctx, func() {}
+	}
+	return SignalContext(ctx, conf.signals...)
+}
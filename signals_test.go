@@ -0,0 +1,53 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send os.Interrupt on Windows")
+	}
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"wait", func(ctx context.Context, _ []string) error {
+				if err := proc.Signal(os.Interrupt); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				case <-time.After(10 * time.Second):
+					return errors.New("timed out")
+				}
+			}, "wait for a signal", nil,
+		)
+	})
+
+	err = Run(context.Background(), c, []string{"wait"}, WithSignals(os.Interrupt))
+	var serr SignalErr
+	if !errors.As(err, &serr) {
+		t.Fatalf("got %v, want SignalErr", err)
+	}
+	if serr.Signal != os.Interrupt {
+		t.Errorf("got signal %v, want %v", serr.Signal, os.Interrupt)
+	}
+}
+
+func TestSignalStatus(t *testing.T) {
+	if got := signalStatus(syscall.SIGINT); got != 130 {
+		t.Errorf("got %d, want 130", got)
+	}
+}
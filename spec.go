@@ -0,0 +1,290 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadSpec reads a JSON specification of subcommands from r
+// and binds it to funcs to produce a [Map].
+// The specification has the form of a [Description] marshaled as JSON,
+// such as [Describe] produces,
+// so that a program's command-line structure can be maintained outside of Go code.
+// See [Description.Map] for how funcs is used.
+//
+// Only JSON is supported.
+// Reading YAML would make every user of this package
+// depend on a YAML library;
+// a caller keeping its spec in YAML can convert it to JSON first,
+// or decode it into a [Description] itself and call [Description.Map].
+func LoadSpec(r io.Reader, funcs map[string]interface{}) (Map, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var d Description
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("decoding spec: %w", err)
+	}
+	return d.Map(funcs)
+}
+
+// Map produces a [Map] from d,
+// the inverse of [Describe].
+//
+// Each subcommand's function is found in funcs
+// under the subcommand's path:
+// its name, preceded by the names of any enclosing nested commands,
+// separated by spaces
+// (for example "db migrate").
+// A subcommand with nested subcommands needs no function;
+// it becomes a nested command (see Subcmd.Sub).
+// It is an error for a function to be missing from funcs,
+// for funcs to contain a function that does not belong to any subcommand,
+// or for a function not to match its subcommand's parameters
+// (see [Check]).
+//
+// Parameter types are given by name (see [Type.String]).
+// Types whose values cannot be expressed in a description
+// ([Value], [JSON], [Custom], and [Text])
+// are not supported.
+// Parameter defaults have the form that Describe produces;
+// for example, a [Duration] default is a string like "1m30s".
+func (d *Description) Map(funcs map[string]interface{}) (Map, error) {
+	used := make(map[string]bool)
+	m, err := specMap(d.Subcmds, nil, funcs, used)
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []string
+	for path := range funcs {
+		if !used[path] {
+			unused = append(unused, path)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("no subcommand for function(s) %s", strings.Join(unused, ", "))
+	}
+
+	return m, nil
+}
+
+func specMap(subcmds []SubcmdDescription, path []string, funcs map[string]interface{}, used map[string]bool) (Map, error) {
+	m := make(Map, len(subcmds))
+	for _, sd := range subcmds {
+		subpath := append(path[:len(path):len(path)], sd.Name)
+		key := strings.Join(subpath, " ")
+
+		if _, ok := m[sd.Name]; ok {
+			return nil, fmt.Errorf("duplicate subcommand %s", key)
+		}
+
+		subcmd := Subcmd{
+			Desc:       sd.Desc,
+			Deprecated: sd.Deprecated,
+			Examples:   sd.Examples,
+		}
+		for _, hp := range sd.Params {
+			p, err := specParam(hp)
+			if err != nil {
+				return nil, fmt.Errorf("subcommand %s: %w", key, err)
+			}
+			subcmd.Params = append(subcmd.Params, p)
+		}
+
+		if len(sd.Subcmds) > 0 {
+			sub, err := specMap(sd.Subcmds, subpath, funcs, used)
+			if err != nil {
+				return nil, err
+			}
+			subcmd.Sub = specCmd(sub)
+		} else {
+			f, ok := funcs[key]
+			if !ok {
+				return nil, fmt.Errorf("no function for subcommand %s", key)
+			}
+			used[key] = true
+			subcmd.F = f
+			if err := Check(subcmd); err != nil {
+				return nil, fmt.Errorf("subcommand %s: %w", key, err)
+			}
+		}
+
+		m[sd.Name] = subcmd
+	}
+	return m, nil
+}
+
+// specCmd is the nested Cmd for a subcommand with nested subcommands in a spec.
+type specCmd Map
+
+// Subcmds implements [Cmd].
+func (c specCmd) Subcmds() Map {
+	return Map(c)
+}
+
+// specParam converts hp to a Param.
+func specParam(hp HelpParam) (Param, error) {
+	t, ok := specType(hp.Type)
+	if !ok {
+		return Param{}, fmt.Errorf("parameter %s: unsupported type %q", hp.Name, hp.Type)
+	}
+
+	p := Param{
+		Name:     hp.Name,
+		Type:     t,
+		Required: hp.Required,
+		Env:      hp.Env,
+		Choices:  hp.Choices,
+		Doc:      hp.Doc,
+	}
+	switch {
+	case hp.Flag:
+		p.Name = "-" + p.Name
+	case hp.Optional:
+		p.Name += "?"
+	}
+
+	dflt, err := specDefault(t, hp.Default)
+	if err != nil {
+		return Param{}, fmt.Errorf("parameter %s: default value: %w", hp.Name, err)
+	}
+	p.Default = dflt
+
+	return p, nil
+}
+
+// specType is the Type named s (see Type.String),
+// if it can be used in a spec.
+func specType(s string) (Type, bool) {
+	for _, t := range []Type{Bool, Int, Int64, Uint, Uint64, String, Float64, Duration, Strings, ExistingFile, ExistingDir, Time, StringMap} {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// specDefault converts v,
+// as produced by Describe or by decoding its JSON form,
+// to a default value for a parameter of type t.
+// A nil v means the zero value of t.
+func specDefault(t Type, v interface{}) (interface{}, error) {
+	if v == nil {
+		// The zero value of the parameter's type.
+		switch t {
+		case Bool:
+			v = false
+		case Int, Int64, Uint, Uint64, Float64:
+			v = 0
+		case String, ExistingFile, ExistingDir:
+			v = ""
+		case Duration:
+			v = "0s"
+		case Time:
+			return time.Time{}, nil
+		case Strings:
+			return []string(nil), nil
+		case StringMap:
+			return map[string]string(nil), nil
+		}
+	}
+
+	switch t {
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("got %T, want bool", v)
+		}
+		return b, nil
+
+	case Int:
+		n, err := strconv.ParseInt(specNumber(v), 10, 0)
+		return int(n), err
+
+	case Int64:
+		return strconv.ParseInt(specNumber(v), 10, 64)
+
+	case Uint:
+		n, err := strconv.ParseUint(specNumber(v), 10, 0)
+		return uint(n), err
+
+	case Uint64:
+		return strconv.ParseUint(specNumber(v), 10, 64)
+
+	case Float64:
+		return strconv.ParseFloat(specNumber(v), 64)
+
+	case String, ExistingFile, ExistingDir:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("got %T, want string", v)
+		}
+		return s, nil
+
+	case Duration:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("got %T, want duration string", v)
+		}
+		return time.ParseDuration(s)
+
+	case Time:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("got %T, want time string", v)
+		}
+		return parseTime(nil, s)
+
+	case Strings:
+		switch v := v.(type) {
+		case []string:
+			return v, nil
+		case []interface{}:
+			result := make([]string, 0, len(v))
+			for _, elt := range v {
+				s, ok := elt.(string)
+				if !ok {
+					return nil, fmt.Errorf("got %T element, want string", elt)
+				}
+				result = append(result, s)
+			}
+			return result, nil
+		}
+		return nil, fmt.Errorf("got %T, want list of strings", v)
+
+	case StringMap:
+		switch v := v.(type) {
+		case map[string]string:
+			return v, nil
+		case map[string]interface{}:
+			result := make(map[string]string, len(v))
+			for k, val := range v {
+				s, ok := val.(string)
+				if !ok {
+					return nil, fmt.Errorf("got %T value for key %s, want string", val, k)
+				}
+				result[k] = s
+			}
+			return result, nil
+		}
+		return nil, fmt.Errorf("got %T, want map of strings", v)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// specNumber formats the numeric value v for parsing with strconv,
+// writing float64s (as decoded from JSON) without exponents.
+func specNumber(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
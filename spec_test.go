@@ -0,0 +1,165 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadSpec(t *testing.T) {
+	const spec = `{
+  "subcmds": [
+    {
+      "name": "serve",
+      "desc": "run the server",
+      "params": [
+        {"name": "port", "type": "int", "flag": true, "default": 8080, "doc": "port to listen on"},
+        {"name": "timeout", "type": "time.Duration", "flag": true, "default": "1m30s"},
+        {"name": "root", "type": "string", "optional": true, "default": "."}
+      ]
+    },
+    {
+      "name": "db",
+      "desc": "database commands",
+      "subcmds": [
+        {"name": "migrate", "params": [{"name": "dir", "type": "string"}]}
+      ]
+    }
+  ]
+}`
+
+	var (
+		gotPort    int
+		gotTimeout time.Duration
+		gotRoot    string
+		gotDir     string
+	)
+	funcs := map[string]interface{}{
+		"serve": func(_ context.Context, port int, timeout time.Duration, root string, _ []string) {
+			gotPort, gotTimeout, gotRoot = port, timeout, root
+		},
+		"db migrate": func(_ context.Context, dir string, _ []string) {
+			gotDir = dir
+		},
+	}
+
+	m, err := LoadSpec(strings.NewReader(spec), funcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testcmdfunc(func() Map { return m })
+
+	if err := Run(context.Background(), c, []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPort != 8080 || gotTimeout != 90*time.Second || gotRoot != "." {
+		t.Errorf("got port %d, timeout %s, root %q; want defaults", gotPort, gotTimeout, gotRoot)
+	}
+
+	if err := Run(context.Background(), c, []string{"serve", "-port", "9000", "/srv"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPort != 9000 || gotRoot != "/srv" {
+		t.Errorf("got port %d, root %q; want 9000, /srv", gotPort, gotRoot)
+	}
+
+	if err := Run(context.Background(), c, []string{"db", "migrate", "here"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != "here" {
+		t.Errorf(`got dir "%s", want "here"`, gotDir)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		d, err := Describe(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m2, err := LoadSpec(bytes.NewReader(j), funcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d2, err := Describe(testcmdfunc(func() Map { return m2 }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(d, d2); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestLoadSpecErrors(t *testing.T) {
+	noop := func(context.Context, []string) {}
+
+	cases := []struct {
+		name  string
+		spec  string
+		funcs map[string]interface{}
+		want  string
+	}{{
+		name: "missing func",
+		spec: `{"subcmds": [{"name": "a"}, {"name": "b"}]}`,
+		funcs: map[string]interface{}{
+			"a": noop,
+		},
+		want: "no function for subcommand b",
+	}, {
+		name: "extra func",
+		spec: `{"subcmds": [{"name": "a"}]}`,
+		funcs: map[string]interface{}{
+			"a": noop,
+			"c": noop,
+		},
+		want: "no subcommand for function(s) c",
+	}, {
+		name: "unsupported type",
+		spec: `{"subcmds": [{"name": "a", "params": [{"name": "v", "type": "flag.Value", "flag": true}]}]}`,
+		funcs: map[string]interface{}{
+			"a": noop,
+		},
+		want: `unsupported type "flag.Value"`,
+	}, {
+		name: "bad default",
+		spec: `{"subcmds": [{"name": "a", "params": [{"name": "n", "type": "int", "flag": true, "default": 1.5}]}]}`,
+		funcs: map[string]interface{}{
+			"a": func(context.Context, int, []string) {},
+		},
+		want: "parameter n: default value",
+	}, {
+		name: "func mismatch",
+		spec: `{"subcmds": [{"name": "a", "params": [{"name": "n", "type": "int", "flag": true}]}]}`,
+		funcs: map[string]interface{}{
+			"a": noop,
+		},
+		want: "subcommand a:",
+	}, {
+		name: "unknown field",
+		spec: `{"subcmds": [{"name": "a", "func": "a"}]}`,
+		funcs: map[string]interface{}{
+			"a": noop,
+		},
+		want: "decoding spec",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadSpec(strings.NewReader(tc.spec), tc.funcs)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf(`got error "%s", want it to contain "%s"`, err, tc.want)
+			}
+		})
+	}
+}
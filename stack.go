@@ -0,0 +1,96 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// WithStackTraces is an [Option] that causes [Run] to record a stack trace
+// when it returns an error.
+// The error is wrapped in a [*StackErr],
+// from which the trace can be obtained with errors.As and the Stack method.
+// This is intended as a debugging aid.
+//
+// Errors returned by nested calls to Run
+// are wrapped only once, by the innermost call.
+func WithStackTraces() Option {
+	return func(c *config) error {
+		c.stackTraces = true
+		return nil
+	}
+}
+
+// StackErr is an error that records the call stack at the point it was created.
+// See [WithStackTraces].
+type StackErr struct {
+	Err error
+	pcs []uintptr
+}
+
+// traced wraps err in a StackErr recording the stack of its caller,
+// if stack traces were requested in ctx (see [WithStackTraces])
+// and err does not already contain a StackErr.
+// It is used where dispatch and invoke create or wrap errors,
+// so that the trace shows where the error arose.
+func traced(ctx context.Context, err error) error {
+	if err == nil || !getConfig(ctx).stackTraces {
+		return err
+	}
+	return withStack(err, 3) // skip runtime.Callers, withStack, and traced
+}
+
+// withStack wraps err in a StackErr,
+// unless err already contains one.
+// The skip argument is as for [runtime.Callers].
+func withStack(err error, skip int) error {
+	var s *StackErr
+	if errors.As(err, &s) {
+		return err
+	}
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip, pcs)
+	return &StackErr{Err: err, pcs: pcs[:n]}
+}
+
+func (e *StackErr) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap unwraps the nested error in e.
+func (e *StackErr) Unwrap() error {
+	return e.Err
+}
+
+// Stack returns the stack frames recorded in e,
+// innermost first.
+func (e *StackErr) Stack() []runtime.Frame {
+	var (
+		result []runtime.Frame
+		frames = runtime.CallersFrames(e.pcs)
+	)
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// Format implements [fmt.Formatter].
+// The %+v verb produces the error message followed by the stack trace.
+// Other verbs produce just the error message.
+func (e *StackErr) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, e.Error())
+		for _, frame := range e.Stack() {
+			fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+		return
+	}
+	io.WriteString(s, e.Error())
+}
@@ -0,0 +1,36 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStackTraces(t *testing.T) {
+	ctx := context.Background()
+
+	err := Run(ctx, errtestcmd{}, []string{"a"})
+	var s *StackErr
+	if errors.As(err, &s) {
+		t.Error("got StackErr without WithStackTraces")
+	}
+
+	err = Run(ctx, errtestcmd{}, []string{"a"}, WithStackTraces())
+	if !errors.As(err, &s) {
+		t.Fatalf("got %T, want *StackErr", err)
+	}
+	if !errors.Is(err, ErrTooFewArgs) {
+		t.Errorf("got %v, want an error wrapping ErrTooFewArgs", err)
+	}
+	if len(s.Stack()) == 0 {
+		t.Fatal("empty stack")
+	}
+	if fn := s.Stack()[0].Function; !strings.HasSuffix(fn, "/subcmd/v2.invoke") {
+		t.Errorf(`got innermost frame "%s", want invoke`, fn)
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "stack_test.go") {
+		t.Errorf("formatted error does not contain stack trace: %s", got)
+	}
+}
@@ -0,0 +1,56 @@
+package subcmd
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// WithStdio is an [Option] that sets the standard input, output, and error streams
+// made available to subcommand functions via [Stdin], [Stdout], and [Stderr],
+// and used for plugin subprocesses (see [Prefixer]).
+// A nil value leaves the corresponding stream unchanged.
+//
+// This is useful in environments where os.Stdin, os.Stdout, and os.Stderr are unavailable or inappropriate,
+// such as WebAssembly hosts and tests.
+func WithStdio(stdin io.Reader, stdout, stderr io.Writer) Option {
+	return func(c *config) error {
+		if stdin != nil {
+			c.stdin = stdin
+		}
+		if stdout != nil {
+			c.stdout = stdout
+		}
+		if stderr != nil {
+			c.stderr = stderr
+		}
+		return nil
+	}
+}
+
+// Stdin returns the standard input stream set with [WithStdio],
+// or os.Stdin if none was set.
+func Stdin(ctx context.Context) io.Reader {
+	if r := getConfig(ctx).stdin; r != nil {
+		return r
+	}
+	return os.Stdin
+}
+
+// Stdout returns the standard output stream set with [WithStdio],
+// or os.Stdout if none was set.
+func Stdout(ctx context.Context) io.Writer {
+	if w := getConfig(ctx).stdout; w != nil {
+		return w
+	}
+	return os.Stdout
+}
+
+// Stderr returns the standard error stream set with [WithStdio],
+// or os.Stderr if none was set.
+func Stderr(ctx context.Context) io.Writer {
+	if w := getConfig(ctx).stderr; w != nil {
+		return w
+	}
+	return os.Stderr
+}
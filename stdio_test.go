@@ -0,0 +1,36 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStdio(t *testing.T) {
+	ctx := context.Background()
+
+	if Stdin(ctx) != os.Stdin || Stdout(ctx) != os.Stdout || Stderr(ctx) != os.Stderr {
+		t.Error("default streams are not os.Stdin, os.Stdout, and os.Stderr")
+	}
+
+	var (
+		stdin          = strings.NewReader("hello")
+		stdout, stderr bytes.Buffer
+	)
+	c := dotenvtestcmd(func(ctx context.Context, _ []string) {
+		io.Copy(Stdout(ctx), Stdin(ctx))
+		io.WriteString(Stderr(ctx), "goodbye")
+	})
+	if err := Run(ctx, c, []string{"x"}, WithStdio(stdin, &stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "hello" {
+		t.Errorf(`got stdout "%s", want "hello"`, got)
+	}
+	if got := stderr.String(); got != "goodbye" {
+		t.Errorf(`got stderr "%s", want "goodbye"`, got)
+	}
+}
@@ -0,0 +1,35 @@
+package subcmd
+
+import "context"
+
+// WithStrictArgs is an [Option] that makes every subcommand strict
+// (see Subcmd.Strict),
+// except those whose functions are variadic.
+// A subcommand taking ...string is presumed to consume its remaining arguments;
+// one taking []string usually ignores them,
+// so a typo like "prog list extra-token" would otherwise go unnoticed.
+func WithStrictArgs() Option {
+	return func(c *config) error {
+		c.strictArgs = true
+		return nil
+	}
+}
+
+// StrictCmd is an optional additional interface that a [Cmd] can implement.
+// If its StrictArgs method returns true,
+// all of the Cmd's subcommands,
+// including nested ones (see Subcmd.Sub),
+// are treated as if [WithStrictArgs] were in effect.
+type StrictCmd interface {
+	StrictArgs() bool
+}
+
+// withStrictCmd returns a context in which WithStrictArgs is in effect
+// if c is a StrictCmd requesting it.
+func withStrictCmd(ctx context.Context, c Cmd) context.Context {
+	if s, ok := cmdAs[StrictCmd](c); !ok || !s.StrictArgs() || getConfig(ctx).strictArgs {
+		return ctx
+	}
+	ctx, _ = withOptions(ctx, []Option{WithStrictArgs()}) // WithStrictArgs never fails
+	return ctx
+}
@@ -0,0 +1,78 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStrictArgs(t *testing.T) {
+	ctx := context.Background()
+
+	var got []string
+	c := dotenvtestcmd(func(_ context.Context, args []string) {
+		got = args
+	})
+
+	if err := Run(ctx, c, []string{"x", "extra"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"extra"}) {
+		t.Errorf("got %v, want [extra]", got)
+	}
+
+	err := Run(ctx, c, []string{"x", "extra", "token"}, WithStrictArgs())
+	var e ExtraArgsErr
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want ExtraArgsErr", err)
+	}
+	if !reflect.DeepEqual(e.Args, []string{"extra", "token"}) {
+		t.Errorf("got %v, want [extra token]", e.Args)
+	}
+
+	if err := Run(ctx, c, []string{"x"}, WithStrictArgs()); err != nil {
+		t.Fatal(err)
+	}
+
+	v := Commands("v", Subcmd{F: func(_ context.Context, args ...string) { got = args }})
+	if err := Run(ctx, testcmdfunc(func() Map { return v }), []string{"v", "a", "b"}, WithStrictArgs()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got %v, want [a b]", got)
+	}
+
+	v = Commands("v", Subcmd{F: func(context.Context, []string) {}, Strict: true})
+	if err := Run(ctx, testcmdfunc(func() Map { return v }), []string{"v", "a"}); !errors.As(err, &e) {
+		t.Errorf("got %v, want ExtraArgsErr", err)
+	}
+}
+
+type strictcmd struct {
+	testcmdfunc
+}
+
+func (strictcmd) StrictArgs() bool { return true }
+
+func TestStrictCmd(t *testing.T) {
+	c := strictcmd{testcmdfunc: func() Map {
+		return Commands(
+			"x", func(context.Context, []string) {}, "x", nil,
+			"sub", Subcmd{Sub: testcmdfunc(func() Map {
+				return Commands("y", func(context.Context, []string) {}, "y", nil)
+			})},
+		)
+	}}
+
+	var e ExtraArgsErr
+	if err := Run(context.Background(), c, []string{"x", "extra"}); !errors.As(err, &e) {
+		t.Errorf("got %v, want ExtraArgsErr", err)
+	}
+	if err := Run(context.Background(), c, []string{"sub", "y", "extra"}); !errors.As(err, &e) {
+		t.Errorf("got %v, want ExtraArgsErr", err)
+	}
+	if err := Run(context.Background(), c, []string{"x"}); err != nil {
+		t.Error(err)
+	}
+}
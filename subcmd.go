@@ -3,16 +3,12 @@ package subcmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"os"
-	"os/exec"
 	"reflect"
 	"sort"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 var (
@@ -42,24 +38,99 @@ type Cmd interface {
 // it is executed with the remaining args as arguments,
 // and a JSON-marshaled copy of the Cmd in the environment variable SUBCMD_ENV
 // (that can be parsed by the subprocess using [ParseEnv]).
+// The environment also identifies the version of this protocol
+// and the command path by which the plugin was invoked;
+// see [ProtocolEnvVar] and [CommandPathEnvVar].
+// A Prefixer can control that encoding with [EnvMarshaler] and [EnvEncoder].
+// To run a plugin directly, use [ExecPlugin].
 type Prefixer interface {
 	Prefix() string
 }
 
+// Resolver is an optional additional interface that a [Cmd] can implement.
+// If it does,
+// [Run] calls Resolve to find the subcommand named in its args,
+// instead of calling Subcmds and looking up the name in the resulting [Map].
+// This allows very large command sets
+// (such as ones generated from an external schema)
+// to be dispatched without materializing all their subcommands.
+// Subcmds is still called when a listing of all subcommands is needed,
+// for example for help and usage errors.
+type Resolver interface {
+	// Resolve returns the named subcommand,
+	// and false if there is no such subcommand.
+	Resolve(name string) (Subcmd, bool)
+}
+
 // Map is the type of the data structure returned by Cmd.Subcmds and by [Commands].
 // It maps a subcommand name to its [Subcmd] structure.
 type Map = map[string]Subcmd
 
-// Returns c's subcommand names as a sorted slice.
-func subcmdNames(c Cmd) []string {
+// Returns the subcommand names in m as a sorted slice,
+// omitting unsupported ones (see Subcmd.Supported).
+// They are sorted by Subcmd.Order and then by name.
+func subcmdNames(m Map) []string {
 	var result []string
-	for cmdname := range c.Subcmds() {
+	for cmdname, subcmd := range m {
+		if ok, _ := subcmd.supported(); !ok {
+			continue
+		}
 		result = append(result, cmdname)
 	}
-	sort.Strings(result)
+	sort.Slice(result, func(i, j int) bool {
+		if oi, oj := m[result[i]].Order, m[result[j]].Order; oi != oj {
+			return oi < oj
+		}
+		return result[i] < result[j]
+	})
 	return result
 }
 
+// listSubcmds returns m if it is not nil,
+// otherwise c.Subcmds().
+// It is used where m may not have been computed,
+// because c is a [Resolver].
+func listSubcmds(c Cmd, m Map) Map {
+	if m != nil {
+		return m
+	}
+	return c.Subcmds()
+}
+
+// lookupSubcmd finds the named subcommand of c,
+// using m if it is not nil,
+// otherwise using c's Resolve method if it has one,
+// otherwise using c.Subcmds().
+func lookupSubcmd(c Cmd, m Map, name string) (Subcmd, bool) {
+	if m == nil {
+		if r, ok := cmdAs[Resolver](c); ok {
+			return r.Resolve(name)
+		}
+	}
+	subcmd, ok := listSubcmds(c, m)[name]
+	return subcmd, ok
+}
+
+// walk calls fn on each subcommand of c in name order,
+// recursing into nested commands declared with Subcmd.Sub.
+// The path passed to fn includes the subcommand's own name.
+func walk(c Cmd, path []string, fn func(path []string, subcmd Subcmd) error) error {
+	subcmds := c.Subcmds()
+	for _, name := range subcmdNames(subcmds) {
+		subcmd := subcmds[name]
+		subpath := append(path[:len(path):len(path)], name)
+		if err := fn(subpath, subcmd); err != nil {
+			return err
+		}
+		if subcmd.Sub != nil {
+			if err := walk(subcmd.Sub, subpath, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Subcmd is one subcommand of a [Cmd],
 // and the value type in the [Map] returned by Cmd.Subcmds.
 //
@@ -78,6 +149,26 @@ type Subcmd struct {
 	// where OPTS stands for a sequence of zero or more additional parameters
 	// corresponding to the types in Params.
 	//
+	// The initial context.Context may also be followed by parameters
+	// whose values are injected by type;
+	// see [WithProvider].
+	//
+	// Instead of a sequence of parameters for OPTS,
+	// F may take a single struct
+	// whose fields hold the values of the Params.
+	// The field for a Param is the exported one with the tag `subcmd:"NAME"`,
+	// where NAME is the Param's name without leading dashes or a trailing "?";
+	// or if there is none,
+	// the one whose name matches NAME ignoring case, dashes, and underscores.
+	// For example:
+	//
+	//	type fooOptions struct {
+	//		DryRun bool   // the -dry-run flag
+	//		Target string `subcmd:"dest"`
+	//	}
+	//
+	//	func foo(ctx context.Context, opts fooOptions, args []string) error
+	//
 	// A Param with type Value supplies a [flag.Value] to the function.
 	// It's up to the function to type-assert the flag.Value to a more-specific type to read the value it contains.
 	F interface{}
@@ -88,6 +179,91 @@ type Subcmd struct {
 
 	// Desc is a one-line description of this subcommand.
 	Desc string
+
+	// Sub is an optional [Cmd] implementing sub-subcommands of this subcommand.
+	// If F is nil and Sub is not,
+	// [Run] dispatches the remaining args to Sub.
+	// If F is also set,
+	// then F is responsible for doing that itself,
+	// and Sub serves only to make the nested commands visible
+	// to functions that walk the command tree, such as [Docopt].
+	Sub Cmd
+
+	// Strict, if true,
+	// causes [Run] to return an [ExtraArgsErr]
+	// when arguments remain after parsing this subcommand's flags and positional parameters,
+	// instead of passing them to F.
+	// See also [WithStrictArgs] and [StrictCmd].
+	Strict bool
+
+	// MinArgs and MaxArgs, if positive,
+	// are the minimum and maximum number of arguments
+	// that may remain after parsing this subcommand's flags and positional parameters.
+	// (Set both to the same value to require exactly that many.)
+	// Otherwise [Run] returns an [ArgCountErr].
+	MinArgs, MaxArgs int
+
+	// Supported, if not nil,
+	// tells whether this subcommand can be used in the current environment,
+	// and if not, why not.
+	// Unsupported subcommands are omitted from help and usage messages,
+	// and attempting to run one produces an [UnsupportedErr].
+	// See [OnlyOn] for a way to restrict a subcommand to certain platforms.
+	Supported func() (ok bool, reason string)
+
+	// Cleanup is an optional function that [Run] calls
+	// if its context is canceled while F is running
+	// and a grace period is in effect (see [WithGracePeriod]).
+	// It can be used to save state before the program exits.
+	// Its context expires at the end of the grace period.
+	Cleanup func(context.Context) error
+
+	// Deprecated, if not empty,
+	// marks this subcommand as deprecated.
+	// It should say what to use instead.
+	// When the subcommand runs,
+	// [Run] emits a warning containing this message (see [WithWarningHandler]),
+	// and help output flags the subcommand as deprecated.
+	Deprecated string
+
+	// Examples are optional example command lines for this subcommand,
+	// shown verbatim in an "Examples:" section of its help
+	// (see [HelpRequestedErr.Detail]).
+	Examples []string
+
+	// Timeout, if positive,
+	// limits how long F may run.
+	// [Run] passes F a context with this timeout,
+	// and if F fails because the timeout expired,
+	// Run returns a [TimeoutErr] naming the subcommand.
+	Timeout time.Duration
+
+	// Order determines where this subcommand appears
+	// in help output and other listings of subcommands.
+	// Subcommands are listed in increasing Order,
+	// and alphabetically among those with the same Order.
+	// So with the default of zero everywhere,
+	// the listing is alphabetical,
+	// and a subcommand with a negative Order is listed before the rest.
+	Order int
+}
+
+func (s Subcmd) supported() (bool, string) {
+	if s.Supported == nil {
+		return true, ""
+	}
+	return s.Supported()
+}
+
+// helpDesc is the description of s for use in lists of subcommands.
+func (s Subcmd) helpDesc(cat Catalog) string {
+	if s.Deprecated == "" {
+		return s.Desc
+	}
+	if s.Desc == "" {
+		return cat.tr(MsgDeprecatedTag)
+	}
+	return s.Desc + " " + cat.tr(MsgDeprecatedTag)
 }
 
 // Param is one parameter of a [Subcmd].
@@ -106,10 +282,81 @@ type Param struct {
 	// If Type is Value,
 	// then Default must be a [flag.Value].
 	// It may optionally also be a [Copier], qv.
+	// If it is also a [flag.Getter],
+	// the subcommand's function may take the type of the result of its Get method
+	// instead of flag.Value,
+	// and receives the result of calling Get after parsing.
 	Default interface{}
 
 	// Doc is a docstring for the parameter.
 	Doc string
+
+	// Required, if true, means that a flag parameter must be given a value,
+	// either on the command line
+	// or by some other means such as a config file (see [WithConfigFlag]).
+	// If it isn't, [Run] returns a [MissingFlagErr].
+	// It is ignored for positional parameters.
+	Required bool
+
+	// Requires lists other flags in the same [Subcmd]
+	// that must be supplied whenever this flag is,
+	// as in a "-key" flag that requires a "-cert" flag.
+	// If they aren't, [Run] returns a [FlagRequiresErr].
+	// Like Required,
+	// a flag counts as supplied if it's given a value on the command line or by some other means.
+	// It is ignored for positional parameters.
+	Requires []string
+
+	// Env, if not empty,
+	// is the name of an environment variable
+	// from which a flag parameter takes its value
+	// when it isn't given on the command line.
+	// It takes precedence over the variable implied by [WithEnvPrefix].
+	// It is ignored for positional parameters.
+	Env string
+
+	// Choices, if not empty,
+	// is the list of values allowed for a parameter of type String.
+	// [Run] rejects any other value with an error listing the choices,
+	// and help output shows them.
+	// The default value must be one of the choices or else empty.
+	Choices []string
+
+	// Layouts, if not empty,
+	// are the layouts (see [time.Layout]) accepted for a parameter of type Time,
+	// tried in order.
+	// The default is [time.RFC3339].
+	Layouts []string
+
+	// OnDuplicate says what to do when a key is given more than once
+	// for a parameter of type StringMap.
+	OnDuplicate DuplicatePolicy
+
+	// Parse is the function that parses the value of a parameter of type Custom.
+	// The value it returns must be of the same type as the parameter's default.
+	// See also [ParseFunc].
+	Parse func(string) (interface{}, error)
+
+	// Var, if not nil,
+	// is a pointer to a variable in which [Run] stores the parameter's value,
+	// as with [flag.IntVar] and its siblings.
+	// The variable must have the Go type of the parameter.
+	// A parameter with a Var is not passed to the subcommand's function,
+	// so the function takes no argument for it.
+	// See [VarParams].
+	Var interface{}
+
+	// Nullable, if true,
+	// means the subcommand's function receives a pointer to the parameter's value
+	// (e.g. *int instead of int),
+	// which is nil when no value was supplied.
+	// This distinguishes an omitted parameter from one given its default value.
+	// A flag counts as supplied if it's given a value on the command line or by some other means
+	// (such as an environment variable; see [WithEnvPrefix]).
+	// A positional parameter counts as supplied if there is an argument for it.
+	// Nullable may not be used with type Value,
+	// or together with Var.
+	Nullable bool
 }
 
 // Type is the type of a [Param].
@@ -127,6 +374,78 @@ const (
 	Float64
 	Duration
 	Value
+
+	// Strings is the type of a flag that may be given more than once,
+	// as in "-tag a -tag b",
+	// accumulating its values into a []string.
+	// If the flag is given at all,
+	// its values replace (rather than add to) the default.
+	// Strings parameters must be flags, not positional parameters.
+	Strings
+
+	// ExistingFile is the type of a string parameter naming a file that must exist.
+	// Its value is passed to the subcommand's function as a cleaned, absolute path.
+	// If the file does not exist or is a directory,
+	// [Run] returns a [ParseErr] wrapping an [*os.PathError].
+	// An empty value is not checked.
+	ExistingFile
+
+	// ExistingDir is like ExistingFile but for a directory.
+	ExistingDir
+
+	// Time is the type of a [time.Time] parameter.
+	// Its value is parsed according to the parameter's Layouts,
+	// or as RFC 3339 (see [time.RFC3339]) if there are none.
+	Time
+
+	// JSON is the type of a parameter whose value is JSON,
+	// or "@" followed by the name of a file containing JSON.
+	// The parameter's default value must be a non-nil pointer,
+	// usually to a struct,
+	// and the subcommand's function receives a pointer of the same type.
+	// It points to a copy of the default value
+	// into which the JSON has been unmarshaled,
+	// so fields that are missing from the JSON keep their default values.
+	JSON
+
+	// StringMap is the type of a flag that may be given more than once,
+	// each time with a value of the form key=value,
+	// as in "-label env=prod -label team=infra",
+	// collecting the pairs into a map[string]string.
+	// The pairs are added to a copy of the default value, if any.
+	// What happens when a key is given more than once is determined by the parameter's OnDuplicate field.
+	// StringMap parameters must be flags, not positional parameters.
+	StringMap
+
+	// Custom is the type of a parameter whose value is produced by its Parse function
+	// (see Param.Parse).
+	// The parameter's default value must not be nil,
+	// and the subcommand's function receives a value of the same type.
+	Custom
+
+	// Text is the type of a parameter whose default value's type T
+	// implements [encoding.TextMarshaler],
+	// and for which *T implements [encoding.TextUnmarshaler],
+	// such as [netip.Addr].
+	// (This is like [flag.TextVar].)
+	// The subcommand's function receives a value of type T.
+	Text
+)
+
+// DuplicatePolicy says what to do when a key is given more than once
+// for a parameter of type [StringMap].
+type DuplicatePolicy int
+
+// Possible values for Param.OnDuplicate.
+const (
+	// DuplicateLast means the last value given for a key wins.
+	DuplicateLast DuplicatePolicy = iota
+
+	// DuplicateFirst means the first value given for a key wins.
+	DuplicateFirst
+
+	// DuplicateError means giving a key more than once is an error.
+	DuplicateError
 )
 
 // String returns the name of a [Type].
@@ -150,11 +469,48 @@ func (t Type) String() string {
 		return "time.Duration"
 	case Value:
 		return "flag.Value"
+	case Strings:
+		return "[]string"
+	case ExistingFile:
+		return "existing file"
+	case ExistingDir:
+		return "existing directory"
+	case Time:
+		return "time.Time"
+	case JSON:
+		return "JSON"
+	case StringMap:
+		return "map[string]string"
+	case Custom:
+		return "custom"
+	case Text:
+		return "text"
 	default:
 		return fmt.Sprintf("unknown type %d", t)
 	}
 }
 
+// reflectType is the Go type of p's value.
+// This is p.Type.reflectType() except for parameters whose type is dynamic,
+// which have the type of the default value.
+func (p Param) reflectType() reflect.Type {
+	var t reflect.Type
+	if p.Type.dynamic() {
+		t = reflect.TypeOf(p.Default)
+	} else {
+		t = p.Type.reflectType()
+	}
+	if p.Nullable && t != nil {
+		t = reflect.PointerTo(t)
+	}
+	return t
+}
+
+// dynamic tells whether parameters of type t have Go types that depend on their default values.
+func (t Type) dynamic() bool {
+	return t == JSON || t == Custom || t == Text
+}
+
 func (t Type) reflectType() reflect.Type {
 	switch t {
 	case Bool:
@@ -175,6 +531,14 @@ func (t Type) reflectType() reflect.Type {
 		return reflect.TypeOf(time.Duration(0))
 	case Value:
 		return valueType
+	case Strings:
+		return strSliceType
+	case ExistingFile, ExistingDir:
+		return reflect.TypeOf("")
+	case Time:
+		return reflect.TypeOf(time.Time{})
+	case StringMap:
+		return reflect.TypeOf(map[string]string(nil))
 	default:
 		panic(fmt.Sprintf("unknown type %d", t))
 	}
@@ -334,9 +698,20 @@ func Params(a ...interface{}) []Param {
 //
 // Calling Run with an unknown subcommand name in args[0] produces an [UnknownSubcmdErr] error,
 // unless the unknown subcommand is "help",
-// in which case the result is a [HelpRequestedErr],
+// in which case the result is a [HelpRequestedErr]
+// (whose Detail method produces JSON if "help" is followed by "-json",
+// and includes nested subcommands at every depth if "help" is followed by "-all"),
+// or unless it is "tree" and the [WithTreeCmd] option is in effect,
 // or unless c is also a [Prefixer].
 //
+// The name after "help" may be preceded by the names of nested commands (see [Subcmd.Sub]),
+// so that "prog help db migrate" is equivalent to "prog db help migrate".
+//
+// A -h or -help flag after a subcommand name also produces a [HelpRequestedErr],
+// the same one that "help" would produce for that subcommand
+// (so "prog foo -h" is equivalent to "prog help foo"),
+// except that it also wraps [flag.ErrHelp].
+//
 // If c is a Prefixer and the subcommand name is both unknown and not "help",
 // then an executable is sought in $PATH with c's prefix plus the subcommand name.
 // (For example, if c.Prefix() returns "foo-" and the subcommand name is "bar",
@@ -350,80 +725,355 @@ func Params(a ...interface{}) []Param {
 // the result is [ErrTooFewArgs].
 //
 // If argument parsing succeeds,
-// Run returns the error produced by calling the subcommand's function, if any.
-func Run(ctx context.Context, c Cmd, args []string) error {
-	if len(args) == 0 {
-		return &MissingSubcmdErr{
-			pairs: subcmdPairList(ctx),
-			cmd:   c,
+// Run returns the error produced by calling the subcommand's function, if any,
+// wrapped with the subcommand's name.
+// If that error is an [ExitCoder],
+// callers may wish to use its ExitCode as the process exit status,
+// as [Main] does.
+//
+// Errors returned by Run may be wrapped in additional context,
+// so callers should examine them with [errors.Is] and [errors.As]
+// rather than with == or type assertions.
+// The following are guaranteed to be found that way
+// and are part of Run's stable contract:
+// the error returned by the subcommand's function;
+// [ErrTooFewArgs];
+// the usage errors *[MissingSubcmdErr], *[UnknownSubcmdErr], *[HelpRequestedErr], and others implementing [UsageErr];
+// and [ParseErr] and [FlagErr].
+// The text of the wrapping context is not part of the contract.
+//
+// If the environment variables COMP_LINE and COMP_POINT are set,
+// Run instead writes to [Stdout] the possible completions of the command line in COMP_LINE,
+// one per line,
+// and returns.
+// This lets bash users enable completion for a program
+// with "complete -o default -C prog prog".
+// (See also [Completion].)
+//
+// The behavior of Run may be modified with zero or more [Option] values.
+func Run(ctx context.Context, c Cmd, args []string, opts ...Option) error {
+	return runWithOptions(ctx, opts, func(ctx context.Context) error {
+		// Call c.Subcmds only once per dispatch,
+		// since it may be expensive,
+		// and not at all if c is a Resolver.
+		var cmds Map
+		if _, ok := cmdAs[Resolver](c); !ok {
+			start := time.Now()
+			cmds = c.Subcmds()
+			addTiming(ctx, mapPhase, start)
 		}
+		return dispatch(ctx, c, cmds, nil, args)
+	})
+}
+
+// runWithOptions applies opts to ctx and calls f with the result,
+// handling signals and adding a stack trace to any error if requested.
+func runWithOptions(ctx context.Context, opts []Option, f func(context.Context) error) error {
+	ctx, err := withOptions(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("applying options: %w", err)
 	}
 
-	cmds := c.Subcmds()
+	ctx, cancel := withSignals(ctx)
+	defer cancel()
+
+	err = f(ctx)
+	if err != nil && getConfig(ctx).stackTraces {
+		// Errors from dispatch normally have a trace already (see traced);
+		// this catches any others.
+		err = withStack(err, 4) // skip runtime.Callers, withStack, runWithOptions, and Run
+	}
+	return err
+}
+
+// dispatch is the common implementation of [Run] and [Program.Run].
+// It runs the subcommand of c named in args[0].
+// The map cmds is the result of c.Subcmds,
+// or nil if c is a [Resolver].
+// If prog is not nil,
+// the subcommand's precompiled form is taken from it.
+func dispatch(ctx context.Context, c Cmd, cmds Map, prog *Program, args []string) (err error) {
+	if line, ok := completionRequest(ctx); ok {
+		return completeLine(ctx, c, cmds, line)
+	}
 
+	if h, ok := cmdAs[ErrorHandler](c); ok {
+		defer func() {
+			if err != nil {
+				err = h.HandleError(ctx, err)
+			}
+		}()
+	}
+
+	ctx, args, err = parseConfigFlag(ctx, c, args)
+	if err != nil {
+		return traced(ctx, err)
+	}
+
+	ctx, args, err = parsePersistentFlags(ctx, c, args)
+	if errors.Is(err, flag.ErrHelp) {
+		// "prog -h" where prog has persistent flags.
+		e := newHelpRequestedErr(ctx, subcmdPairList(ctx), c, cmds)
+		e.err = flag.ErrHelp
+		return traced(ctx, e)
+	}
+	if err != nil {
+		return traced(ctx, err)
+	}
+
+	ctx = withStrictCmd(ctx, c)
+	ctx = withCatalog(ctx, c)
+
+	if len(args) == 0 {
+		return traced(ctx, formatErr(ctx, &MissingSubcmdErr{
+			pairs:   subcmdPairList(ctx),
+			cmd:     c,
+			subcmds: cmds,
+			prog:    ProgName(ctx),
+			cat:     catalog(ctx),
+			df:      detailFormatter(ctx, c),
+		}))
+	}
+
+	origArgs := args
 	name := args[0]
 	args = args[1:]
-	subcmd, ok := cmds[name]
 
+	start := time.Now()
+	subcmd, ok := lookupSubcmd(c, cmds, name)
+	if !ok {
+		if r, isRenamer := cmdAs[Renamer](c); isRenamer {
+			if newName, renamed := r.Renames()[name]; renamed {
+				if subcmd, ok = lookupSubcmd(c, cmds, newName); ok {
+					warn(ctx, catalog(ctx).tr(MsgRenamedWarning), name, newName)
+					name = newName
+				}
+			}
+		}
+	}
+	addTiming(ctx, mapPhase, start)
+
+	if !ok && isHelpFlag(name) {
+		// "prog sub -h" where sub has nested subcommands.
+		logDebug(ctx, "help requested", "flag", name)
+		e := newHelpRequestedErr(ctx, subcmdPairList(ctx), c, cmds)
+		e.err = flag.ErrHelp
+		return traced(ctx, e)
+	}
 	if !ok && name == "help" {
-		e := &HelpRequestedErr{
-			pairs: subcmdPairList(ctx),
-			cmd:   c,
+		logDebug(ctx, "help requested", "args", args)
+		var jsonFlag, allFlag bool
+	helpFlags:
+		for len(args) > 0 {
+			switch args[0] {
+			case "-json", "--json":
+				jsonFlag = true
+			case "-all", "--all":
+				allFlag = true
+			default:
+				break helpFlags
+			}
+			args = args[1:]
 		}
+		hctx, pairs, hc, hcmds, args := helpPath(ctx, c, cmds, args)
+		e := newHelpRequestedErr(hctx, pairs, hc, hcmds)
+		e.json, e.all = jsonFlag, allFlag
 		if len(args) > 0 {
 			e.name = args[0]
 		}
-		return e
+		return traced(ctx, e)
+	}
+	if !ok && name == "tree" && getConfig(ctx).treeCmd {
+		logDebug(ctx, "tree requested")
+		return traced(ctx, tree(Stdout(ctx), c, catalog(ctx)))
 	}
 	if !ok {
-		unknownSubcmdErr := &UnknownSubcmdErr{
-			pairs: subcmdPairList(ctx),
-			cmd:   c,
-			name:  name,
-		}
+		unknownSubcmdErr := formatErr(ctx, &UnknownSubcmdErr{
+			pairs:   subcmdPairList(ctx),
+			cmd:     c,
+			subcmds: cmds,
+			name:    name,
+			prog:    ProgName(ctx),
+			cat:     catalog(ctx),
+			df:      detailFormatter(ctx, c),
+		})
 
-		if p, ok := c.(Prefixer); ok {
+		if p, ok := cmdAs[Prefixer](c); ok {
 			// The cmds map does not contain name,
 			// but c is a Prefixer so look for the executable prefix+name to run instead.
+			logDebug(ctx, "looking for plugin", "name", name, "prefix", p.Prefix())
+			return traced(ctx, runPrefixed(ctx, c, p.Prefix(), name, args, unknownSubcmdErr))
+		}
 
-			prefix := p.Prefix()
-			path, err := exec.LookPath(prefix + name)
-			if errors.Is(err, exec.ErrNotFound) {
-				return unknownSubcmdErr
-			}
-			if err != nil {
-				return errors.Wrapf(err, "looking for %s%s", prefix, name)
-			}
+		logDebug(ctx, "unknown subcommand", "name", name)
+		return traced(ctx, unknownSubcmdErr)
+	}
 
-			execCmd := exec.CommandContext(ctx, path, args...)
-			execCmd.Stdin, execCmd.Stdout, execCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if supported, reason := subcmd.supported(); !supported {
+		logDebug(ctx, "unsupported subcommand", "name", name, "reason", reason)
+		return traced(ctx, &UnsupportedErr{Name: name, Reason: reason, cat: catalog(ctx)})
+	}
 
-			j, err := json.Marshal(c)
-			if err != nil {
-				return errors.Wrap(err, "marshaling Cmd")
+	if subcmd.Deprecated != "" {
+		warn(ctx, catalog(ctx).tr(MsgDeprecatedWarning), name, subcmd.Deprecated)
+	}
+
+	// Before adding this subcommand to the path, for HelpRequestedErr.
+	helpCtx, helpPairs := ctx, subcmdPairList(ctx)
+
+	ctx = addSubcmdPair(ctx, name, subcmd)
+	ctx = context.WithValue(ctx, argsKey, origArgs)
+	ctx = addEnricher(ctx, c)
+
+	if i, ok := cmdAs[Initer](c); ok {
+		if err := i.Init(ctx); err != nil {
+			return traced(ctx, fmt.Errorf("initializing: %w", err))
+		}
+	}
+	if cl, ok := cmdAs[Closer](c); ok {
+		defer func() {
+			if closeErr := cl.Close(ctx); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("closing: %w", closeErr))
 			}
-			execCmd.Env = append(os.Environ(), EnvVar+"="+string(j))
+		}()
+	}
+
+	if subcmd.F == nil && subcmd.Sub != nil {
+		logDebug(ctx, "dispatching to nested command", "name", name)
+		if prog != nil {
+			return prog.subs[name].Run(ctx, args)
+		}
+		return Run(ctx, subcmd.Sub, args)
+	}
+
+	logDebug(ctx, "dispatching", "name", name)
+
+	ectx, err := enrich(ctx)
+	if err != nil {
+		return traced(ctx, err)
+	}
+	ctx = ectx
+
+	interval, args, err := parseWatchFlag(ctx, args)
+	if err != nil {
+		return traced(ctx, err)
+	}
+
+	args, err = wizard(ctx, subcmd.Params, args)
+	if err != nil {
+		return traced(ctx, err)
+	}
 
-			return execCmd.Run()
+	run := func() error {
+		start := time.Now()
+		err := compileAndInvoke(ctx, name, subcmd, prog, args)
+		audit(ctx, start, subcmd.Params, err)
+		if errors.Is(err, flag.ErrHelp) {
+			// "prog sub -h" is the same as "prog help sub".
+			e := newHelpRequestedErr(helpCtx, helpPairs, c, cmds)
+			e.name = name
+			e.err = flag.ErrHelp
+			return traced(ctx, e)
 		}
+		return err
+	}
+	if interval > 0 {
+		logDebug(ctx, "watching", "name", name, "interval", interval)
+		return watch(ctx, interval, run)
+	}
+	return run()
+}
 
-		return unknownSubcmdErr
+func compileAndInvoke(ctx context.Context, name string, subcmd Subcmd, prog *Program, args []string) error {
+	var cs *compiled
+	if prog != nil {
+		cs = prog.compiled[name]
+	} else {
+		var err error
+		start := time.Now()
+		cs, err = compile(subcmd)
+		addTiming(ctx, checkPhase, start)
+		if err != nil {
+			return traced(ctx, fmt.Errorf("checking function type: %w", err))
+		}
 	}
 
-	ctx = addSubcmdPair(ctx, name, subcmd)
+	return invoke(ctx, name, cs, args)
+}
 
-	fv := reflect.ValueOf(subcmd.F)
-	ft := fv.Type()
+// compiled is the result of checking a Subcmd's function type.
+type compiled struct {
+	subcmd    Subcmd
+	ft        reflect.Type
+	variadic  bool
+	nInjected int
+	opts      *optsPlan
+	flags     *flagSetBlueprint
+}
 
-	if err := checkFuncType(ft, subcmd.Params); err != nil {
-		return errors.Wrap(err, "checking function type")
+func compile(subcmd Subcmd) (*compiled, error) {
+	ft := reflect.TypeOf(subcmd.F)
+	if ft == nil {
+		return nil, FuncTypeErr{}
+	}
+	plan := cachedFuncPlan(ft, funcParams(subcmd.Params))
+	if plan.err != nil {
+		return nil, plan.err
+	}
+	flags, err := newFlagSetBlueprint(subcmd.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &compiled{
+		subcmd:    subcmd,
+		ft:        ft,
+		variadic:  ft.IsVariadic(),
+		nInjected: plan.nInjected,
+		opts:      plan.opts,
+		flags:     flags,
+	}, nil
+}
+
+// invoke parses args according to cs and calls its function.
+func invoke(ctx context.Context, name string, cs *compiled, args []string) error {
+	var (
+		subcmd   = cs.subcmd
+		ft       = cs.ft
+		variadic = cs.variadic
+	)
+
+	limits := argLimits{
+		strict: subcmd.Strict || (getConfig(ctx).strictArgs && !variadic),
+		min:    subcmd.MinArgs,
+		max:    subcmd.MaxArgs,
+	}
+
+	start := time.Now()
+	argvals, err := parseArgs(ctx, subcmd.Params, cs.flags, args, variadic, limits)
+	addTiming(ctx, parsePhase, start)
+	if err != nil {
+		if ferr := reformat(ctx, err); ferr != nil {
+			return traced(ctx, ferr)
+		}
+		return traced(ctx, fmt.Errorf("marshaling args: %w", err))
+	}
+
+	logParsed(ctx, name, subcmd.Params, argvals, variadic)
+
+	argvals, err = storeVars(subcmd.Params, argvals)
+	if err != nil {
+		return traced(ctx, err)
 	}
 
-	variadic := ft.IsVariadic()
+	nparams := len(funcParams(subcmd.Params))
+	if cs.opts != nil {
+		argvals = cs.opts.fill(argvals)
+		nparams = 1
+	}
 
-	argvals, err := parseArgs(ctx, subcmd.Params, args, variadic)
+	argvals, err = inject(ctx, ft, cs.nInjected, argvals)
 	if err != nil {
-		return errors.Wrap(err, "marshaling args")
+		return traced(ctx, fmt.Errorf("injecting dependencies: %w", err))
 	}
 
 	numIn := ft.NumIn()
@@ -431,35 +1081,55 @@ func Run(ctx context.Context, c Cmd, args []string) error {
 	for i, argval := range argvals {
 		if variadic && i >= (numIn-1) {
 			if !argval.Type().AssignableTo(strType) {
-				return fmt.Errorf("type of arg %d is %s, want string", i, argval.Type())
+				return traced(ctx, fmt.Errorf("type of arg %d is %s, want string", i, argval.Type()))
 			}
 		} else if !argval.Type().AssignableTo(ft.In(i)) {
-			return fmt.Errorf("type of arg %d is %s, want %s", i, ft.In(i), argval.Type())
+			v, ok := fromGetter(argval, ft.In(i))
+			if !ok {
+				return traced(ctx, fmt.Errorf("type of arg %d is %s, want %s", i, ft.In(i), argval.Type()))
+			}
+			argvals[i] = v
 		}
 	}
 
-	rv := fv.Call(argvals)
+	fctx, cancel := withTimeout(subcmd.Timeout, argvals)
+	defer cancel()
 
-	if ft.NumOut() == 1 {
-		err, _ = rv[0].Interface().(error)
+	start = time.Now()
+	err = profile(ctx, func() error {
+		return callWithGrace(fctx, subcmd.Cleanup, func() error {
+			return callF(subcmd.F, cs.nInjected+nparams, variadic, argvals)
+		})
+	})
+	addTiming(ctx, callPhase, start)
+	if err != nil {
+		if timedOut(ctx, subcmd.Timeout, err) {
+			return traced(ctx, TimeoutErr{Name: name, Timeout: subcmd.Timeout, Err: err})
+		}
+		return traced(ctx, fmt.Errorf("running %s: %w", name, err))
 	}
-
-	return errors.Wrapf(err, "running %s", name)
+	return nil
 }
 
 // EnvVar is the name of the environment variable used by [Run] to pass the JSON-encoded [Cmd] to a subprocess.
 // Use [ParseEnv] to decode it.
-// See [Prefixer].
+// See [Prefixer] and [EnvVarNamer].
 const EnvVar = "SUBCMD_ENV"
 
 // ParseEnv parses the value of the SUBCMD_ENV environment variable,
 // placing the result in the value pointed to by ptr,
 // which must be a pointer of a suitable type.
 // Executables that implement subcommands should run this at startup.
+//
+// If ptr implements [EnvVarNamer],
+// the variable named by its EnvVarName method is parsed instead.
+//
+// If the parent process speaks a newer version of the plugin protocol than this package,
+// the result is a [ProtocolErr].
+// See [ProtocolEnvVar].
+// If the variable is present but cannot be decoded,
+// the result is an [EnvErr].
+// See also [ParseEnvStrict] and [EnvEncoder].
 func ParseEnv(ptr interface{}) error {
-	val := os.Getenv(EnvVar)
-	if val == "" {
-		return nil
-	}
-	return json.Unmarshal([]byte(val), ptr)
+	return parseEnv(ptr, false)
 }
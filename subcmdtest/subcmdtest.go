@@ -0,0 +1,75 @@
+// Package subcmdtest helps in testing programs built with subcmd.
+//
+// Its [Run] function runs a [subcmd.Cmd] on a command line
+// and collects everything a test might want to examine:
+// the subcommand's standard output and standard error
+// (for subcommand functions that use [subcmd.Stdout] and [subcmd.Stderr]),
+// the error,
+// and the parsed [flag.FlagSet].
+// This makes table-driven tests of a command-line interface straightforward:
+//
+//	for _, tc := range cases {
+//		res := subcmdtest.Run(ctx, cmd, tc.args, subcmd.WithProgName("prog"))
+//		if res.Stdout != tc.want {
+//			...
+//		}
+//	}
+package subcmdtest
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+// Result is the outcome of a call to [Run].
+type Result struct {
+	// Stdout and Stderr are what the subcommand wrote to [subcmd.Stdout] and [subcmd.Stderr].
+	Stdout, Stderr string
+
+	// Err is the error returned by [subcmd.Run],
+	// with its wrapping intact,
+	// so that tests can examine it with [errors.Is] and [errors.As].
+	Err error
+
+	// FlagSet is the flag set parsed for the invoked subcommand
+	// (see [subcmd.WithParsedFlagSet]),
+	// or nil if [subcmd.Run] did not get that far
+	// (e.g. because of an unknown subcommand name).
+	FlagSet *flag.FlagSet
+}
+
+// Run calls [subcmd.Run] with the given context, Cmd, args, and options,
+// capturing the subcommand's output and parsed flags.
+// Standard input is empty
+// unless a [subcmd.WithStdio] option among opts supplies one.
+func Run(ctx context.Context, c subcmd.Cmd, args []string, opts ...subcmd.Option) Result {
+	var (
+		stdout, stderr strings.Builder
+		res            Result
+	)
+	opts = append([]subcmd.Option{
+		subcmd.WithStdio(strings.NewReader(""), &stdout, &stderr),
+		subcmd.WithParsedFlagSet(&res.FlagSet),
+	}, opts...)
+
+	res.Err = subcmd.Run(ctx, c, args, opts...)
+	res.Stdout, res.Stderr = stdout.String(), stderr.String()
+	return res
+}
+
+// Lookup returns the value of the named flag in r.FlagSet,
+// as with [flag.FlagSet.Lookup],
+// and false if there is no FlagSet or no such flag.
+func (r Result) Lookup(name string) (string, bool) {
+	if r.FlagSet == nil {
+		return "", false
+	}
+	f := r.FlagSet.Lookup(name)
+	if f == nil {
+		return "", false
+	}
+	return f.Value.String(), true
+}
@@ -0,0 +1,117 @@
+package subcmdtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bobg/subcmd/v2"
+)
+
+type testcmd struct{}
+
+func (testcmd) Subcmds() subcmd.Map {
+	return subcmd.Commands(
+		"greet", greet, "print a greeting", subcmd.Params(
+			"-n", subcmd.Int, 1, "number of times",
+			"name", subcmd.String, "", "whom to greet",
+		),
+		"cat", cat, "copy stdin to stdout", nil,
+	)
+}
+
+func greet(ctx context.Context, n int, name string, _ []string) error {
+	if name == "nobody" {
+		return errNobody
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(subcmd.Stdout(ctx), "hello, %s\n", name)
+	}
+	fmt.Fprintln(subcmd.Stderr(ctx), "done")
+	return nil
+}
+
+func cat(ctx context.Context, _ []string) error {
+	_, err := io.Copy(subcmd.Stdout(ctx), subcmd.Stdin(ctx))
+	return err
+}
+
+var errNobody = errors.New("nobody to greet")
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		args         []string
+		wantStdout   string
+		wantStderr   string
+		wantErr      error
+		wantN        string
+		wantFlagSet  bool
+		wantUsageErr bool
+	}{{
+		args:        []string{"greet", "-n", "2", "world"},
+		wantStdout:  "hello, world\nhello, world\n",
+		wantStderr:  "done\n",
+		wantN:       "2",
+		wantFlagSet: true,
+	}, {
+		args:        []string{"greet", "nobody"},
+		wantErr:     errNobody,
+		wantN:       "1",
+		wantFlagSet: true,
+	}, {
+		args:         []string{"frobnicate"},
+		wantUsageErr: true,
+	}, {
+		args:        []string{"cat"},
+		wantFlagSet: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			res := Run(ctx, testcmd{}, tc.args, subcmd.WithProgName("prog"))
+
+			if res.Stdout != tc.wantStdout {
+				t.Errorf("got stdout %q, want %q", res.Stdout, tc.wantStdout)
+			}
+			if res.Stderr != tc.wantStderr {
+				t.Errorf("got stderr %q, want %q", res.Stderr, tc.wantStderr)
+			}
+
+			switch {
+			case tc.wantErr != nil:
+				if !errors.Is(res.Err, tc.wantErr) {
+					t.Errorf("got error %v, want %v", res.Err, tc.wantErr)
+				}
+			case tc.wantUsageErr:
+				var uerr subcmd.UsageErr
+				if !errors.As(res.Err, &uerr) {
+					t.Errorf("got error %v, want a UsageErr", res.Err)
+				}
+			case res.Err != nil:
+				t.Errorf("unexpected error %v", res.Err)
+			}
+
+			if (res.FlagSet != nil) != tc.wantFlagSet {
+				t.Errorf("got FlagSet %v, want one: %v", res.FlagSet, tc.wantFlagSet)
+			}
+			if n, _ := res.Lookup("n"); n != tc.wantN {
+				t.Errorf(`got -n "%s", want "%s"`, n, tc.wantN)
+			}
+		})
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	res := Run(context.Background(), testcmd{}, []string{"cat"}, subcmd.WithStdio(strings.NewReader("meow\n"), nil, nil))
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res.Stdout != "meow\n" {
+		t.Errorf(`got "%s", want "meow\n"`, res.Stdout)
+	}
+}
@@ -0,0 +1,79 @@
+package subcmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// suggestions returns the elements of names that are close enough to name
+// to be plausible misspellings of it,
+// closest first.
+func suggestions(name string, names []string) []string {
+	maxdist := len(name) / 3
+	if maxdist < 1 {
+		maxdist = 1
+	}
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var candidates []candidate
+	for _, n := range names {
+		if d := editDistance(name, n); d <= maxdist && d < len(name) {
+			candidates = append(candidates, candidate{name: n, dist: d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+
+	var result []string
+	for _, c := range candidates {
+		result = append(result, c.name)
+	}
+	return result
+}
+
+// didYouMean formats a list of suggestions as a question,
+// or returns "" if there are none.
+func didYouMean(suggestions []string, cat Catalog) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, s))
+	}
+	return fmt.Sprintf(cat.tr(MsgDidYouMean), strings.Join(quoted, cat.tr(MsgOr)))
+}
+
+// editDistance computes the edit distance between a and b,
+// counting insertions, deletions, substitutions,
+// and transpositions of adjacent characters
+// (the "optimal string alignment" distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,48 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSuggestions(t *testing.T) {
+	names := []string{"add", "last", "list", "remove"}
+
+	cases := []struct {
+		name string
+		want []string
+	}{
+		{name: "lst", want: []string{"last", "list"}},
+		{name: "lits", want: []string{"list"}},
+		{name: "remvoe", want: []string{"remove"}},
+		{name: "a"},
+		{name: "xyz"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := suggestions(tc.name, names)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnknownSubcmdSuggestion(t *testing.T) {
+	err := Run(context.Background(), errtestcmd{}, []string{"cc"})
+	var u *UnknownSubcmdErr
+	if !errors.As(err, &u) {
+		t.Fatalf("got %v, want *UnknownSubcmdErr", err)
+	}
+	if got, want := u.Error(), `unknown subcommand "cc", did you mean "ccc"?`; got != want {
+		t.Errorf(`got "%s", want "%s"`, got, want)
+	}
+	if detail := u.Detail(); !strings.HasPrefix(detail, "Unknown subcommand \"cc\", did you mean \"ccc\"?\nWant one of:\n") {
+		t.Errorf("got detail %q", detail)
+	}
+}
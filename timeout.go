@@ -0,0 +1,53 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// TimeoutErr is the error returned by [Run]
+// when a subcommand with a Timeout (see [Subcmd]) fails
+// because that timeout expired.
+type TimeoutErr struct {
+	// Name is the name of the subcommand.
+	Name string
+
+	// Timeout is the subcommand's Timeout.
+	Timeout time.Duration
+
+	// Err is the error returned by the subcommand's function.
+	// It is or wraps [context.DeadlineExceeded].
+	Err error
+}
+
+func (e TimeoutErr) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Name, e.Timeout)
+}
+
+// Unwrap unwraps the nested error in e.
+func (e TimeoutErr) Unwrap() error {
+	return e.Err
+}
+
+// withTimeout applies the timeout d, if positive,
+// to the context in argvals[0].
+// It returns that context and a function to release its resources.
+func withTimeout(d time.Duration, argvals []reflect.Value) (context.Context, context.CancelFunc) {
+	ctx := argvals[0].Interface().(context.Context)
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	argvals[0] = reflect.ValueOf(ctx)
+	return ctx, cancel
+}
+
+// timedOut tells whether err, returned by a subcommand function with timeout d,
+// was caused by the expiration of that timeout
+// and not by an earlier deadline in parent.
+func timedOut(parent context.Context, d time.Duration, err error) bool {
+	return d > 0 && errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil
+}
@@ -0,0 +1,76 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	var sawDeadline bool
+	c := testcmdfunc(func() Map {
+		return Map{
+			"slow": Subcmd{
+				F: func(ctx context.Context, _ []string) error {
+					_, sawDeadline = ctx.Deadline()
+					<-ctx.Done()
+					return ctx.Err()
+				},
+				Timeout: 10 * time.Millisecond,
+			},
+			"fast": Subcmd{
+				F: func(ctx context.Context, _ []string) error {
+					_, sawDeadline = ctx.Deadline()
+					return nil
+				},
+				Timeout: time.Minute,
+			},
+		}
+	})
+
+	err := Run(context.Background(), c, []string{"slow"})
+	var terr TimeoutErr
+	if !errors.As(err, &terr) {
+		t.Fatalf("got %v, want TimeoutErr", err)
+	}
+	if terr.Name != "slow" || terr.Timeout != 10*time.Millisecond {
+		t.Errorf("got name %s, timeout %s; want slow, 10ms", terr.Name, terr.Timeout)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error %v does not wrap %v", err, context.DeadlineExceeded)
+	}
+	if !sawDeadline {
+		t.Error("slow subcommand's context had no deadline")
+	}
+
+	sawDeadline = false
+	if err := Run(context.Background(), c, []string{"fast"}); err != nil {
+		t.Fatal(err)
+	}
+	if !sawDeadline {
+		t.Error("fast subcommand's context had no deadline")
+	}
+
+	// A deadline from the caller is not the subcommand's timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	c2 := testcmdfunc(func() Map {
+		return Map{
+			"slow": Subcmd{
+				F: func(ctx context.Context, _ []string) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+				Timeout: time.Minute,
+			},
+		}
+	})
+	err = Run(ctx, c2, []string{"slow"})
+	if errors.As(err, &terr) {
+		t.Errorf("got TimeoutErr for caller's deadline: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error %v does not wrap %v", err, context.DeadlineExceeded)
+	}
+}
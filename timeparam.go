@@ -0,0 +1,67 @@
+package subcmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// timeValue is the [flag.Value] for a parameter of type [Time].
+type timeValue struct {
+	ptr     *time.Time
+	layouts []string
+}
+
+func (v *timeValue) String() string {
+	if v == nil || v.ptr == nil || v.ptr.IsZero() {
+		return ""
+	}
+	return v.ptr.Format(timeLayouts(v.layouts)[0])
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := parseTime(v.layouts, s)
+	if err != nil {
+		return err
+	}
+	*v.ptr = t
+	return nil
+}
+
+// Get implements [flag.Getter].
+func (v *timeValue) Get() interface{} {
+	return *v.ptr
+}
+
+func timeLayouts(layouts []string) []string {
+	if len(layouts) == 0 {
+		return []string{time.RFC3339}
+	}
+	return layouts
+}
+
+// parseTime parses s according to the first of layouts that works.
+func parseTime(layouts []string, s string) (time.Time, error) {
+	layouts = timeLayouts(layouts)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(`cannot parse "%s" as a time with layout %s`, s, strings.Join(layouts, " or "))
+}
+
+func parseTimePos(args *[]string, argvals *[]reflect.Value, p Param) error {
+	val, _ := p.Default.(time.Time)
+	if len(*args) > 0 {
+		var err error
+		val, err = parseTime(p.Layouts, (*args)[0])
+		if err != nil {
+			return parseErr(p, (*args)[0], err)
+		}
+		*args = (*args)[1:]
+	}
+	*argvals = append(*argvals, reflect.ValueOf(val))
+	return nil
+}
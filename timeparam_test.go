@@ -0,0 +1,52 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeParam(t *testing.T) {
+	var gotSince, gotDay time.Time
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, since, day time.Time, _ []string) { gotSince, gotDay = since, day },
+				Params: []Param{
+					{Name: "-since", Type: Time, Default: time.Time{}},
+					{Name: "day?", Type: Time, Default: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), Layouts: []string{"2006-01-02", "Jan 2 2006"}},
+				},
+			},
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-since", "2024-03-04T05:06:07Z", "Feb 3 2021"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC); !gotSince.Equal(want) {
+		t.Errorf("got since %s, want %s", gotSince, want)
+	}
+	if want := time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC); !gotDay.Equal(want) {
+		t.Errorf("got day %s, want %s", gotDay, want)
+	}
+
+	if err := Run(context.Background(), c, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotSince.IsZero() {
+		t.Errorf("got since %s, want zero", gotSince)
+	}
+	if want := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC); !gotDay.Equal(want) {
+		t.Errorf("got day %s, want default %s", gotDay, want)
+	}
+
+	var f FlagErr
+	if err := Run(context.Background(), c, []string{"x", "-since", "yesterday"}); !errors.As(err, &f) {
+		t.Errorf("got %v, want FlagErr", err)
+	}
+	var p ParseErr
+	if err := Run(context.Background(), c, []string{"x", "2021/02/03"}); !errors.As(err, &p) {
+		t.Errorf("got %v, want ParseErr", err)
+	}
+}
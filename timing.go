@@ -0,0 +1,71 @@
+package subcmd
+
+import (
+	"context"
+	"time"
+)
+
+// Timings records how long the phases of a call to [Run] took.
+// See [WithTimings].
+type Timings struct {
+	// Map is the time spent building the [Map] of subcommands
+	// (i.e., in Cmd.Subcmds or Resolver.Resolve).
+	Map time.Duration
+
+	// Check is the time spent checking the subcommand's function type.
+	Check time.Duration
+
+	// Parse is the time spent parsing flags and positional arguments.
+	Parse time.Duration
+
+	// Call is the time spent in the subcommand's function.
+	Call time.Duration
+}
+
+// Total is the sum of the durations in t.
+func (t Timings) Total() time.Duration {
+	return t.Map + t.Check + t.Parse + t.Call
+}
+
+// WithTimings is an [Option] that causes [Run] to record how long each phase of dispatching a subcommand took,
+// adding the durations to the fields of *t.
+// The durations from nested calls to Run are added too
+// (and are also included in the Call time of the enclosing subcommand).
+// This is useful in diagnosing why a program is slow to start.
+//
+// The caller must not access *t until Run returns.
+func WithTimings(t *Timings) Option {
+	return func(c *config) error {
+		c.timings = t
+		return nil
+	}
+}
+
+type timingPhase int
+
+const (
+	mapPhase timingPhase = iota
+	checkPhase
+	parsePhase
+	callPhase
+)
+
+// addTiming adds the time since start to the given phase,
+// if timings are being recorded.
+func addTiming(ctx context.Context, phase timingPhase, start time.Time) {
+	t := getConfig(ctx).timings
+	if t == nil {
+		return
+	}
+	d := time.Since(start)
+	switch phase {
+	case mapPhase:
+		t.Map += d
+	case checkPhase:
+		t.Check += d
+	case parsePhase:
+		t.Parse += d
+	case callPhase:
+		t.Call += d
+	}
+}
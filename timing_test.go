@@ -0,0 +1,27 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	c := dotenvtestcmd(func(context.Context, []string) {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	var tm Timings
+	if err := Run(context.Background(), c, []string{"x"}, WithTimings(&tm)); err != nil {
+		t.Fatal(err)
+	}
+	if tm.Call < 10*time.Millisecond {
+		t.Errorf("got call time %s, want at least 10ms", tm.Call)
+	}
+	if tm.Map <= 0 || tm.Check <= 0 || tm.Parse <= 0 {
+		t.Errorf("got zero durations in %+v", tm)
+	}
+	if tm.Total() < tm.Call {
+		t.Errorf("total %s is less than call time %s", tm.Total(), tm.Call)
+	}
+}
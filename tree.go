@@ -0,0 +1,60 @@
+package subcmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithTreeCmd is an [Option] that enables a built-in "tree" pseudo-subcommand.
+// When [Run] encounters the subcommand name "tree"
+// and the [Cmd] does not define a subcommand by that name,
+// it writes the output of [Tree] for the Cmd to [Stdout].
+// Like "help",
+// it works at any level of nesting:
+// "prog db tree" shows only the subcommands of "db".
+func WithTreeCmd() Option {
+	return func(c *config) error {
+		c.treeCmd = true
+		return nil
+	}
+}
+
+// Tree writes the full hierarchy of c's subcommands to w,
+// one per line in name order,
+// with nested subcommands (see Subcmd.Sub) indented beneath their parents
+// and each followed by its one-line description.
+func Tree(w io.Writer, c Cmd) error {
+	return tree(w, c, nil)
+}
+
+func tree(w io.Writer, c Cmd, cat Catalog) error {
+	type row struct {
+		name, desc string
+	}
+
+	var (
+		rows   []row
+		maxlen int
+	)
+	err := walk(c, nil, func(path []string, subcmd Subcmd) error {
+		name := strings.Repeat("  ", len(path)-1) + path[len(path)-1]
+		if len(name) > maxlen {
+			maxlen = len(name)
+		}
+		rows = append(rows, row{name: name, desc: subcmd.helpDesc(cat)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	format := fmt.Sprintf("%%-%ds  %%s", maxlen)
+	for _, r := range rows {
+		line := strings.TrimRight(fmt.Sprintf(format, r.name, r.desc), " ")
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
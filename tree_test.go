@@ -0,0 +1,112 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTree(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Run(context.Background(), nestedtestcmd{}, []string{"tree"}, WithTreeCmd(), WithStdio(nil, &buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `a          Do a
+bb         Do b
+ccc        Do c
+db         database commands
+  migrate  run migrations
+  status   show migration status
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := Run(context.Background(), nestedtestcmd{}, []string{"db", "tree"}, WithTreeCmd(), WithStdio(nil, &buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	want = `migrate  run migrations
+status   show migration status
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	var u *UnknownSubcmdErr
+	if err := Run(context.Background(), nestedtestcmd{}, []string{"tree"}); !errors.As(err, &u) {
+		t.Errorf("got %v, want *UnknownSubcmdErr", err)
+	}
+}
+
+func TestHelpAll(t *testing.T) {
+	err := Run(context.Background(), nestedtestcmd{}, []string{"help", "-all"})
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	want := `Subcommands are:
+a          Do a
+bb         Do b
+ccc        Do c
+db         database commands
+  migrate  run migrations
+  status   show migration status
+`
+	if diff := cmp.Diff(want, herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	err = Run(context.Background(), nestedtestcmd{}, []string{"help", "-all", "-json"})
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	var info HelpInfo
+	if err := json.Unmarshal([]byte(herr.Detail()), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Subcmds) != 4 {
+		t.Fatalf("got %d subcommands, want 4", len(info.Subcmds))
+	}
+	var nested []string
+	for _, sub := range info.Subcmds[3].Subcmds {
+		nested = append(nested, sub.Name)
+	}
+	if diff := cmp.Diff([]string{"migrate", "status"}, nested); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	c := testcmdfunc(func() Map {
+		f := func(context.Context, []string) {}
+		return Map{
+			"alpha":   Subcmd{F: f, Desc: "alpha"},
+			"beta":    Subcmd{F: f, Desc: "beta"},
+			"init":    Subcmd{F: f, Desc: "initialize", Order: -2},
+			"run":     Subcmd{F: f, Desc: "run", Order: -1},
+			"version": Subcmd{F: f, Desc: "show version", Order: 1},
+		}
+	})
+
+	err := Run(context.Background(), c, []string{"help"})
+	var herr *HelpRequestedErr
+	if !errors.As(err, &herr) {
+		t.Fatalf("got %v, want *HelpRequestedErr", err)
+	}
+	want := `Subcommands are:
+init     initialize
+run      run
+alpha    alpha
+beta     beta
+version  show version
+`
+	if diff := cmp.Diff(want, herr.Detail()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,108 @@
+package subcmd
+
+import (
+	"context"
+	"time"
+)
+
+// ParamType is the set of Go types that a [TypedParam] may have.
+// They correspond to the parameter [Type] values other than [Value].
+type ParamType interface {
+	bool | int | int64 | uint | uint64 | string | float64 | time.Duration | time.Time | []string | map[string]string
+}
+
+// TypedParam is a [Param] whose type is given by the type parameter T
+// rather than by a [Type] value.
+// It is used with [Command1] and its siblings,
+// which construct a [Subcmd] whose parameters are checked against its function at compile time.
+type TypedParam[T ParamType] struct {
+	// Name is the parameter's name,
+	// with the same meaning as in [Param].
+	Name string
+
+	// Default is the parameter's default value.
+	Default T
+
+	// Doc is a docstring for the parameter.
+	Doc string
+
+	// Required tells whether the parameter is a required flag,
+	// with the same meaning as in [Param].
+	Required bool
+}
+
+// P is a convenience function for constructing a [TypedParam],
+// letting the compiler infer T from dflt.
+func P[T ParamType](name string, dflt T, doc string) TypedParam[T] {
+	return TypedParam[T]{Name: name, Default: dflt, Doc: doc}
+}
+
+func (p TypedParam[T]) param() Param {
+	return Param{
+		Name:     p.Name,
+		Type:     paramTypeOf[T](),
+		Default:  p.Default,
+		Doc:      p.Doc,
+		Required: p.Required,
+	}
+}
+
+func paramTypeOf[T ParamType]() Type {
+	var zero T
+	switch any(zero).(type) {
+	case bool:
+		return Bool
+	case int:
+		return Int
+	case int64:
+		return Int64
+	case uint:
+		return Uint
+	case uint64:
+		return Uint64
+	case string:
+		return String
+	case float64:
+		return Float64
+	case time.Duration:
+		return Duration
+	case time.Time:
+		return Time
+	case []string:
+		return Strings
+	default: // map[string]string
+		return StringMap
+	}
+}
+
+// Command0 constructs a [Subcmd] with no parameters.
+// It is the type-safe counterpart of a [Subcmd] literal whose F takes only a context and the remaining args.
+func Command0(f func(context.Context, []string) error, desc string) Subcmd {
+	return Subcmd{F: f, Desc: desc}
+}
+
+// Command1 constructs a [Subcmd] with one parameter.
+// Unlike with a [Subcmd] literal or [Commands],
+// the compiler verifies that the type of the parameter matches the corresponding argument of f,
+// so there is no need to call [Check] on the result.
+//
+// Parameters of type [Value] are not supported by the typed constructors.
+// Use a Subcmd literal for those.
+func Command1[A ParamType](f func(context.Context, A, []string) error, desc string, a TypedParam[A]) Subcmd {
+	return Subcmd{F: f, Desc: desc, Params: []Param{a.param()}}
+}
+
+// Command2 is like [Command1] but for a subcommand with two parameters.
+func Command2[A, B ParamType](f func(context.Context, A, B, []string) error, desc string, a TypedParam[A], b TypedParam[B]) Subcmd {
+	return Subcmd{F: f, Desc: desc, Params: []Param{a.param(), b.param()}}
+}
+
+// Command3 is like [Command1] but for a subcommand with three parameters.
+func Command3[A, B, C ParamType](f func(context.Context, A, B, C, []string) error, desc string, a TypedParam[A], b TypedParam[B], c TypedParam[C]) Subcmd {
+	return Subcmd{F: f, Desc: desc, Params: []Param{a.param(), b.param(), c.param()}}
+}
+
+// Command4 is like [Command1] but for a subcommand with four parameters.
+func Command4[A, B, C, D ParamType](f func(context.Context, A, B, C, D, []string) error, desc string, a TypedParam[A], b TypedParam[B], c TypedParam[C], d TypedParam[D]) Subcmd {
+	return Subcmd{F: f, Desc: desc, Params: []Param{a.param(), b.param(), c.param(), d.param()}}
+}
@@ -0,0 +1,50 @@
+package subcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTypedCommands(t *testing.T) {
+	var (
+		gotVerbose bool
+		gotN       int
+		gotDir     string
+		gotArgs    []string
+	)
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Command3(func(_ context.Context, verbose bool, n int, dir string, args []string) error {
+				gotVerbose, gotN, gotDir, gotArgs = verbose, n, dir, args
+				return nil
+			}, "do x",
+				P("-v", false, "be verbose"),
+				P("-n", 1, "how many"),
+				P("dir", "", "directory"),
+			),
+		}
+	})
+
+	if err := Run(context.Background(), c, []string{"x", "-v", "-n", "3", "here", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gotVerbose || gotN != 3 || gotDir != "here" || len(gotArgs) != 1 || gotArgs[0] != "a" {
+		t.Errorf("got verbose=%v n=%d dir=%s args=%v, want true 3 here [a]", gotVerbose, gotN, gotDir, gotArgs)
+	}
+}
+
+func TestTypedCheck(t *testing.T) {
+	cases := map[string]Subcmd{
+		"0": Command0(func(context.Context, []string) error { return nil }, ""),
+		"1": Command1(func(context.Context, int64, []string) error { return nil }, "", P("-a", int64(0), "")),
+		"2": Command2(func(context.Context, uint, uint64, []string) error { return nil }, "", P("-a", uint(0), ""), P("-b", uint64(0), "")),
+		"4": Command4(func(context.Context, float64, time.Duration, string, bool, []string) error { return nil }, "",
+			P("-a", 0.0, ""), P("-b", time.Second, ""), P("-c", "", ""), P("-d", false, "")),
+	}
+	for name, subcmd := range cases {
+		if err := Check(subcmd); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
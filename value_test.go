@@ -127,3 +127,55 @@ func (v *valuetestvalue) Copy() flag.Value {
 	copy(result.result, v.result)
 	return result
 }
+
+func TestValueGetter(t *testing.T) {
+	var (
+		gotFlag, gotPos []string
+		gotValue        flag.Value
+	)
+	c := testcmdfunc(func() Map {
+		return Commands(
+			"get", func(_ context.Context, x []string, pos []string, _ []string) {
+				gotFlag, gotPos = x, pos
+			}, "", Params(
+				"-x", Value, &gettertestvalue{result: []string{"dflt"}}, "",
+				"pos", Value, &gettertestvalue{}, "",
+			),
+			"value", func(_ context.Context, x flag.Value, _ []string) {
+				gotValue = x
+			}, "", Params(
+				"-x", Value, &gettertestvalue{}, "",
+			),
+		)
+	})
+
+	if err := Run(context.Background(), c, []string{"get", "a,b"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotFlag, []string{"dflt"}) {
+		t.Errorf("got flag %v, want [dflt]", gotFlag)
+	}
+	if !reflect.DeepEqual(gotPos, []string{"a", "b"}) {
+		t.Errorf("got positional %v, want [a b]", gotPos)
+	}
+
+	// A function may still take the flag.Value itself.
+	if err := Run(context.Background(), c, []string{"value", "-x", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gotValue.(*gettertestvalue); !ok {
+		t.Errorf("got %T, want *gettertestvalue", gotValue)
+	}
+}
+
+type gettertestvalue struct {
+	result []string
+}
+
+func (v *gettertestvalue) String() string     { return strings.Join(v.result, ",") }
+func (v *gettertestvalue) Set(s string) error { v.result = strings.Split(s, ","); return nil }
+func (v *gettertestvalue) Get() interface{}   { return v.result }
+
+func (v *gettertestvalue) Copy() flag.Value {
+	return &gettertestvalue{result: append([]string(nil), v.result...)}
+}
@@ -0,0 +1,34 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithWarningHandler is an [Option] that directs warnings produced by [Run],
+// such as notices about deprecated subcommand names (see [Renamer]),
+// to h.
+// By default they are written to [Stderr],
+// prefixed with the program name (see [ProgName]).
+//
+// This lets programs that embed this package,
+// such as GUIs and daemons,
+// surface warnings in their own way.
+func WithWarningHandler(h func(ctx context.Context, msg string)) Option {
+	return func(c *config) error {
+		c.warningHandler = h
+		return nil
+	}
+}
+
+// warn emits a warning about the current run,
+// such as a deprecation notice.
+func warn(ctx context.Context, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logDebug(ctx, "warning", "msg", msg)
+	if h := getConfig(ctx).warningHandler; h != nil {
+		h(ctx, msg)
+		return
+	}
+	fmt.Fprintf(Stderr(ctx), "%s: warning: %s\n", ProgName(ctx), msg)
+}
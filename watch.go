@@ -0,0 +1,127 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WithWatch is an [Option] that enables a "watch" mode for every subcommand.
+// When the first argument after a subcommand's name is "-watch INTERVAL"
+// (or "--watch INTERVAL", "-watch=INTERVAL", or "--watch=INTERVAL"),
+// where INTERVAL is a duration like "5s",
+// [Run] runs the subcommand repeatedly,
+// waiting INTERVAL between the end of one run and the start of the next,
+// until its context is canceled.
+// Then Run returns nil.
+//
+// Errors from individual runs are reported as warnings (see [WithWarningHandler])
+// and do not stop the repetition,
+// except for usage errors
+// (such as a bad flag or too few arguments)
+// and requests for help,
+// which Run returns immediately.
+//
+// See [WithWatchTrigger] for a way to rerun a subcommand on other events,
+// such as changes to files.
+func WithWatch() Option {
+	return func(c *config) error {
+		c.watch = true
+		return nil
+	}
+}
+
+// WithWatchTrigger is an [Option] that enables watch mode (see [WithWatch])
+// and replaces its interval timer with trigger.
+// The trigger function is called once,
+// with the interval given on the command line,
+// when watch mode begins.
+// The subcommand is run once at the start,
+// then again each time a value is received on the resulting channel.
+// Watching ends when the context is canceled or the channel is closed.
+//
+// The trigger function might, for example,
+// watch a directory and send a value whenever a file in it changes,
+// using the interval to debounce rapid changes.
+func WithWatchTrigger(trigger func(ctx context.Context, interval time.Duration) <-chan struct{}) Option {
+	return func(c *config) error {
+		c.watch = true
+		c.watchTrigger = trigger
+		return nil
+	}
+}
+
+// parseWatchFlag checks for the -watch flag in args,
+// returning the interval (or 0 if there's no -watch flag)
+// and the remaining args.
+func parseWatchFlag(ctx context.Context, args []string) (time.Duration, []string, error) {
+	if !getConfig(ctx).watch || len(args) == 0 {
+		return 0, args, nil
+	}
+
+	var val string
+	switch arg := args[0]; {
+	case arg == "-watch" || arg == "--watch":
+		if len(args) < 2 {
+			return 0, args, fmt.Errorf("flag needs an argument: %s", arg)
+		}
+		val, args = args[1], args[2:]
+
+	case strings.HasPrefix(arg, "-watch=") || strings.HasPrefix(arg, "--watch="):
+		val, args = arg[strings.Index(arg, "=")+1:], args[1:]
+
+	default:
+		return 0, args, nil
+	}
+
+	interval, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, args, fmt.Errorf("parsing watch interval: %w", err)
+	}
+	if interval <= 0 {
+		return 0, args, fmt.Errorf("watch interval %s is not positive", interval)
+	}
+	return interval, args, nil
+}
+
+// watch calls run repeatedly until ctx is canceled,
+// waiting for interval between calls,
+// or for the trigger from WithWatchTrigger, if there is one.
+func watch(ctx context.Context, interval time.Duration, run func() error) error {
+	var trigger <-chan struct{}
+	if t := getConfig(ctx).watchTrigger; t != nil {
+		trigger = t(ctx, interval)
+	}
+
+	for {
+		if err := run(); err != nil && ctx.Err() == nil {
+			if isUsageErr(err) || errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			warn(ctx, "%s", err)
+		}
+
+		if trigger != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case _, ok := <-trigger:
+				if !ok {
+					return nil
+				}
+			}
+			continue
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
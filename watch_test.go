@@ -0,0 +1,92 @@
+package subcmd
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var count int
+	c := dotenvtestcmd(func(context.Context, []string) {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	})
+
+	if err := Run(ctx, c, []string{"x", "-watch", "1ms"}, WithWatch()); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("got %d runs, want 3", count)
+	}
+
+	if err := Run(context.Background(), c, []string{"x", "-watch", "0s"}, WithWatch()); err == nil {
+		t.Error("got no error for zero interval")
+	}
+}
+
+func TestWatchTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		count    int
+		interval time.Duration
+	)
+	c := dotenvtestcmd(func(context.Context, []string) {
+		count++
+	})
+	trigger := func(_ context.Context, d time.Duration) <-chan struct{} {
+		interval = d
+		ch := make(chan struct{}, 2)
+		ch <- struct{}{}
+		ch <- struct{}{}
+		close(ch)
+		return ch
+	}
+
+	if err := Run(ctx, c, []string{"x", "--watch=1h"}, WithWatchTrigger(trigger)); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("got %d runs, want 3", count)
+	}
+	if interval != time.Hour {
+		t.Errorf("got interval %s, want 1h", interval)
+	}
+}
+
+func TestWatchUsageErr(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := testcmdfunc(func() Map {
+		return Commands("x", func(context.Context, int, []string) {}, "", Params("n", Int, 0, ""))
+	})
+
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{name: "too few args", args: []string{"x", "-watch", "1ms"}},
+		{name: "bad arg", args: []string{"x", "-watch", "1ms", "abc"}},
+		{name: "bad flag", args: []string{"x", "-watch", "1ms", "-bogus", "1"}},
+		{name: "help", args: []string{"x", "-watch", "1ms", "-h"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Run(ctx, c, tc.args, WithWatch(), WithWarningHandler(func(context.Context, string) {
+				t.Error("got warning, want watching to stop")
+				cancel()
+			}), WithStdio(nil, io.Discard, io.Discard))
+			if err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
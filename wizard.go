@@ -0,0 +1,129 @@
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithWizard is an [Option] that enables an interactive "wizard" mode for every subcommand.
+// When the first argument after a subcommand's name is "-interactive" (or "--interactive"),
+// [Run] prompts for the value of each of the subcommand's parameters in turn,
+// showing its type, its doc string, and its default value,
+// and re-prompting if a value cannot be parsed.
+// An empty response accepts the default,
+// except for required positional parameters,
+// which must be supplied.
+// It then runs the subcommand with the resulting values,
+// followed by any remaining arguments.
+//
+// Prompts are written to [Stdout] and responses are read from [Stdin].
+// Wizard mode is an error if the run is not interactive (see [Interactive]).
+func WithWizard() Option {
+	return func(c *config) error {
+		c.wizard = true
+		return nil
+	}
+}
+
+// ErrNotInteractive is the error when wizard mode (see [WithWizard]) is requested in a non-interactive run.
+var ErrNotInteractive = errors.New("not an interactive session")
+
+// wizard checks for the -interactive flag in args
+// and if it's present,
+// prompts for the values of params,
+// returning a new list of args representing the result.
+func wizard(ctx context.Context, params []Param, args []string) ([]string, error) {
+	if !getConfig(ctx).wizard || len(args) == 0 {
+		return args, nil
+	}
+	if args[0] != "-interactive" && args[0] != "--interactive" {
+		return args, nil
+	}
+	if !Interactive(ctx) {
+		return nil, ErrNotInteractive
+	}
+
+	var (
+		r          = bufio.NewReader(Stdin(ctx))
+		w          = Stdout(ctx)
+		flags      []string
+		positional []string
+	)
+	for _, p := range params {
+		val, err := prompt(r, w, p)
+		if err != nil {
+			return nil, fmt.Errorf("reading value for %s: %w", p.Name, err)
+		}
+		if strings.HasPrefix(p.Name, "-") {
+			if val != "" {
+				flags = append(flags, p.Name+"="+val)
+			}
+		} else {
+			positional = append(positional, val)
+		}
+	}
+
+	result := append(flags, "--")
+	result = append(result, positional...)
+	return append(result, args[1:]...), nil
+}
+
+// prompt asks for the value of p until it gets a valid one.
+// The result is "" if a flag should take its default value.
+func prompt(r *bufio.Reader, w io.Writer, p Param) (string, error) {
+	var (
+		name     = strings.TrimSuffix(strings.TrimLeft(p.Name, "-"), "?")
+		isFlag   = strings.HasPrefix(p.Name, "-")
+		optional = isFlag || strings.HasSuffix(p.Name, "?")
+	)
+
+	// Use a single-flag FlagSet to validate responses and to format the default value.
+	// The flag is a copy of p,
+	// so that Parse, Choices, Layouts, etc. apply.
+	fp := p
+	fp.Name = "-" + name
+	fs, _, _, err := ToFlagSet([]Param{fp})
+	if err != nil {
+		return "", err
+	}
+	fs.SetOutput(io.Discard)
+	dflt := fs.Lookup(name).DefValue
+
+	for {
+		fmt.Fprintf(w, "%s (%s)", name, p.Type)
+		if p.Doc != "" {
+			fmt.Fprintf(w, " - %s", p.Doc)
+		}
+		if optional {
+			fmt.Fprintf(w, " [%s]", dflt)
+		}
+		fmt.Fprint(w, ": ")
+
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			if isFlag {
+				return "", nil
+			}
+			if optional {
+				return dflt, nil
+			}
+			fmt.Fprintln(w, "A value is required.")
+			continue
+		}
+
+		if err := fs.Set(name, line); err != nil {
+			fmt.Fprintf(w, "Invalid value: %s\n", err)
+			continue
+		}
+		return line, nil
+	}
+}
@@ -0,0 +1,84 @@
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestWizard(t *testing.T) {
+	var (
+		gotN   int
+		gotDir string
+	)
+	c := nestedtestcmd{migrate: func(_ context.Context, n int, dir string, _ []string) {
+		gotN, gotDir = n, dir
+	}}
+
+	var (
+		stdin  = strings.NewReader("x\n4\n\n-here\n")
+		stdout bytes.Buffer
+	)
+	err := Run(context.Background(), c, []string{"db", "migrate", "-interactive"}, WithWizard(), WithInteractive(true), WithStdio(stdin, &stdout, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotN != 4 {
+		t.Errorf("got n=%d, want 4", gotN)
+	}
+	if gotDir != "-here" {
+		t.Errorf(`got dir "%s", want "-here"`, gotDir)
+	}
+
+	out := stdout.String()
+	if !strings.HasPrefix(out, "n (int) - number of steps [1]: Invalid value: ") {
+		t.Errorf(`got output "%s"`, out)
+	}
+	if !strings.Contains(out, "dir (string) - migrations directory: A value is required.\n") {
+		t.Errorf(`got output "%s"`, out)
+	}
+
+	err = Run(context.Background(), c, []string{"db", "migrate", "-interactive"}, WithWizard(), WithInteractive(false))
+	if !errors.Is(err, ErrNotInteractive) {
+		t.Errorf("got %v, want %v", err, ErrNotInteractive)
+	}
+}
+
+func TestWizardParamFields(t *testing.T) {
+	var (
+		gotAddr  netip.Addr
+		gotColor string
+	)
+	c := testcmdfunc(func() Map {
+		return Map{
+			"x": Subcmd{
+				F: func(_ context.Context, addr netip.Addr, color string, _ []string) { gotAddr, gotColor = addr, color },
+				Params: []Param{
+					{Name: "-addr", Type: Custom, Default: netip.MustParseAddr("127.0.0.1"), Parse: ParseFunc(netip.ParseAddr)},
+					{Name: "color", Type: String, Choices: []string{"red", "blue"}},
+				},
+			},
+		}
+	})
+
+	var (
+		stdin  = strings.NewReader("bogus\n::1\ngreen\nblue\n")
+		stdout bytes.Buffer
+	)
+	err := Run(context.Background(), c, []string{"x", "-interactive"}, WithWizard(), WithInteractive(true), WithStdio(stdin, &stdout, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr.String() != "::1" {
+		t.Errorf("got addr %s, want ::1", gotAddr)
+	}
+	if gotColor != "blue" {
+		t.Errorf(`got color "%s", want "blue"`, gotColor)
+	}
+	if n := strings.Count(stdout.String(), "Invalid value"); n != 2 {
+		t.Errorf("got %d invalid-value messages, want 2; output:\n%s", n, stdout.String())
+	}
+}